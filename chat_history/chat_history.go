@@ -3,12 +3,15 @@ package chat_history
 import (
 	"fmt"
 	"github.com/meysamhadeli/codai/chat_history/contracts"
+	"github.com/meysamhadeli/codai/chat_history/models"
 	"time"
 )
 
 // ChatHistory Define a struct for the chat session to keep the history
 type chatHistory struct {
-	History []string // Store each prompt-response as a string
+	History     []string       // Store each prompt-response as a string
+	Session     models.Session // Record of the turns of session for the export, kept after clearing the history
+	saveSession bool           // Save the record of session in '~/.config/codai/sessions' after each turn
 }
 
 func (ch *chatHistory) GetHistory() []string {
@@ -28,6 +31,38 @@ func (ch *chatHistory) ClearHistory() {
 	ch.History = []string{}
 }
 
+// StartSession starts the record of a new session, it's saved after each turn when save is set, else kept only in memory
+func (ch *chatHistory) StartSession(cwd string, provider string, model string, save bool) {
+	startedAt := time.Now()
+	ch.Session = models.Session{
+		ID:        fmt.Sprintf("%s-%04x", startedAt.Format("20060102-150405"), startedAt.Nanosecond()&0xffff),
+		Cwd:       cwd,
+		Provider:  provider,
+		Model:     model,
+		StartedAt: startedAt,
+	}
+	ch.saveSession = save
+}
+
+// RecordTurn adds a turn to the record of session and saves it if enabled, so it can be exported later
+func (ch *chatHistory) RecordTurn(turn models.Turn) error {
+	if ch.Session.ID == "" {
+		return nil
+	}
+
+	ch.Session.Turns = append(ch.Session.Turns, turn)
+
+	if !ch.saveSession {
+		return nil
+	}
+	return SaveSession(ch.Session)
+}
+
+// GetSession returns the record of session
+func (ch *chatHistory) GetSession() models.Session {
+	return ch.Session
+}
+
 func NewChatHistory() contracts.IChatHistory {
 	return &chatHistory{}
 }
@@ -0,0 +1,44 @@
+package chat_history
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/meysamhadeli/codai/chat_history/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecordTurn(t *testing.T) {
+	tests := []struct {
+		name  string
+		save  bool
+		saved bool
+	}{
+		{name: "saved", save: true, saved: true},
+		{name: "kept in memory", save: false, saved: false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			homeDir := t.TempDir()
+			t.Setenv("HOME", homeDir)
+
+			chatHistory := NewChatHistory()
+			chatHistory.StartSession("/projects/shop", "openai", "gpt-4o", test.save)
+			assert.NoError(t, chatHistory.RecordTurn(models.Turn{UserInput: "Fix the cart", Answer: "Done", InputTokens: 10}))
+
+			session := chatHistory.GetSession()
+			assert.Len(t, session.Turns, 1)
+
+			_, err := os.Stat(filepath.Join(homeDir, ".config", "codai", "sessions", session.ID+".json"))
+			assert.Equal(t, test.saved, err == nil)
+
+			if test.saved {
+				loaded, err := LoadSession(session.ID)
+				assert.NoError(t, err)
+				assert.Equal(t, "Fix the cart", loaded.Turns[0].UserInput)
+			}
+		})
+	}
+}
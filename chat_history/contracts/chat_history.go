@@ -1,7 +1,12 @@
 package contracts
 
+import "github.com/meysamhadeli/codai/chat_history/models"
+
 type IChatHistory interface {
 	AddToHistory(userInputPrompt string, aiResponse string)
 	ClearHistory()
 	GetHistory() []string
+	StartSession(cwd string, provider string, model string, save bool)
+	RecordTurn(turn models.Turn) error
+	GetSession() models.Session
 }
@@ -0,0 +1,35 @@
+package models
+
+import "time"
+
+// Session is the record of a code session, kept in '~/.config/codai/sessions/<id>.json' for the export
+type Session struct {
+	ID        string    `json:"id"`
+	Cwd       string    `json:"cwd"`
+	Provider  string    `json:"provider"`
+	Model     string    `json:"model"`
+	StartedAt time.Time `json:"started_at"`
+	Turns     []Turn    `json:"turns"`
+}
+
+// Turn is a user request with the answer of AI, the code changes and their accept/reject status, and its tokens and cost
+type Turn struct {
+	Time             time.Time          `json:"time"`
+	UserInput        string             `json:"user_input"`
+	Answer           string             `json:"answer"`
+	Canceled         bool               `json:"canceled,omitempty"`
+	CodeChanges      []CodeChangeRecord `json:"code_changes,omitempty"`
+	InputTokens      int                `json:"input_tokens"`
+	OutputTokens     int                `json:"output_tokens"`
+	CachedTokens     int                `json:"cached_tokens,omitempty"`
+	CacheWriteTokens int                `json:"cache_write_tokens,omitempty"`
+	ReasoningTokens  int                `json:"reasoning_tokens,omitempty"`
+	Cost             float64            `json:"cost"`
+}
+
+// CodeChangeRecord is a code change suggested by AI and if the user accepted it
+type CodeChangeRecord struct {
+	RelativePath string `json:"relative_path"`
+	Code         string `json:"code"`
+	Accepted     bool   `json:"accepted"`
+}
@@ -0,0 +1,211 @@
+package chat_history
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html"
+	"html/template"
+	"path/filepath"
+	"strings"
+
+	"github.com/meysamhadeli/codai/chat_history/models"
+)
+
+// Export formats of a session
+const (
+	FormatMarkdown = "md"
+	FormatHTML     = "html"
+	FormatJSON     = "json"
+)
+
+// sessionHTMLTemplate renders a session as a standalone HTML page
+var sessionHTMLTemplate = template.Must(template.New("session").Funcs(template.FuncMap{
+	"markdown": markdownToHTML,
+	"add":      func(a, b int) int { return a + b },
+	"tokens":   formatTokens,
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Codai session {{.Session.ID}}</title>
+<style>
+body { font-family: sans-serif; max-width: 960px; margin: auto; padding: 1em; }
+pre { background: #f5f5f5; padding: 0.75em; overflow-x: auto; }
+blockquote { border-left: 4px solid #2b7fec; margin: 0; padding-left: 1em; white-space: pre-wrap; }
+.accepted { color: #2e7d32; }
+.rejected { color: #c62828; }
+</style>
+</head>
+<body>
+<h1>Codai session {{.Session.ID}}</h1>
+<ul>
+<li>Project: {{.Session.Cwd}}</li>
+<li>Model: {{.Session.Provider}}/{{.Session.Model}}</li>
+<li>Started: {{.Session.StartedAt.Format "2006-01-02 15:04:05"}}</li>
+<li>Tokens: {{tokens .Totals}} - Cost: {{printf "%.6f" .Totals.Cost}} $</li>
+</ul>
+{{range $index, $turn := .Session.Turns}}
+<h2>Turn {{add $index 1}} - {{$turn.Time.Format "2006-01-02 15:04:05"}}</h2>
+<p>Tokens: {{tokens $turn}} - Cost: {{printf "%.6f" $turn.Cost}} $</p>
+<h3>Prompt</h3>
+<blockquote>{{$turn.UserInput}}</blockquote>
+<h3>Answer{{if $turn.Canceled}} (canceled){{end}}</h3>
+{{markdown $turn.Answer}}
+{{if $turn.CodeChanges}}<h3>Code changes</h3>
+<ul>
+{{range $turn.CodeChanges}}<li>{{if .Accepted}}<span class="accepted">accepted</span>{{else}}<span class="rejected">rejected</span>{{end}} <code>{{.RelativePath}}</code></li>
+{{end}}</ul>
+{{end}}{{end}}
+</body>
+</html>
+`))
+
+// ExportFormatFromPath returns the export format of a file by its extension, markdown by default
+func ExportFormatFromPath(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".html", ".htm":
+		return FormatHTML
+	case ".json":
+		return FormatJSON
+	default:
+		return FormatMarkdown
+	}
+}
+
+// ExportSession renders the prompts, answers, code changes with their status, and tokens and cost per turn of a session
+func ExportSession(session models.Session, format string) ([]byte, error) {
+	totals := sessionTotals(session)
+
+	switch format {
+	case FormatJSON:
+		return json.MarshalIndent(session, "", "  ")
+
+	case FormatHTML:
+		var buffer bytes.Buffer
+		err := sessionHTMLTemplate.Execute(&buffer, map[string]any{
+			"Session": session,
+			"Totals":  totals,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("error rendering session: %v", err)
+		}
+		return buffer.Bytes(), nil
+
+	case FormatMarkdown:
+		var builder strings.Builder
+		builder.WriteString(fmt.Sprintf("# Codai session %s\n\n", session.ID))
+		builder.WriteString(fmt.Sprintf("- Project: %s\n", session.Cwd))
+		builder.WriteString(fmt.Sprintf("- Model: %s/%s\n", session.Provider, session.Model))
+		builder.WriteString(fmt.Sprintf("- Started: %s\n", session.StartedAt.Format("2006-01-02 15:04:05")))
+		builder.WriteString(fmt.Sprintf("- Tokens: %s - Cost: %.6f $\n", formatTokens(totals), totals.Cost))
+
+		for i, turn := range session.Turns {
+			builder.WriteString(fmt.Sprintf("\n## Turn %d - %s\n\n", i+1, turn.Time.Format("2006-01-02 15:04:05")))
+			builder.WriteString(fmt.Sprintf("Tokens: %s - Cost: %.6f $\n\n", formatTokens(turn), turn.Cost))
+			builder.WriteString("### Prompt\n\n")
+			builder.WriteString("> " + strings.ReplaceAll(turn.UserInput, "\n", "\n> ") + "\n\n")
+
+			if turn.Canceled {
+				builder.WriteString("### Answer (canceled)\n\n")
+			} else {
+				builder.WriteString("### Answer\n\n")
+			}
+			builder.WriteString(strings.TrimSpace(turn.Answer) + "\n")
+
+			if len(turn.CodeChanges) > 0 {
+				builder.WriteString("\n### Code changes\n\n")
+				for _, change := range turn.CodeChanges {
+					status := "❌ rejected"
+					if change.Accepted {
+						status = "✅ accepted"
+					}
+					builder.WriteString(fmt.Sprintf("- %s `%s`\n", status, change.RelativePath))
+				}
+			}
+		}
+
+		return []byte(builder.String()), nil
+
+	default:
+		return nil, fmt.Errorf("unsupported export format '%s', use one of 'md', 'html' or 'json'", format)
+	}
+}
+
+// sessionTotals sums the tokens and cost of the turns of a session
+func sessionTotals(session models.Session) models.Turn {
+	var totals models.Turn
+	for _, turn := range session.Turns {
+		totals.InputTokens += turn.InputTokens
+		totals.OutputTokens += turn.OutputTokens
+		totals.CachedTokens += turn.CachedTokens
+		totals.CacheWriteTokens += turn.CacheWriteTokens
+		totals.ReasoningTokens += turn.ReasoningTokens
+		totals.Cost += turn.Cost
+	}
+	return totals
+}
+
+// formatTokens describes the tokens of a turn, with the cached, cache write and reasoning tokens when there are some
+func formatTokens(turn models.Turn) string {
+	var details []string
+	if turn.CachedTokens > 0 {
+		details = append(details, fmt.Sprintf("%d cached", turn.CachedTokens))
+	}
+	if turn.CacheWriteTokens > 0 {
+		details = append(details, fmt.Sprintf("%d cache write", turn.CacheWriteTokens))
+	}
+
+	input := fmt.Sprintf("%d input", turn.InputTokens)
+	if len(details) > 0 {
+		input += fmt.Sprintf(" (%s)", strings.Join(details, ", "))
+	}
+
+	output := fmt.Sprintf("%d output", turn.OutputTokens)
+	if turn.ReasoningTokens > 0 {
+		output += fmt.Sprintf(" (%d reasoning)", turn.ReasoningTokens)
+	}
+
+	return input + ", " + output
+}
+
+// markdownToHTML renders the code blocks of an answer as preformatted code and the other lines as paragraphs
+func markdownToHTML(markdown string) template.HTML {
+	var builder strings.Builder
+	var paragraph []string
+
+	flushParagraph := func() {
+		if len(paragraph) > 0 {
+			builder.WriteString("<p>" + strings.Join(paragraph, "<br>\n") + "</p>\n")
+			paragraph = nil
+		}
+	}
+
+	inCodeBlock := false
+	for _, line := range strings.Split(markdown, "\n") {
+		trimmedLine := strings.TrimSpace(line)
+
+		switch {
+		case strings.HasPrefix(trimmedLine, "```") && !inCodeBlock:
+			flushParagraph()
+			inCodeBlock = true
+			builder.WriteString(fmt.Sprintf("<pre><code class=\"language-%s\">", html.EscapeString(strings.TrimPrefix(trimmedLine, "```"))))
+		case strings.HasPrefix(trimmedLine, "```"):
+			inCodeBlock = false
+			builder.WriteString("</code></pre>\n")
+		case inCodeBlock:
+			builder.WriteString(html.EscapeString(line) + "\n")
+		case trimmedLine == "":
+			flushParagraph()
+		default:
+			paragraph = append(paragraph, html.EscapeString(line))
+		}
+	}
+
+	if inCodeBlock {
+		builder.WriteString("</code></pre>\n")
+	}
+	flushParagraph()
+
+	return template.HTML(builder.String())
+}
@@ -0,0 +1,155 @@
+package chat_history
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/meysamhadeli/codai/chat_history/models"
+	"github.com/stretchr/testify/assert"
+)
+
+var startedAt = time.Date(2026, 10, 19, 9, 30, 0, 0, time.UTC)
+
+// testSession returns a session with a finished turn with code changes and a canceled turn
+func testSession() models.Session {
+	return models.Session{
+		ID:        "20261019-093000-00ab",
+		Cwd:       "/projects/shop",
+		Provider:  "anthropic",
+		Model:     "claude-sonnet-4",
+		StartedAt: startedAt,
+		Turns: []models.Turn{
+			{
+				Time:      startedAt.Add(time.Minute),
+				UserInput: "Fix the <total>\nof the cart",
+				Answer:    "Use a sum:\n\nFile: cart.go\n```go\nfunc total() int { return a & b }\n```\n",
+				CodeChanges: []models.CodeChangeRecord{
+					{RelativePath: "cart.go", Code: "func total() int { return a & b }", Accepted: true},
+					{RelativePath: "cart_test.go", Code: "package cart"},
+				},
+				InputTokens:      1200,
+				OutputTokens:     300,
+				CachedTokens:     800,
+				CacheWriteTokens: 400,
+				ReasoningTokens:  100,
+				Cost:             0.0125,
+			},
+			{
+				Time:         startedAt.Add(2 * time.Minute),
+				UserInput:    "Explain it",
+				Answer:       "It adds",
+				Canceled:     true,
+				InputTokens:  500,
+				OutputTokens: 20,
+				Cost:         0.0025,
+			},
+		},
+	}
+}
+
+func TestExportFormatFromPath(t *testing.T) {
+	tests := map[string]string{
+		"session.md":     FormatMarkdown,
+		"session.HTML":   FormatHTML,
+		"session.htm":    FormatHTML,
+		"session.json":   FormatJSON,
+		"session.txt":    FormatMarkdown,
+		"session":        FormatMarkdown,
+		"out/day.1.Md":   FormatMarkdown,
+		"out/day.1.Json": FormatJSON,
+	}
+
+	for path, format := range tests {
+		t.Run(path, func(t *testing.T) {
+			assert.Equal(t, format, ExportFormatFromPath(path))
+		})
+	}
+}
+
+func TestExportSessionMarkdown(t *testing.T) {
+	content, err := ExportSession(testSession(), FormatMarkdown)
+	assert.NoError(t, err)
+
+	markdown := string(content)
+	assert.True(t, strings.HasPrefix(markdown, "# Codai session 20261019-093000-00ab\n"))
+	assert.Contains(t, markdown, "- Model: anthropic/claude-sonnet-4\n")
+	assert.Contains(t, markdown, "- Tokens: 1700 input (800 cached, 400 cache write), 320 output (100 reasoning) - Cost: 0.015000 $\n")
+	assert.Contains(t, markdown, "## Turn 1 - 2026-10-19 09:31:00\n\nTokens: 1200 input (800 cached, 400 cache write), 300 output (100 reasoning) - Cost: 0.012500 $\n")
+	assert.Contains(t, markdown, "### Prompt\n\n> Fix the <total>\n> of the cart\n")
+	assert.Contains(t, markdown, "- ✅ accepted `cart.go`\n- ❌ rejected `cart_test.go`\n")
+	assert.Contains(t, markdown, "## Turn 2 - 2026-10-19 09:32:00\n\nTokens: 500 input, 20 output - Cost: 0.002500 $\n")
+	assert.Contains(t, markdown, "### Answer (canceled)\n\nIt adds\n")
+}
+
+func TestExportSessionJSON(t *testing.T) {
+	session := testSession()
+
+	content, err := ExportSession(session, FormatJSON)
+	assert.NoError(t, err)
+	assert.Contains(t, string(content), `"cache_write_tokens": 400`)
+
+	var exported models.Session
+	assert.NoError(t, json.Unmarshal(content, &exported))
+	assert.Equal(t, session, exported)
+}
+
+func TestExportSessionHTML(t *testing.T) {
+	content, err := ExportSession(testSession(), FormatHTML)
+	assert.NoError(t, err)
+
+	page := string(content)
+	assert.Contains(t, page, "<title>Codai session 20261019-093000-00ab</title>")
+	assert.Contains(t, page, "<li>Tokens: 1700 input (800 cached, 400 cache write), 320 output (100 reasoning) - Cost: 0.015000 $</li>")
+	assert.Contains(t, page, "<blockquote>Fix the &lt;total&gt;\nof the cart</blockquote>")
+	assert.Contains(t, page, "<h3>Answer (canceled)</h3>")
+	assert.Contains(t, page, `<span class="accepted">accepted</span> <code>cart.go</code>`)
+	assert.Contains(t, page, `<span class="rejected">rejected</span> <code>cart_test.go</code>`)
+	assert.NotContains(t, page, "<total>")
+}
+
+func TestExportSessionUnsupportedFormat(t *testing.T) {
+	_, err := ExportSession(testSession(), "pdf")
+	assert.EqualError(t, err, "unsupported export format 'pdf', use one of 'md', 'html' or 'json'")
+}
+
+func TestMarkdownToHTML(t *testing.T) {
+	tests := []struct {
+		name     string
+		markdown string
+		expected string
+	}{
+		{
+			name:     "paragraphs",
+			markdown: "First line\nsecond line\n\nNext paragraph",
+			expected: "<p>First line<br>\nsecond line</p>\n<p>Next paragraph</p>\n",
+		},
+		{
+			name:     "escaped text",
+			markdown: `Use <script>alert("x")</script> & more`,
+			expected: "<p>Use &lt;script&gt;alert(&#34;x&#34;)&lt;/script&gt; &amp; more</p>\n",
+		},
+		{
+			name:     "code block",
+			markdown: "Code:\n```go\nif a < b && c > d {\n\treturn\n}\n```\nDone",
+			expected: "<p>Code:</p>\n<pre><code class=\"language-go\">if a &lt; b &amp;&amp; c &gt; d {\n\treturn\n}\n</code></pre>\n<p>Done</p>\n",
+		},
+		{
+			name:     "escaped language",
+			markdown: "```\"><script>\nx\n```",
+			expected: "<pre><code class=\"language-&#34;&gt;&lt;script&gt;\">x\n</code></pre>\n",
+		},
+		{
+			name:     "unterminated code block",
+			markdown: "```python\nprint('<b>')",
+			expected: "<pre><code class=\"language-python\">print(&#39;&lt;b&gt;&#39;)\n</code></pre>\n",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, string(markdownToHTML(test.markdown)))
+		})
+	}
+}
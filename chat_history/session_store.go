@@ -0,0 +1,99 @@
+package chat_history
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/meysamhadeli/codai/chat_history/models"
+	"github.com/meysamhadeli/codai/utils"
+)
+
+// getSessionsDir returns the directory of the saved sessions, '~/.config/codai/sessions'
+func getSessionsDir() (string, error) {
+	configDir, err := utils.GetCodaiConfigDir()
+	if err != nil {
+		return "", err
+	}
+
+	sessionsDir := filepath.Join(configDir, "sessions")
+	if err := os.MkdirAll(sessionsDir, os.ModePerm); err != nil {
+		return "", fmt.Errorf("failed to create directory %s: %w", sessionsDir, err)
+	}
+
+	return sessionsDir, nil
+}
+
+// SaveSession writes the record of a session as JSON
+func SaveSession(session models.Session) error {
+	sessionsDir, err := getSessionsDir()
+	if err != nil {
+		return err
+	}
+
+	content, err := json.MarshalIndent(session, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error marshalling session: %v", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(sessionsDir, session.ID+".json"), content, 0644); err != nil {
+		return fmt.Errorf("failed to save session %s: %w", session.ID, err)
+	}
+
+	return nil
+}
+
+// LoadSession reads the record of a saved session
+func LoadSession(id string) (models.Session, error) {
+	var session models.Session
+
+	sessionsDir, err := getSessionsDir()
+	if err != nil {
+		return session, err
+	}
+
+	content, err := os.ReadFile(filepath.Join(sessionsDir, filepath.Base(id)+".json"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return session, fmt.Errorf("session '%s' not found, use 'codai sessions list' to see the sessions", id)
+		}
+		return session, fmt.Errorf("failed to read session %s: %w", id, err)
+	}
+
+	if err := json.Unmarshal(content, &session); err != nil {
+		return session, fmt.Errorf("error unmarshalling session %s: %v", id, err)
+	}
+
+	return session, nil
+}
+
+// ListSessions returns the saved sessions, the latest first
+func ListSessions() ([]models.Session, error) {
+	sessionsDir, err := getSessionsDir()
+	if err != nil {
+		return nil, err
+	}
+
+	files, err := filepath.Glob(filepath.Join(sessionsDir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	var sessions []models.Session
+	for _, file := range files {
+		session, err := LoadSession(strings.TrimSuffix(filepath.Base(file), ".json"))
+		if err != nil {
+			continue
+		}
+		sessions = append(sessions, session)
+	}
+
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].StartedAt.After(sessions[j].StartedAt)
+	})
+
+	return sessions, nil
+}
@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/meysamhadeli/codai/code_analyzer"
+	"github.com/meysamhadeli/codai/code_analyzer/contracts"
+	"github.com/meysamhadeli/codai/config"
+	"github.com/meysamhadeli/codai/constants/lipgloss"
+	"github.com/meysamhadeli/codai/utils"
+	"github.com/spf13/cobra"
+)
+
+// AnalyzeCmd: codai analyze [file]
+var analyzeCmd = &cobra.Command{
+	Use:   "analyze [file]",
+	Short: "Preview the Tree-sitter summary of a file in the context of project.",
+	Long: `The 'analyze' subcommand prints the summary of a file sent in the context of project, to try the queries of 
+'.codai/queries/<lang>.scm' and '~/.config/codai/queries/<lang>.scm' that extend or override the embedded queries by tag, 
+e.g., 'codai analyze --lang go path/file.go'. The language is detected from the extension of the file by default.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		cwd, err := os.Getwd()
+		if err != nil {
+			fmt.Println(lipgloss.Red.Render(fmt.Sprintf("%v", err)))
+			return
+		}
+
+		cfg := config.LoadConfigs(cmd, cwd)
+
+		analyzer := code_analyzer.NewCodeAnalyzer(cwd)
+		analyzer.ConfigureContext(cfg.Context)
+
+		language, _ := cmd.Flags().GetString("lang")
+		handleAnalyzeCommand(analyzer, args[0], language)
+	},
+}
+
+func handleAnalyzeCommand(analyzer contracts.ICodeAnalyzer, filePath string, language string) {
+	if language == "" {
+		language = utils.GetSupportedLanguage(filePath)
+	}
+
+	if language == "" || !slices.Contains(code_analyzer.TreeSitterLanguages(), language) {
+		fmt.Println(lipgloss.Red.Render(fmt.Sprintf("unknown language '%s' of %s, use '--lang' with one of: %s", language, filePath, strings.Join(code_analyzer.TreeSitterLanguages(), ", "))))
+		return
+	}
+
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		fmt.Println(lipgloss.Red.Render(fmt.Sprintf("%v", err)))
+		return
+	}
+
+	elements := analyzer.ProcessFileWithLanguage(filepath.ToSlash(filePath), language, content)
+
+	for _, warning := range analyzer.Warnings() {
+		fmt.Println(lipgloss.Yellow.Render(warning))
+	}
+
+	fmt.Println(lipgloss.Gray.Render(fmt.Sprintf("Summary of %s (%s)", filePath, language)))
+	fmt.Println(strings.Join(elements, "\n"))
+}
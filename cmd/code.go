@@ -1,20 +1,46 @@
 package cmd
 
 import (
-	"bufio"
 	"context"
+	"errors"
 	"fmt"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/meysamhadeli/codai/chat_history"
+	history_models "github.com/meysamhadeli/codai/chat_history/models"
 	"github.com/meysamhadeli/codai/code_analyzer/models"
 	"github.com/meysamhadeli/codai/constants/lipgloss"
+	provider_models "github.com/meysamhadeli/codai/providers/models"
+	"github.com/meysamhadeli/codai/session_commands"
+	contracts_commands "github.com/meysamhadeli/codai/session_commands/contracts"
+	commands_models "github.com/meysamhadeli/codai/session_commands/models"
+	"github.com/meysamhadeli/codai/token_management"
+	token_models "github.com/meysamhadeli/codai/token_management/models"
+	"github.com/meysamhadeli/codai/tui"
 	"github.com/meysamhadeli/codai/utils"
 	"github.com/pterm/pterm"
 	"github.com/spf13/cobra"
-	"os"
+	"io"
 	"os/signal"
 	"strings"
 	"syscall"
+	"time"
 )
 
+// lastReasoning keeps the reasoning of the last answer, so it can be expanded with ':reasoning'
+var lastReasoning string
+
+// pendingImages keeps the images attached with ':image' until the next request
+var pendingImages []provider_models.Image
+
+// errRequestCanceled is returned when the user cancels the in-flight request with Ctrl+C
+var errRequestCanceled = errors.New("request canceled")
+
+// errBudgetExceeded is returned when a request above a limit of budget is blocked or not confirmed by the user
+var errBudgetExceeded = errors.New("budget exceeded")
+
+// slashCommands are the user commands of '.codai/commands', invoked like '/test path/to/file.go'
+var slashCommands map[string]utils.SlashCommand
+
 // CodeCmd: codai code
 var codeCmd = &cobra.Command{
 	Use:   "code",
@@ -25,6 +51,10 @@ based on the current project context. Each interaction is part of a session, all
 improved responses throughout the user experience.`,
 	Run: func(cmd *cobra.Command, args []string) {
 		rootDependencies := handleRootCommand(cmd)
+		if useTui, _ := cmd.Flags().GetBool("tui"); useTui {
+			handleTuiCommand(rootDependencies)
+			return
+		}
 		handleCodeCommand(rootDependencies)
 	},
 }
@@ -32,26 +62,30 @@ improved responses throughout the user experience.`,
 func handleCodeCommand(rootDependencies *RootDependencies) {
 
 	// Create a context with cancel function
-	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
+	// The first Ctrl+C during a request cancels only that request
+	requestCanceler := &utils.RequestCanceler{}
+
 	var requestedContext string
 	var fullContext *models.FullContextData
 
 	spinner := pterm.DefaultSpinner.WithStyle(pterm.NewStyle(pterm.FgLightBlue)).WithSequence("⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏").WithDelay(100).WithRemoveWhenDone(true)
 
-	go utils.GracefulShutdown(ctx, cancel, func() {
+	go utils.GracefulShutdown(ctx, cancel, requestCanceler, func() {
 
 		rootDependencies.ChatHistory.ClearHistory()
 		rootDependencies.TokenManagement.ClearToken()
 	})
 
-	reader := bufio.NewReader(os.Stdin)
-
 	codeOptionsBox := lipgloss.BoxStyle.Render(":help  Help for code subcommand")
 	fmt.Println(codeOptionsBox)
 
 	spinnerLoadContext, _ := spinner.Start("Loading Context...")
+	rootDependencies.Analyzer.SetProgressHandler(func(processed int, total int) {
+		spinnerLoadContext.UpdateText(fmt.Sprintf("Loading Context... %d/%d files", processed, total))
+	})
 
 	// Get all data files from the root directory
 	fullContext, err := rootDependencies.Analyzer.GetProjectFiles(rootDependencies.Cwd)
@@ -64,6 +98,34 @@ func handleCodeCommand(rootDependencies *RootDependencies) {
 
 	spinnerLoadContext.Stop()
 	fmt.Print("\r")
+	rootDependencies.Analyzer.SetProgressHandler(nil)
+
+	printAnalyzerWarnings(rootDependencies)
+
+	slashCommands, err = utils.LoadSlashCommands(rootDependencies.Cwd)
+	if err != nil {
+		fmt.Println(lipgloss.Red.Render(fmt.Sprintf("%v", err)))
+	}
+
+	rootDependencies.ChatHistory.StartSession(rootDependencies.Cwd, rootDependencies.Config.AIProviderConfig.Provider, rootDependencies.Config.AIProviderConfig.Model, rootDependencies.Config.SaveSessions)
+
+	var completer *utils.Completer
+	commandRegistry := newCodeCommandRegistry(rootDependencies, func(scopedContext *models.FullContextData) {
+		fullContext = scopedContext
+		completer.Mentions = rootDependencies.Analyzer.GetMentionCandidates(fullContext)
+	})
+
+	// Read the input with history, reverse search and tab completion of commands, mentions and paths
+	completer = &utils.Completer{
+		Commands: append(commandRegistry.Names(), utils.SlashCommandNames(slashCommands)...),
+		Mentions: rootDependencies.Analyzer.GetMentionCandidates(fullContext),
+	}
+	editor, err := utils.NewLineEditor(rootDependencies.Cwd, completer)
+	if err != nil {
+		fmt.Println(lipgloss.Red.Render(fmt.Sprintf("%v", err)))
+		return
+	}
+	defer editor.Close()
 
 	// Launch the user input handler in a goroutine
 startLoop: // Label for the start loop
@@ -79,7 +141,14 @@ startLoop: // Label for the start loop
 			}
 
 			// Get user input
-			userInput, err := utils.InputPrompt(reader)
+			userInput, err := utils.InputPrompt(editor)
+
+			// Ctrl+C or Ctrl+D at the prompt ends the session
+			if errors.Is(err, utils.ErrInterrupt) || errors.Is(err, io.EOF) {
+				rootDependencies.ChatHistory.ClearHistory()
+				rootDependencies.TokenManagement.ClearToken()
+				return
+			}
 
 			if err != nil {
 				fmt.Println(lipgloss.Red.Render(fmt.Sprintf("%v", err)))
@@ -91,45 +160,203 @@ startLoop: // Label for the start loop
 				continue
 			}
 
-			// Configure help code subcommand
-			isHelpSubcommands, exit := findCodeSubCommand(userInput, rootDependencies)
+			// Run the commands of the session, e.g., ":help" or an external "codai-<cmd>"
+			isCommand, exit := commandRegistry.Execute(userInput)
+
+			if exit {
+				return
+			}
 
-			if isHelpSubcommands {
+			if isCommand {
 				continue
 			}
 
-			if exit {
-				return
+			// Expand user commands like '/test path/to/file.go' into their prompt, other inputs like '/etc/hosts why?' are sent as is
+			if command, args, ok := utils.ParseSlashCommandInput(userInput, slashCommands); ok {
+				userInput = command.Expand(rootDependencies.Cwd, args)
+				fmt.Println(lipgloss.Dim.Render(userInput))
+			}
+
+			// Attach the images queued with ':image' and the images mentioned like '@screenshot.png'
+			images := pendingImages
+			pendingImages = nil
+			var contextMentions []string
+			for _, mention := range utils.ExtractMentions(userInput) {
+				if !utils.IsImageFile(mention) {
+					contextMentions = append(contextMentions, mention)
+					continue
+				}
+				image, err := utils.LoadImage(mention)
+				if err != nil {
+					fmt.Println(lipgloss.Red.Render(fmt.Sprintf("%v", err)))
+					continue
+				}
+				images = append(images, *image)
+			}
+
+			if len(images) > 0 && !isVisionSupported(rootDependencies, editor) {
+				continue
+			}
+
+			// Pin the full content of files mentioned like '@path/to/file.go', '@dir/' or '@FuncName'
+			var mentionedContext string
+			if len(contextMentions) > 0 {
+				var unresolved []string
+				mentionedContext, unresolved = rootDependencies.Analyzer.ResolveMentions(contextMentions, fullContext)
+				if len(unresolved) > 0 {
+					fmt.Println(lipgloss.Yellow.Render(fmt.Sprintf("No file, directory or symbol found for: @%s", strings.Join(unresolved, ", @"))))
+				}
 			}
 
 			var aiResponseBuilder strings.Builder
 
+			// Record the turn for the export of session and the usage ledger, with the tokens and cost used since the request
+			rootDependencies.TokenManagement.StartTurn()
+			recordTurn := func(canceled bool, codeChanges []history_models.CodeChangeRecord) {
+				usage := recordUsage(rootDependencies)
+
+				err := rootDependencies.ChatHistory.RecordTurn(history_models.Turn{
+					Time:             time.Now(),
+					UserInput:        userInput,
+					Answer:           aiResponseBuilder.String(),
+					Canceled:         canceled,
+					CodeChanges:      codeChanges,
+					InputTokens:      usage.InputTokens,
+					OutputTokens:     usage.OutputTokens,
+					CachedTokens:     usage.CachedTokens,
+					CacheWriteTokens: usage.CacheWriteTokens,
+					ReasoningTokens:  usage.ReasoningTokens,
+					Cost:             usage.Cost,
+				})
+				if err != nil {
+					fmt.Println(lipgloss.Red.Render(fmt.Sprintf("%v", err)))
+				}
+			}
+
+			// Report a failed request, the partial answer of a canceled request can be kept in the history
+			handleRequestError := func(err error) {
+				if errors.Is(err, errBudgetExceeded) {
+					fmt.Println(lipgloss.Yellow.Render("⏹ Request skipped."))
+					return
+				}
+
+				if !errors.Is(err, errRequestCanceled) {
+					fmt.Println(lipgloss.Red.Render(fmt.Sprintf("%v", err)))
+					recordUsage(rootDependencies)
+					displayTokens()
+					return
+				}
+
+				fmt.Println()
+				fmt.Println(lipgloss.Yellow.Render("⏹ Request canceled."))
+
+				if aiResponseBuilder.Len() > 0 {
+					keepAnswer, _ := utils.ConfirmKeepPartialAnswer(editor)
+					if keepAnswer {
+						rootDependencies.ChatHistory.AddToHistory(userInput, aiResponseBuilder.String())
+					}
+				}
+
+				recordTurn(true, nil)
+
+				displayTokens()
+			}
+
 			chatRequestOperation := func() error {
 
-				finalPrompt, userInputPrompt := rootDependencies.Analyzer.GeneratePrompt(fullContext.RawCodes, rootDependencies.ChatHistory.GetHistory(), userInput, requestedContext)
+				// The reasoning of a previous answer isn't the reasoning of this one
+				lastReasoning = ""
+
+				var reasoningBuilder strings.Builder
+				collapseReasoning := rootDependencies.Config.CollapseReasoning
+
+				// Close the reasoning section before the answer starts
+				finishReasoning := func() {
+					if reasoningBuilder.Len() == 0 {
+						return
+					}
+					lastReasoning = reasoningBuilder.String()
+					if collapseReasoning {
+						utils.RenderReasoningSummary(lastReasoning)
+					} else {
+						fmt.Print("\n\n")
+					}
+					reasoningBuilder.Reset()
+				}
+
+				// Mentioned files are always part of the requested context
+				fullRequestedContext := requestedContext
+				if mentionedContext != "" {
+					fullRequestedContext = strings.TrimSuffix(mentionedContext+"\n---------\n\n"+requestedContext, "\n---------\n\n")
+				}
+
+				finalPrompt, userInputPrompt := rootDependencies.Analyzer.GeneratePrompt(rootDependencies.Analyzer.RepoMap(fullContext), rootDependencies.ChatHistory.GetHistory(), userInput, fullRequestedContext)
+
+				// Check the limits of budget with the estimated cost of the request before sending it
+				if !confirmRequestBudget(rootDependencies, editor, finalPrompt+userInputPrompt) {
+					return errBudgetExceeded
+				}
+
+				requestCtx := requestCanceler.Start(ctx)
+				defer requestCanceler.Done()
 
 				// Step 7: Send the relevant code and user input to the AI API
-				responseChan := rootDependencies.CurrentChatProvider.ChatCompletionRequest(ctx, userInputPrompt, finalPrompt)
+				responseChan := rootDependencies.CurrentChatProvider.ChatCompletionRequest(requestCtx, userInputPrompt, finalPrompt, images)
 
 				// Iterate over response channel to handle streamed data or errors.
 				for response := range responseChan {
+					// Stop at the first chunk after Ctrl+C, the provider ends its stream in the background
+					if requestCtx.Err() != nil {
+						go func() {
+							for range responseChan {
+							}
+						}()
+						return errRequestCanceled
+					}
+
 					if response.Err != nil {
 						return response.Err
 					}
 
-					if response.Done {
-						rootDependencies.ChatHistory.AddToHistory(userInput, aiResponseBuilder.String())
-						return nil
+					// Render reasoning dimmed and keep it out of the answer and the history
+					if response.ReasoningContent != "" {
+						if reasoningBuilder.Len() == 0 {
+							fmt.Println(lipgloss.Dim.Render("💭 Thinking..."))
+						}
+						reasoningBuilder.WriteString(response.ReasoningContent)
+						if !collapseReasoning {
+							utils.RenderReasoning(response.ReasoningContent)
+						}
+						continue
 					}
 
+					finishReasoning()
+
 					aiResponseBuilder.WriteString(response.Content)
 
-					language := utils.DetectLanguageFromCodeBlock(response.Content)
-					if err := utils.RenderAndPrintMarkdown(response.Content, language, rootDependencies.Config.Theme); err != nil {
-						return fmt.Errorf("Error rendering markdown: %v", err)
+					// Render line by line, a chunk can hold several lines or the full answer when streaming is disabled
+					for _, line := range strings.SplitAfter(response.Content, "\n") {
+						if line == "" {
+							continue
+						}
+						language := utils.DetectLanguageFromCodeBlock(line)
+						if err := utils.RenderAndPrintMarkdown(line, language, rootDependencies.Config.Theme); err != nil {
+							return fmt.Errorf("Error rendering markdown: %v", err)
+						}
+					}
+
+					if response.Done {
+						rootDependencies.ChatHistory.AddToHistory(userInput, aiResponseBuilder.String())
+						return nil
 					}
 				}
 
+				if requestCtx.Err() != nil {
+					return errRequestCanceled
+				}
+
+				finishReasoning()
+
 				return nil
 			}
 
@@ -141,7 +368,7 @@ startLoop: // Label for the start loop
 
 				fmt.Print("\n")
 
-				contextAccepted, err := utils.ConfirmAdditinalContext(reader)
+				contextAccepted, err := utils.ConfirmAdditinalContext(editor)
 				if err != nil {
 					fmt.Println(lipgloss.Red.Render(fmt.Sprintf("error getting user prompt: %v", err)))
 					continue
@@ -151,8 +378,7 @@ startLoop: // Label for the start loop
 					fmt.Println(lipgloss.Green.Render("✔️ Context accepted!"))
 
 					if err := chatRequestOperation(); err != nil {
-						fmt.Println(lipgloss.Red.Render(fmt.Sprintf("%v", err)))
-						displayTokens()
+						handleRequestError(err)
 						continue
 					}
 
@@ -162,8 +388,7 @@ startLoop: // Label for the start loop
 			}
 
 			if err := chatRequestOperation(); err != nil {
-				fmt.Println(lipgloss.Red.Render(fmt.Sprintf("%v", err)))
-				displayTokens()
+				handleRequestError(err)
 				continue startLoop
 			}
 
@@ -172,6 +397,7 @@ startLoop: // Label for the start loop
 
 			if changes == nil {
 				fmt.Println()
+				recordTurn(false, nil)
 				displayTokens()
 				continue
 			}
@@ -179,10 +405,12 @@ startLoop: // Label for the start loop
 			fmt.Print("\n")
 
 			// Try to apply changes
+			var codeChanges []history_models.CodeChangeRecord
 			for _, change := range changes {
+				codeChanges = append(codeChanges, history_models.CodeChangeRecord{RelativePath: change.RelativePath, Code: change.Code})
 
 				// Prompt the user to accept or reject the changes
-				promptAccepted, err := utils.ConfirmPrompt(change.RelativePath, reader)
+				promptAccepted, err := utils.ConfirmPrompt(change.RelativePath, editor)
 				if err != nil {
 					fmt.Println(lipgloss.Red.Render(fmt.Sprintf("Error getting user prompt: %v", err)))
 					continue
@@ -194,6 +422,7 @@ startLoop: // Label for the start loop
 						fmt.Println(lipgloss.Red.Render(fmt.Sprintf("Error applying changes: %v", err)))
 						continue
 					}
+					codeChanges[len(codeChanges)-1].Accepted = true
 					fmt.Println(lipgloss.Green.Render("✔️ Changes accepted!"))
 
 					fmt.Print("\r")
@@ -203,36 +432,285 @@ startLoop: // Label for the start loop
 				}
 			}
 
+			recordTurn(false, codeChanges)
+
 			displayTokens()
 		}
 	}
 }
 
-func findCodeSubCommand(command string, rootDependencies *RootDependencies) (bool, bool) {
-	switch command {
-	case ":help":
-		helps := ":clear  Clear screen\n:exit  Exit from codai\n:token  Token information\n:clear-token  Clear token from session\n:clear-history  Clear history of chat from session"
-		styledHelps := lipgloss.BoxStyle.Render(helps)
-		fmt.Println(styledHelps)
-		return true, false
-	case ":clear":
-		fmt.Print("\033[2J\033[H")
-		return true, false
-	case ":exit":
-		return false, true
-	case ":token":
-		rootDependencies.TokenManagement.DisplayTokens(
-			rootDependencies.Config.AIProviderConfig.Provider,
-			rootDependencies.Config.AIProviderConfig.Model,
-		)
-		return true, false
-	case ":clear-token":
-		rootDependencies.TokenManagement.ClearToken()
-		return true, false
-	case ":clear-history":
-		rootDependencies.ChatHistory.ClearHistory()
-		return true, false
-	default:
-		return false, false
+// handleTuiCommand runs the code session as a full-screen terminal UI
+func handleTuiCommand(rootDependencies *RootDependencies) {
+
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	spinner := pterm.DefaultSpinner.WithStyle(pterm.NewStyle(pterm.FgLightBlue)).WithSequence("⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏").WithDelay(100).WithRemoveWhenDone(true)
+
+	spinnerLoadContext, _ := spinner.Start("Loading Context...")
+	rootDependencies.Analyzer.SetProgressHandler(func(processed int, total int) {
+		spinnerLoadContext.UpdateText(fmt.Sprintf("Loading Context... %d/%d files", processed, total))
+	})
+
+	// Get all data files from the root directory
+	fullContext, err := rootDependencies.Analyzer.GetProjectFiles(rootDependencies.Cwd)
+
+	spinnerLoadContext.Stop()
+	fmt.Print("\r")
+
+	if err != nil {
+		fmt.Println(lipgloss.Red.Render(fmt.Sprintf("%v", err)))
+	}
+	rootDependencies.Analyzer.SetProgressHandler(nil)
+
+	printAnalyzerWarnings(rootDependencies)
+
+	slashCommands, err = utils.LoadSlashCommands(rootDependencies.Cwd)
+	if err != nil {
+		fmt.Println(lipgloss.Red.Render(fmt.Sprintf("%v", err)))
+	}
+
+	rootDependencies.ChatHistory.StartSession(rootDependencies.Cwd, rootDependencies.Config.AIProviderConfig.Provider, rootDependencies.Config.AIProviderConfig.Model, rootDependencies.Config.SaveSessions)
+
+	// The commands of the session are the ones of the line mode, the TUI shows their output in its chat pane
+	var model *tui.Model
+	commandRegistry := newCodeCommandRegistry(rootDependencies, func(scopedContext *models.FullContextData) {
+		model.SetFullContext(scopedContext)
+	})
+
+	model = tui.NewModel(ctx, tui.Dependencies{
+		ChatProvider:    rootDependencies.CurrentChatProvider,
+		Analyzer:        rootDependencies.Analyzer,
+		ChatHistory:     rootDependencies.ChatHistory,
+		TokenManagement: rootDependencies.TokenManagement,
+		Config:          rootDependencies.Config,
+		Cwd:             rootDependencies.Cwd,
+		FullContext:     fullContext,
+		SlashCommands:   slashCommands,
+		Commands:        commandRegistry,
+		PendingImages: func() []provider_models.Image {
+			images := pendingImages
+			pendingImages = nil
+			return images
+		},
+		SetLastReasoning: func(reasoning string) {
+			lastReasoning = reasoning
+		},
+	})
+
+	if _, err := tea.NewProgram(model, tea.WithAltScreen(), tea.WithContext(ctx)).Run(); err != nil && !errors.Is(err, tea.ErrProgramKilled) {
+		fmt.Println(lipgloss.Red.Render(fmt.Sprintf("%v", err)))
+	}
+
+	rootDependencies.ChatHistory.ClearHistory()
+	rootDependencies.TokenManagement.ClearToken()
+}
+
+// newCodeCommandRegistry registers the built-in commands of the code session and the external 'codai-<cmd>' commands on PATH,
+// onContextChange receives the context of project reloaded by a command, e.g., ':scope'
+func newCodeCommandRegistry(rootDependencies *RootDependencies, onContextChange func(fullContext *models.FullContextData)) contracts_commands.ICommandRegistry {
+	registry := session_commands.NewCommandRegistry(func() commands_models.SessionState {
+		return commands_models.SessionState{
+			Cwd:      rootDependencies.Cwd,
+			Provider: rootDependencies.Config.AIProviderConfig.Provider,
+			Model:    rootDependencies.Config.AIProviderConfig.Model,
+			History:  rootDependencies.ChatHistory.GetHistory(),
+		}
+	})
+
+	registry.Register(commands_models.Command{
+		Name:        "help",
+		Aliases:     []string{"h"},
+		Description: "Help for code subcommand",
+		Run: func(args []string) (bool, error) {
+			helps := registry.Help()
+			for _, name := range utils.SlashCommandNames(slashCommands) {
+				helps += fmt.Sprintf("\n%s  %s", name, slashCommands[strings.TrimPrefix(name, "/")].Description)
+			}
+			fmt.Println(lipgloss.BoxStyle.Render(helps))
+			return false, nil
+		},
+	})
+	registry.Register(commands_models.Command{
+		Name:        "clear",
+		Description: "Clear screen",
+		Run: func(args []string) (bool, error) {
+			fmt.Print("\033[2J\033[H")
+			return false, nil
+		},
+	})
+	registry.Register(commands_models.Command{
+		Name:        "exit",
+		Aliases:     []string{"quit", "q"},
+		Description: "Exit from codai",
+		Run: func(args []string) (bool, error) {
+			return true, nil
+		},
+	})
+	registry.Register(commands_models.Command{
+		Name:        "token",
+		Description: "Token information",
+		Run: func(args []string) (bool, error) {
+			rootDependencies.TokenManagement.DisplayTokens(
+				rootDependencies.Config.AIProviderConfig.Provider,
+				rootDependencies.Config.AIProviderConfig.Model,
+			)
+			return false, nil
+		},
+	})
+	registry.Register(commands_models.Command{
+		Name:        "clear-token",
+		Description: "Clear token from session",
+		Run: func(args []string) (bool, error) {
+			rootDependencies.TokenManagement.ClearToken()
+			return false, nil
+		},
+	})
+	registry.Register(commands_models.Command{
+		Name:        "clear-history",
+		Description: "Clear history of chat from session",
+		Run: func(args []string) (bool, error) {
+			rootDependencies.ChatHistory.ClearHistory()
+			return false, nil
+		},
+	})
+	registry.Register(commands_models.Command{
+		Name:        "reasoning",
+		Description: "Show reasoning of the last answer",
+		Run: func(args []string) (bool, error) {
+			if lastReasoning == "" {
+				fmt.Println(lipgloss.Gray.Render("No reasoning for the last answer."))
+				return false, nil
+			}
+			utils.RenderReasoning(lastReasoning)
+			fmt.Println()
+			return false, nil
+		},
+	})
+	registry.Register(commands_models.Command{
+		Name:        "image",
+		Usage:       "<path>",
+		Description: "Attach an image to the next request (or mention it like @screenshot.png)",
+		MinArgs:     1,
+		MaxArgs:     -1,
+		Run: func(args []string) (bool, error) {
+			attachImage(strings.Join(args, " "))
+			return false, nil
+		},
+	})
+
+	registry.Register(commands_models.Command{
+		Name:        "export",
+		Usage:       "<file>",
+		Description: "Export the session to a Markdown, HTML or JSON file by its extension",
+		MinArgs:     1,
+		MaxArgs:     1,
+		Run: func(args []string) (bool, error) {
+			return false, exportSession(rootDependencies.ChatHistory.GetSession(), args[0], chat_history.ExportFormatFromPath(args[0]))
+		},
+	})
+
+	registry.Register(commands_models.Command{
+		Name:        "scope",
+		Usage:       "[paths|names|+path|-path|all]",
+		Description: "Show or change the directories the context is limited to, e.g., ':scope services/billing,libs/common'",
+		MaxArgs:     -1,
+		Run: func(args []string) (bool, error) {
+			return false, handleScopeCommand(rootDependencies, args, onContextChange)
+		},
+	})
+
+	registry.RegisterExternalCommands()
+
+	return registry
+}
+
+// attachImage queues an image for the next request
+func attachImage(path string) {
+	image, err := utils.LoadImage(path)
+	if err != nil {
+		fmt.Println(lipgloss.Red.Render(fmt.Sprintf("%v", err)))
+		return
+	}
+
+	pendingImages = append(pendingImages, *image)
+	fmt.Println(lipgloss.Green.Render(fmt.Sprintf("✔️ Image %s attached to the next request.", image.Path)))
+}
+
+// isVisionSupported checks the model catalog before sending images, the user decides for a model missing from the catalog
+func isVisionSupported(rootDependencies *RootDependencies, editor *utils.LineEditor) bool {
+	provider := rootDependencies.Config.AIProviderConfig.Provider
+	model := rootDependencies.Config.AIProviderConfig.Model
+
+	supportsVision, err := rootDependencies.TokenManagement.SupportsVision(provider, model)
+	if err != nil {
+		sendImages, _ := utils.ConfirmUnknownVision(editor, model)
+		if !sendImages {
+			fmt.Println(lipgloss.Yellow.Render("⏹ Request skipped."))
+		}
+		return sendImages
+	}
+
+	if !supportsVision {
+		fmt.Println(lipgloss.Red.Render(fmt.Sprintf("🚫 Model '%s' is not vision-capable, choose a vision model with '--model' to send images.", model)))
+		return false
+	}
+
+	return true
+}
+
+// recordUsage appends the tokens and cost of the current turn to the usage ledger and returns them
+func recordUsage(rootDependencies *RootDependencies) token_models.Usage {
+	provider := rootDependencies.Config.AIProviderConfig.Provider
+	model := rootDependencies.Config.AIProviderConfig.Model
+
+	usage := rootDependencies.TokenManagement.TurnUsage(provider, model)
+	if err := token_management.RecordUsage(rootDependencies.Cwd, provider, model, usage); err != nil {
+		fmt.Println(lipgloss.Red.Render(fmt.Sprintf("%v", err)))
+	}
+
+	printBudgetCheck(rootDependencies.TokenManagement.CheckBudget(rootDependencies.Config.Budget, provider, model))
+
+	return usage
+}
+
+// confirmRequestBudget warns about the limits of budget near their threshold, and blocks a request above a limit
+// or asks to confirm it, depending on 'budget.on_exceed'
+func confirmRequestBudget(rootDependencies *RootDependencies, editor *utils.LineEditor, prompt string) bool {
+	provider := rootDependencies.Config.AIProviderConfig.Provider
+	model := rootDependencies.Config.AIProviderConfig.Model
+	budget := rootDependencies.Config.Budget
+
+	estimatedCost := rootDependencies.TokenManagement.EstimateRequestCost(provider, model, prompt)
+	check := rootDependencies.TokenManagement.PreflightBudget(budget, provider, model, estimatedCost)
+	printBudgetCheck(check)
+
+	if len(check.Exceeded) == 0 {
+		return true
+	}
+
+	if budget.OnExceed == token_management.BudgetBlock {
+		fmt.Println(lipgloss.Red.Render("🚫 The request is blocked by the budget, raise the limits in the 'budget' config to continue."))
+		return false
+	}
+
+	confirmed, _ := utils.ConfirmBudget(editor)
+	return confirmed
+}
+
+// printBudgetCheck shows the warnings and the exceeded limits of budget
+func printBudgetCheck(check token_models.BudgetCheck) {
+	for _, warning := range check.Warnings {
+		fmt.Println(lipgloss.Yellow.Render("⚠️ " + warning))
+	}
+	for _, exceeded := range check.Exceeded {
+		fmt.Println(lipgloss.Red.Render("💸 " + exceeded))
+	}
+}
+
+// printAnalyzerWarnings prints the warnings of the analysis of the project, e.g., the broken queries of '.codai/queries'
+func printAnalyzerWarnings(rootDependencies *RootDependencies) {
+	for _, warning := range rootDependencies.Analyzer.Warnings() {
+		fmt.Println(lipgloss.Yellow.Render(warning))
 	}
 }
@@ -0,0 +1,147 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/meysamhadeli/codai/code_analyzer"
+	code_analyzer_models "github.com/meysamhadeli/codai/code_analyzer/models"
+	"github.com/meysamhadeli/codai/constants/lipgloss"
+	"github.com/meysamhadeli/codai/token_management"
+	token_models "github.com/meysamhadeli/codai/token_management/models"
+	"github.com/spf13/cobra"
+)
+
+// ContextCmd: codai context
+var contextCmd = &cobra.Command{
+	Use:   "context",
+	Short: "Inspect the files included in the context of project, the excluded ones and the tokens it costs.",
+	Long: `The 'context' subcommand lists the files included in the context of project with the size of their summary, 
+the files excluded with the reason (e.g., an ignore rule, outside '--scope', too large, binary or generated) and the estimated 
+tokens and cost of the prompt sent on every request with the configured model, e.g., to tune the ignore rules of a project.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		rootDependencies := handleRootCommand(cmd)
+		tree, _ := cmd.Flags().GetBool("tree")
+		tokens, _ := cmd.Flags().GetBool("tokens")
+		asJSON, _ := cmd.Flags().GetBool("json")
+		handleContextCommand(rootDependencies, tree, tokens, asJSON)
+	},
+}
+
+// contextReport is the report of the context of project printed by 'codai context'
+type contextReport struct {
+	Included       []contextFile                       `json:"included"`
+	Excluded       []code_analyzer_models.ExcludedFile `json:"excluded"`
+	SummaryBytes   int                                 `json:"summary_bytes"`
+	SummaryTokens  int                                 `json:"summary_tokens"`
+	PromptTokens   int                                 `json:"prompt_tokens"` // The stable prefix sent on every request, with the repository map within its budget
+	Provider       string                              `json:"provider"`
+	Model          string                              `json:"model"`
+	MaxInputTokens int                                 `json:"max_input_tokens,omitempty"`
+	EstimatedCost  float64                             `json:"estimated_cost_usd"` // The cost of the prompt tokens of a request with the model
+}
+
+// contextFile is a file included in the context of project with the size of its summary
+type contextFile struct {
+	Path         string `json:"path"`
+	SummaryBytes int    `json:"summary_bytes"`
+	Tokens       int    `json:"tokens"`
+}
+
+func handleContextCommand(rootDependencies *RootDependencies, tree bool, tokens bool, asJSON bool) {
+	fullContext, err := rootDependencies.Analyzer.GetProjectFiles(rootDependencies.Cwd)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, lipgloss.Red.Render(fmt.Sprintf("%v", err)))
+		return
+	}
+
+	report := buildContextReport(rootDependencies, fullContext)
+
+	if asJSON {
+		content, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			fmt.Fprintln(os.Stderr, lipgloss.Red.Render(fmt.Sprintf("%v", err)))
+			return
+		}
+		fmt.Println(string(content))
+		return
+	}
+
+	printAnalyzerWarnings(rootDependencies)
+
+	if !tokens {
+		printContextFiles(report, tree)
+	}
+
+	fmt.Println(lipgloss.Gray.Render(fmt.Sprintf("Included: %d files, summaries of %.1f KB, ~%d tokens", len(report.Included), float64(report.SummaryBytes)/1024, report.SummaryTokens)))
+	fmt.Println(lipgloss.Gray.Render(fmt.Sprintf("Excluded: %d files and directories", len(report.Excluded))))
+	fmt.Println(lipgloss.Gray.Render(fmt.Sprintf("Prompt sent on every request: ~%d tokens", report.PromptTokens)))
+
+	modelLine := fmt.Sprintf("%s (%s): ~$%.4f per request", report.Model, report.Provider, report.EstimatedCost)
+	if report.MaxInputTokens > 0 {
+		modelLine = fmt.Sprintf("%s (%s): %.1f%% of %d input tokens, ~$%.4f per request", report.Model, report.Provider,
+			float64(report.PromptTokens)*100/float64(report.MaxInputTokens), report.MaxInputTokens, report.EstimatedCost)
+	}
+	fmt.Println(lipgloss.Gray.Render(modelLine))
+}
+
+// buildContextReport estimates the size and tokens of the summaries of files and of the prompt with the configured model
+func buildContextReport(rootDependencies *RootDependencies, fullContext *code_analyzer_models.FullContextData) contextReport {
+	providerName := rootDependencies.Config.AIProviderConfig.Provider
+	modelName := rootDependencies.Config.AIProviderConfig.Model
+
+	report := contextReport{Included: []contextFile{}, Excluded: fullContext.ExcludedFiles, Provider: providerName, Model: modelName}
+	if report.Excluded == nil {
+		report.Excluded = []code_analyzer_models.ExcludedFile{}
+	}
+
+	for i, fileData := range fullContext.FileData {
+		file := contextFile{Path: fileData.RelativePath, SummaryBytes: len(fileData.TreeSitterCode), Tokens: token_management.EstimateTokens(fullContext.RawCodes[i])}
+		report.Included = append(report.Included, file)
+		report.SummaryBytes += file.SummaryBytes
+		report.SummaryTokens += file.Tokens
+	}
+
+	finalPrompt, _ := rootDependencies.Analyzer.GeneratePrompt(rootDependencies.Analyzer.RepoMap(fullContext), nil, "", "")
+	report.PromptTokens = token_management.EstimateTokens(finalPrompt)
+	report.EstimatedCost = rootDependencies.TokenManagement.CalculateCost(providerName, modelName, token_models.Usage{InputTokens: report.PromptTokens})
+
+	if _, modelDetails, err := token_management.ResolveModelDetails(providerName, modelName); err == nil {
+		report.MaxInputTokens = modelDetails.MaxInputTokens
+	}
+
+	return report
+}
+
+// printContextFiles prints the included files as a table, or as a tree with '--tree', and the excluded files with the reason
+func printContextFiles(report contextReport, tree bool) {
+	fmt.Println(lipgloss.LightBlue.Render("Included files"))
+	if tree {
+		var paths []string
+		for _, file := range report.Included {
+			paths = append(paths, file.Path)
+		}
+		fmt.Println(code_analyzer.BuildProjectTree(paths))
+	} else {
+		writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(writer, "PATH\tSUMMARY (BYTES)\tTOKENS")
+		for _, file := range report.Included {
+			fmt.Fprintf(writer, "%s\t%d\t%d\n", file.Path, file.SummaryBytes, file.Tokens)
+		}
+		writer.Flush()
+	}
+	fmt.Println()
+
+	if len(report.Excluded) > 0 {
+		fmt.Println(lipgloss.LightBlue.Render("Excluded files"))
+		writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(writer, "PATH\tREASON")
+		for _, excludedFile := range report.Excluded {
+			fmt.Fprintf(writer, "%s\t%s\n", excludedFile.RelativePath, excludedFile.Reason)
+		}
+		writer.Flush()
+		fmt.Println()
+	}
+}
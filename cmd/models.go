@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/meysamhadeli/codai/config"
+	"github.com/meysamhadeli/codai/constants/lipgloss"
+	"github.com/meysamhadeli/codai/token_management"
+	"github.com/spf13/cobra"
+)
+
+// ModelsCmd: codai models
+var modelsCmd = &cobra.Command{
+	Use:   "models",
+	Short: "Inspect the pricing and capabilities of the models in the catalog.",
+	Long: `The 'models' command inspects the catalog of models, the embedded one merged with the user catalog 
+'~/.config/codai/models.json' that adds or overrides models, e.g., fine-tunes, self-hosted models or new releases.`,
+}
+
+// ModelsListCmd: codai models list
+var modelsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the models of the catalog, only the ones of a provider with '--provider'.",
+	Run: func(cmd *cobra.Command, args []string) {
+		var providerName string
+		if cmd.Flags().Changed("provider") {
+			providerName, _ = cmd.Flags().GetString("provider")
+		}
+		handleModelsListCommand(providerName)
+	},
+}
+
+// ModelsShowCmd: codai models show [model]
+var modelsShowCmd = &cobra.Command{
+	Use:   "show [model]",
+	Short: "Show the pricing and capabilities of a model, the configured model by default.",
+	Args:  cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		cwd, err := os.Getwd()
+		if err != nil {
+			fmt.Println(lipgloss.Red.Render(fmt.Sprintf("%v", err)))
+			return
+		}
+
+		cfg := config.LoadConfigs(cmd, cwd)
+
+		modelName := cfg.AIProviderConfig.Model
+		if len(args) > 0 {
+			modelName = args[0]
+		}
+		handleModelsShowCommand(cfg.AIProviderConfig.Provider, modelName)
+	},
+}
+
+func handleModelsListCommand(providerName string) {
+	modelCatalog, err := token_management.GetModelCatalog()
+	if err != nil {
+		fmt.Println(lipgloss.Red.Render(fmt.Sprintf("%v", err)))
+		if modelCatalog == nil {
+			return
+		}
+	}
+
+	keys := token_management.ModelCatalogKeys(modelCatalog, providerName)
+	if len(keys) == 0 {
+		fmt.Println(lipgloss.Gray.Render(fmt.Sprintf("No models found for provider '%s'.", providerName)))
+		return
+	}
+
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(writer, "MODEL\tMAX INPUT\tINPUT ($/1M)\tOUTPUT ($/1M)\tCACHED ($/1M)\tVISION\tSOURCE")
+	for _, key := range keys {
+		modelDetails := modelCatalog[key]
+		fmt.Fprintf(writer, "%s\t%d\t%.4f\t%.4f\t%.4f\t%t\t%s\n", key, modelDetails.MaxInputTokens,
+			modelDetails.InputCostPerToken*1e6, modelDetails.OutputCostPerToken*1e6, modelDetails.CacheReadInputTokenCost*1e6,
+			modelDetails.SupportsVision, modelDetails.Source)
+	}
+	writer.Flush()
+}
+
+func handleModelsShowCommand(providerName string, modelName string) {
+	key, modelDetails, err := token_management.ResolveModelDetails(providerName, modelName)
+	if err != nil {
+		fmt.Println(lipgloss.Red.Render(fmt.Sprintf("%v", err)))
+		return
+	}
+
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(writer, "Model:\t%s\n", key)
+	fmt.Fprintf(writer, "Provider:\t%s\n", modelDetails.Provider)
+	fmt.Fprintf(writer, "Mode:\t%s\n", modelDetails.Mode)
+	fmt.Fprintf(writer, "Max input tokens:\t%d\n", modelDetails.MaxInputTokens)
+	fmt.Fprintf(writer, "Max output tokens:\t%d\n", modelDetails.MaxOutputTokens)
+	fmt.Fprintf(writer, "Input cost:\t%.4f $/1M tokens\n", modelDetails.InputCostPerToken*1e6)
+	fmt.Fprintf(writer, "Output cost:\t%.4f $/1M tokens\n", modelDetails.OutputCostPerToken*1e6)
+	fmt.Fprintf(writer, "Cached input cost:\t%.4f $/1M tokens\n", modelDetails.CacheReadInputTokenCost*1e6)
+	fmt.Fprintf(writer, "Function calling:\t%t\n", modelDetails.SupportsFunctionCalling)
+	fmt.Fprintf(writer, "Vision:\t%t\n", modelDetails.SupportsVision)
+	fmt.Fprintf(writer, "Prompt caching:\t%t\n", modelDetails.SupportsPromptCaching)
+	fmt.Fprintf(writer, "Source:\t%s\n", modelDetails.Source)
+	writer.Flush()
+}
@@ -0,0 +1,40 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/meysamhadeli/codai/constants/lipgloss"
+	"github.com/spf13/cobra"
+	"strings"
+)
+
+// PromptCmd: codai prompt
+var promptCmd = &cobra.Command{
+	Use:   "prompt",
+	Short: "Inspect the prompt that codai sends to the AI provider.",
+}
+
+// PromptShowCmd: codai prompt show
+var promptShowCmd = &cobra.Command{
+	Use:   "show [request]",
+	Short: "Print the final rendered prompt for debugging.",
+	Long: `The 'show' subcommand prints the final prompt rendered from the template prompt (or the custom 'prompt_template'), 
+the project instructions of 'instructions_file' (default 'CODAI.md') and the context of the project, followed by the user request.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		rootDependencies := handleRootCommand(cmd)
+		handlePromptShowCommand(rootDependencies, strings.Join(args, " "))
+	},
+}
+
+func handlePromptShowCommand(rootDependencies *RootDependencies, userInput string) {
+	// Get all data files from the root directory
+	fullContext, err := rootDependencies.Analyzer.GetProjectFiles(rootDependencies.Cwd)
+	if err != nil {
+		fmt.Println(lipgloss.Red.Render(fmt.Sprintf("%v", err)))
+		return
+	}
+
+	finalPrompt, userInputPrompt := rootDependencies.Analyzer.GeneratePrompt(rootDependencies.Analyzer.RepoMap(fullContext), rootDependencies.ChatHistory.GetHistory(), userInput, "")
+
+	fmt.Println(finalPrompt)
+	fmt.Println(userInputPrompt)
+}
@@ -50,6 +50,8 @@ var rootCmd = &cobra.Command{
 	},
 }
 
+// handleRootCommand loads the configuration and builds the dependencies of the commands, its errors and warnings go to
+// stderr so the output of a command stays parseable, e.g., 'codai context --json'.
 func handleRootCommand(cmd *cobra.Command) *RootDependencies {
 
 	var err error
@@ -58,26 +60,47 @@ func handleRootCommand(cmd *cobra.Command) *RootDependencies {
 	// Get current working directory
 	rootDependencies.Cwd, err = os.Getwd()
 	if err != nil || rootDependencies.Cwd == "" {
-		fmt.Println(lipgloss.Red.Render(fmt.Sprintf("error getting current directory")))
+		fmt.Fprintln(os.Stderr, lipgloss.Red.Render(fmt.Sprintf("error getting current directory")))
 		return nil
 	}
 
 	rootDependencies.Config = config.LoadConfigs(cmd, rootDependencies.Cwd)
 
+	// Any other action than 'block' would silently ask before a request above a limit of budget
+	if err := token_management.ValidateBudget(rootDependencies.Config.Budget); err != nil {
+		fmt.Fprintln(os.Stderr, lipgloss.Red.Render(fmt.Sprintf("%v", err)))
+		os.Exit(1)
+	}
+
 	rootDependencies.TokenManagement = token_management.NewTokenManager()
 
+	// A broken user catalog of models is reported, the embedded catalog is still used
+	if _, err := token_management.GetModelCatalog(); err != nil {
+		fmt.Fprintln(os.Stderr, lipgloss.Yellow.Render(fmt.Sprintf("%v", err)))
+	}
+
 	rootDependencies.ChatHistory = chat_history.NewChatHistory()
 
 	rootDependencies.Analyzer = code_analyzer.NewCodeAnalyzer(rootDependencies.Cwd)
 
+	rootDependencies.Analyzer.ConfigureContext(rootDependencies.Config.Context)
+
+	// An unknown scope would silently load the whole project, e.g., a monorepo too large for the context
+	if err := rootDependencies.Analyzer.SetScope(rootDependencies.Config.Context.Scope); err != nil {
+		fmt.Fprintln(os.Stderr, lipgloss.Red.Render(fmt.Sprintf("%v", err)))
+		os.Exit(1)
+	}
+
+	err = rootDependencies.Analyzer.ConfigurePrompt(rootDependencies.Config.InstructionsFile, rootDependencies.Config.PromptTemplate)
+
 	if err != nil {
-		fmt.Println(lipgloss.Red.Render(fmt.Sprintf("%v", err)))
+		fmt.Fprintln(os.Stderr, lipgloss.Red.Render(fmt.Sprintf("%v", err)))
 	}
 
 	rootDependencies.CurrentChatProvider, err = providers.ChatProviderFactory(rootDependencies.Config.AIProviderConfig, rootDependencies.TokenManagement)
 
 	if err != nil {
-		fmt.Println(lipgloss.Red.Render(fmt.Sprintf("%v", err)))
+		fmt.Fprintln(os.Stderr, lipgloss.Red.Render(fmt.Sprintf("%v", err)))
 	}
 
 	return rootDependencies
@@ -94,6 +117,27 @@ func Execute() {
 func init() {
 	config.InitFlags(rootCmd)
 
+	codeCmd.Flags().Bool("tui", false, "Run the code session as a full-screen terminal UI with chat, context, diff-review panes and a status bar.")
+
 	// Register subcommands
 	rootCmd.AddCommand(codeCmd)
+	rootCmd.AddCommand(promptCmd)
+	promptCmd.AddCommand(promptShowCmd)
+	rootCmd.AddCommand(sessionsCmd)
+	sessionsCmd.AddCommand(sessionsListCmd)
+	sessionsCmd.AddCommand(sessionsExportCmd)
+	sessionsExportCmd.Flags().String("format", chat_history.FormatMarkdown, "The format of the export, 'md', 'html' or 'json'.")
+	sessionsExportCmd.Flags().StringP("output", "o", "", "The file to write the export to, stdout by default.")
+	rootCmd.AddCommand(modelsCmd)
+	modelsCmd.AddCommand(modelsListCmd)
+	modelsCmd.AddCommand(modelsShowCmd)
+	rootCmd.AddCommand(usageCmd)
+	rootCmd.AddCommand(analyzeCmd)
+	rootCmd.AddCommand(contextCmd)
+	contextCmd.Flags().Bool("tree", false, "Print the included files as a tree.")
+	contextCmd.Flags().Bool("tokens", false, "Print only the estimated tokens and cost, without the lists of files.")
+	contextCmd.Flags().Bool("json", false, "Print the report as JSON.")
+	analyzeCmd.Flags().String("lang", "", "The language of the file, e.g., 'go', detected from its extension by default.")
+	usageCmd.Flags().String("since", "30d", "The start of the report, a number of days like '7d', a duration like '12h' or a date like '2025-01-31'.")
+	usageCmd.Flags().String("by", token_management.GroupByModel, "Group the usage by 'model' or 'project'.")
 }
@@ -0,0 +1,110 @@
+package cmd
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/meysamhadeli/codai/code_analyzer"
+	code_analyzer_models "github.com/meysamhadeli/codai/code_analyzer/models"
+	"github.com/meysamhadeli/codai/constants/lipgloss"
+)
+
+// handleScopeCommand shows the scope of the context without arguments, else changes it and reloads the context of
+// project, e.g., ':scope services/billing,libs/common', ':scope +libs/auth', ':scope -libs/common' or ':scope all'.
+func handleScopeCommand(rootDependencies *RootDependencies, args []string, onContextChange func(fullContext *code_analyzer_models.FullContextData)) error {
+	if len(args) == 0 {
+		printScope(rootDependencies)
+		return nil
+	}
+
+	scope := scopeFromArgs(rootDependencies.Analyzer.Scope(), rootDependencies.Analyzer.NamedScopes(), args)
+	if err := rootDependencies.Analyzer.SetScope(scope); err != nil {
+		return err
+	}
+
+	fullContext, err := rootDependencies.Analyzer.GetProjectFiles(rootDependencies.Cwd)
+	if err != nil {
+		return err
+	}
+	onContextChange(fullContext)
+
+	fmt.Println(lipgloss.Green.Render(fmt.Sprintf("✔️ Scope: %s, %d files in the context.", describeScope(rootDependencies.Analyzer.Scope()), len(fullContext.FileData))))
+	return nil
+}
+
+// scopeFromArgs returns the scope from the arguments of ':scope', separated by spaces or commas. The paths and names
+// replace the scope, unless all of them add to it with '+' or remove from it with '-'. 'all' is the whole project.
+func scopeFromArgs(current []string, namedScopes map[string][]string, args []string) []string {
+	var names []string
+	for _, arg := range args {
+		for _, name := range strings.Split(arg, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				names = append(names, name)
+			}
+		}
+	}
+
+	scope := slices.Clone(current)
+	if slices.ContainsFunc(names, func(name string) bool { return !strings.HasPrefix(name, "+") && !strings.HasPrefix(name, "-") }) {
+		scope = nil
+	}
+
+	for _, name := range names {
+		switch {
+		case name == "all":
+			return []string{"."}
+		case strings.HasPrefix(name, "-"):
+			removed := strings.TrimPrefix(name, "-")
+			scope = slices.DeleteFunc(scope, func(path string) bool {
+				return strings.TrimSuffix(path, "/") == strings.TrimSuffix(removed, "/") || slices.Contains(namedScopes[removed], path)
+			})
+		default:
+			scope = append(scope, strings.TrimPrefix(name, "+"))
+		}
+	}
+
+	// Removing the last path of the scope widens it to the whole project
+	if len(scope) == 0 {
+		return []string{"."}
+	}
+	return scope
+}
+
+// printScope prints the scope of the context, the named scopes of 'context.scopes' and the detected workspaces.
+func printScope(rootDependencies *RootDependencies) {
+	fmt.Println(lipgloss.LightBlue.Render("Scope: ") + describeScope(rootDependencies.Analyzer.Scope()))
+
+	namedScopes := rootDependencies.Analyzer.NamedScopes()
+	if len(namedScopes) > 0 {
+		names := make([]string, 0, len(namedScopes))
+		for name := range namedScopes {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		fmt.Println(lipgloss.LightBlue.Render("Named scopes:"))
+		for _, name := range names {
+			fmt.Printf("  %s  %s\n", name, lipgloss.Gray.Render(strings.Join(namedScopes[name], ", ")))
+		}
+	}
+
+	workspaces := code_analyzer.DetectWorkspaces(rootDependencies.Cwd)
+	if len(workspaces) > 0 {
+		fmt.Println(lipgloss.LightBlue.Render("Detected workspaces:"))
+		for _, workspace := range workspaces {
+			fmt.Printf("  %s  %s\n", workspace.Path, lipgloss.Gray.Render(workspace.Kind))
+		}
+	}
+
+	fmt.Println(lipgloss.Gray.Render("Change it with ':scope <paths or names>', ':scope +<path>', ':scope -<path>' or ':scope all'."))
+}
+
+// describeScope joins the paths of the scope, or names the whole project for an empty scope.
+func describeScope(scope []string) string {
+	if len(scope) == 0 {
+		return "whole project"
+	}
+	return strings.Join(scope, ", ")
+}
@@ -0,0 +1,88 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/meysamhadeli/codai/chat_history"
+	history_models "github.com/meysamhadeli/codai/chat_history/models"
+	"github.com/meysamhadeli/codai/constants/lipgloss"
+	"github.com/spf13/cobra"
+	"os"
+)
+
+// SessionsCmd: codai sessions
+var sessionsCmd = &cobra.Command{
+	Use:   "sessions",
+	Short: "List and export the recorded code sessions.",
+}
+
+// SessionsListCmd: codai sessions list
+var sessionsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the recorded code sessions, the latest first.",
+	Run: func(cmd *cobra.Command, args []string) {
+		handleSessionsListCommand()
+	},
+}
+
+// SessionsExportCmd: codai sessions export <id>
+var sessionsExportCmd = &cobra.Command{
+	Use:   "export <id>",
+	Short: "Export a code session as Markdown, HTML or JSON.",
+	Long: `The 'export' subcommand exports every user prompt, the answers of AI, the code changes with their accept/reject status, 
+and the tokens and cost per turn of a recorded session, e.g., to attach it to a pull request as a record of AI-assisted changes.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		format, _ := cmd.Flags().GetString("format")
+		output, _ := cmd.Flags().GetString("output")
+		handleSessionsExportCommand(args[0], format, output)
+	},
+}
+
+func handleSessionsListCommand() {
+	sessions, err := chat_history.ListSessions()
+	if err != nil {
+		fmt.Println(lipgloss.Red.Render(fmt.Sprintf("%v", err)))
+		return
+	}
+
+	if len(sessions) == 0 {
+		fmt.Println(lipgloss.Gray.Render("No recorded sessions."))
+		return
+	}
+
+	for _, session := range sessions {
+		fmt.Printf("%s  %s  %s/%s  %d turns  %s\n", lipgloss.LightBlue.Render(session.ID), session.StartedAt.Format("2006-01-02 15:04"), session.Provider, session.Model, len(session.Turns), session.Cwd)
+	}
+}
+
+func handleSessionsExportCommand(id string, format string, output string) {
+	session, err := chat_history.LoadSession(id)
+	if err != nil {
+		fmt.Println(lipgloss.Red.Render(fmt.Sprintf("%v", err)))
+		return
+	}
+
+	if err := exportSession(session, output, format); err != nil {
+		fmt.Println(lipgloss.Red.Render(fmt.Sprintf("%v", err)))
+	}
+}
+
+// exportSession writes the export of a session to a file, or to stdout when the path is empty
+func exportSession(session history_models.Session, path string, format string) error {
+	content, err := chat_history.ExportSession(session, format)
+	if err != nil {
+		return err
+	}
+
+	if path == "" {
+		fmt.Println(string(content))
+		return nil
+	}
+
+	if err := os.WriteFile(path, content, 0644); err != nil {
+		return fmt.Errorf("failed to write export %s: %w", path, err)
+	}
+
+	fmt.Println(lipgloss.Green.Render(fmt.Sprintf("✔️ Session exported to %s", path)))
+	return nil
+}
@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/meysamhadeli/codai/constants/lipgloss"
+	"github.com/meysamhadeli/codai/token_management"
+	"github.com/spf13/cobra"
+)
+
+// UsageCmd: codai usage
+var usageCmd = &cobra.Command{
+	Use:   "usage",
+	Short: "Report the tokens and cost of the recorded turns by model or project.",
+	Long: `The 'usage' subcommand reports the input, output, cached and reasoning tokens and the cost recorded in the 
+usage ledger '~/.config/codai/usage.jsonl', e.g., 'codai usage --since 7d --by project' for team budgeting.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		since, _ := cmd.Flags().GetString("since")
+		by, _ := cmd.Flags().GetString("by")
+		handleUsageCommand(since, by)
+	},
+}
+
+func handleUsageCommand(since string, by string) {
+	sinceTime, err := token_management.ParseSince(since, time.Now())
+	if err != nil {
+		fmt.Println(lipgloss.Red.Render(fmt.Sprintf("%v", err)))
+		return
+	}
+
+	entries, err := token_management.ReadUsage(sinceTime)
+	if err != nil {
+		fmt.Println(lipgloss.Red.Render(fmt.Sprintf("%v", err)))
+		return
+	}
+
+	summaries, err := token_management.SummarizeUsage(entries, by)
+	if err != nil {
+		fmt.Println(lipgloss.Red.Render(fmt.Sprintf("%v", err)))
+		return
+	}
+
+	if len(summaries) == 0 {
+		fmt.Println(lipgloss.Gray.Render("No usage recorded."))
+		return
+	}
+
+	header := "MODEL"
+	if by == token_management.GroupByProject {
+		header = "PROJECT"
+	}
+
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(writer, "%s\tTURNS\tINPUT\tCACHED\tOUTPUT\tREASONING\tCOST ($)\n", header)
+
+	var turns, inputTokens, cachedTokens, outputTokens, reasoningTokens int
+	var cost float64
+	for _, summary := range summaries {
+		fmt.Fprintf(writer, "%s\t%d\t%d\t%d\t%d\t%d\t%.6f\n", summary.Key, summary.Turns, summary.InputTokens, summary.CachedTokens, summary.OutputTokens, summary.ReasoningTokens, summary.Cost)
+
+		turns += summary.Turns
+		inputTokens += summary.InputTokens
+		cachedTokens += summary.CachedTokens
+		outputTokens += summary.OutputTokens
+		reasoningTokens += summary.ReasoningTokens
+		cost += summary.Cost
+	}
+	fmt.Fprintf(writer, "TOTAL\t%d\t%d\t%d\t%d\t%d\t%.6f\n", turns, inputTokens, cachedTokens, outputTokens, reasoningTokens, cost)
+
+	writer.Flush()
+}
@@ -5,65 +5,97 @@ import (
 	"fmt"
 	"github.com/meysamhadeli/codai/code_analyzer/contracts"
 	"github.com/meysamhadeli/codai/code_analyzer/models"
-	"github.com/meysamhadeli/codai/embed_data"
 	"github.com/meysamhadeli/codai/utils"
 	sitter "github.com/smacker/go-tree-sitter"
-	"github.com/smacker/go-tree-sitter/csharp"
-	"github.com/smacker/go-tree-sitter/golang"
-	"github.com/smacker/go-tree-sitter/java"
-	"github.com/smacker/go-tree-sitter/javascript"
-	"github.com/smacker/go-tree-sitter/python"
-	"github.com/smacker/go-tree-sitter/typescript/typescript"
 	"io/fs"
 	"io/ioutil"
-	"log"
 	"os"
+	"path"
 	"path/filepath"
 	"regexp"
+	"slices"
+	"sort"
 	"strings"
+	"sync"
+	"text/template"
 )
 
 // CodeAnalyzer handles the analysis of project files.
 type CodeAnalyzer struct {
-	Cwd string
+	Cwd             string
+	instructions    string
+	promptTemplate  *template.Template
+	exportedOnly    bool
+	mutex           sync.Mutex                   // Guards the queries and warnings shared by the workers scanning the files
+	queries         map[string]map[string]string // The loaded queries of the languages, tagged by the type of their elements
+	compiledQueries map[string]*languageQueries  // The compiled queries of the languages, nil for a language without queries
+	warnings        []string
+	onProgress      func(processed int, total int) // Reports the progress of the scan of the files of the project
+	mapTokens       int                            // The token budget of the repository map in the prompt, 0 for no limit
+	focusFiles      []string                       // The files mentioned or edited in the session
+	relatedFiles    []string                       // The files most related to the focus files, from the last repository map
+	include         []string                       // The gitignore patterns of the files kept in the context, all files when empty
+	exclude         []string                       // The gitignore patterns of the files removed from the context
+	maxFileSizeKB   int                            // The size in KB above which a file is skipped, 0 for no limit
+	maxFiles        int                            // The number of files read for the context, the next files are skipped, 0 for no limit
+	scope           []string                       // The paths the context is limited to, the whole project when empty
+	namedScopes     map[string][]string            // The scopes of 'context.scopes' with their paths
 }
 
+// defaultMaxFileSizeKB is the size in KB above which a file is skipped until the context is configured
+const defaultMaxFileSizeKB = 100
+
 func (analyzer *CodeAnalyzer) GeneratePrompt(codes []string, history []string, userInput string, requestedContext string) (string, string) {
 
-	promptTemplate := string(embed_data.SummarizeFullContextPrompt)
+	promptTemplate := analyzer.renderPromptTemplate(codes)
+
+	// Combine the relevant code into a single string, the top of the ranked codes filling the token budget of the map
+	code := analyzer.fillTokenBudget(codes)
+
+	// The stable prefix, same on every turn of the session so the providers can cache it: the template prompt,
+	// the summary of context of project and the project instructions, e.g., team conventions from 'CODAI.md'
+	finalPrompt := fmt.Sprintf("%s\n\n______\n%s\n\n______\n", fmt.Sprintf("## Here is the general template prompt for using AI\n\n%s", promptTemplate), fmt.Sprintf("## Here is the summary of context of project\n\n%s", code))
+
+	if analyzer.instructions != "" {
+		finalPrompt = finalPrompt + fmt.Sprintf("## Here are the instructions of the project that you must follow\n\n%s\n\n______\n", analyzer.instructions)
+	}
 
-	// Combine the relevant code into a single string
-	code := strings.Join(codes, "\n---------\n\n")
+	// The parts changing on every turn come after the stable prefix, with the user request
+	userInputPrompt := "## Here is the history of chats\n\n" + strings.Join(history, "\n---------\n\n") + "\n\n______\n\n"
 
-	prompt := fmt.Sprintf("%s\n\n______\n%s\n\n______\n", fmt.Sprintf("## Here is the summary of context of project\n\n%s", code), fmt.Sprintf("## Here is the general template prompt for using AI\n\n%s", promptTemplate))
-	userInputPrompt := fmt.Sprintf("## Here is user request\n%s", userInput)
+	if len(analyzer.relatedFiles) > 0 {
+		userInputPrompt = userInputPrompt + fmt.Sprintf("## Here are the files of project most related to the files mentioned or edited in this session\n\n%s\n\n______\n\n", strings.Join(analyzer.relatedFiles, ", "))
+	}
 
 	if requestedContext != "" {
-		prompt = prompt + fmt.Sprintf("## Here are the requsted full context files for using in your task\n\n%s______\n", requestedContext)
+		userInputPrompt = userInputPrompt + fmt.Sprintf("## Here are the requsted full context files for using in your task\n\n%s______\n\n", requestedContext)
 	}
 
-	historyPrompt := "## Here is the history of chats\n\n" + strings.Join(history, "\n---------\n\n")
-	finalPrompt := fmt.Sprintf("%s\n\n______\n\n%s", historyPrompt, prompt)
+	userInputPrompt = userInputPrompt + fmt.Sprintf("## Here is user request\n%s", userInput)
 
 	return finalPrompt, userInputPrompt
 }
 
 // NewCodeAnalyzer initializes a new CodeAnalyzer.
 func NewCodeAnalyzer(cwd string) contracts.ICodeAnalyzer {
-	return &CodeAnalyzer{Cwd: cwd}
+	return &CodeAnalyzer{Cwd: cwd, maxFileSizeKB: defaultMaxFileSizeKB}
 }
 
+// GetProjectFiles walks the project for its files not ignored, then reads and summarizes them with a pool of workers.
+// The files and the excluded files are in the order of the walk whatever the order of the workers.
 func (analyzer *CodeAnalyzer) GetProjectFiles(rootDir string) (*models.FullContextData, error) {
-	var result models.FullContextData
+	var entries []scanEntry
+	queued := 0
+	tooMany := 0
 
-	// Retrieve the ignore patterns from .gitignore, if it exists
-	gitIgnorePatterns, err := utils.GetGitignorePatterns(rootDir)
-	if err != nil {
-		return nil, err
-	}
+	// The patterns of '.gitignore' and '.codai-gitignore' are added for each directory of the walk, the ones of a
+	// nested directory winning over the ones of its parents
+	gitIgnoreMatcher := utils.NewPathMatcher()
+	excludeMatcher := utils.NewPathMatcher(analyzer.exclude...)
+	includeMatcher := utils.NewPathMatcher(analyzer.include...)
 
 	// Walk the directory tree and find all files
-	err = filepath.WalkDir(rootDir, func(path string, d fs.DirEntry, err error) error {
+	err := filepath.WalkDir(rootDir, func(path string, d fs.DirEntry, err error) error {
 		if err != nil {
 			return err
 		}
@@ -71,49 +103,46 @@ func (analyzer *CodeAnalyzer) GetProjectFiles(rootDir string) (*models.FullConte
 		relativePath, err := filepath.Rel(rootDir, path)
 		relativePath = strings.ReplaceAll(relativePath, "\\", "/")
 
-		// Check if the current directory or file should be skipped based on default ignore patterns
-		if utils.IsDefaultIgnored(relativePath) {
-			// Skip the directory or file
-			if d.IsDir() {
-				// If it's a directory, skip the whole directory
-				return filepath.SkipDir
-			}
-			// If it's a file, just skip the file
-			return nil
+		if relativePath == "." {
+			return gitIgnoreMatcher.AddIgnoreFiles(rootDir, "")
 		}
 
-		// Ensure that the current entry is a file, not a directory
-		if !d.IsDir() {
-
-			// Check file size
-			fileInfo, err := os.Stat(path)
-			if err != nil {
-				return fmt.Errorf("failed to get file info: %s, error: %w", relativePath, err)
-			}
-			// Skip files over 100 KB (100 * 1024 bytes)
-			if fileInfo.Size() > 100*1024 {
-				return nil // Skip this file
-			}
+		// Check if the current directory or file should be skipped based on default ignore patterns
+		if utils.IsDefaultIgnored(relativePath, d.IsDir()) {
+			return skipEntry(&entries, relativePath, d.IsDir(), models.ExcludedByIgnoreRule)
+		}
 
-			if utils.IsGitIgnored(relativePath, gitIgnorePatterns) {
-				// Debugging: Print the ignored file
-				return nil // Skip this file
-			}
+		if !analyzer.inScope(relativePath, d.IsDir()) {
+			return skipEntry(&entries, relativePath, d.IsDir(), models.ExcludedOutOfScope)
+		}
 
-			// Read the file content using the full path
-			content, err := ioutil.ReadFile(path) // Use full path from WalkDir
-			if err != nil {
-				return fmt.Errorf("failed to read file: %s, error: %w", relativePath, err)
-			}
+		if gitIgnoreMatcher.Matches(relativePath, d.IsDir()) {
+			return skipEntry(&entries, relativePath, d.IsDir(), models.ExcludedByGitignore)
+		}
+		if excludeMatcher.Matches(relativePath, d.IsDir()) {
+			return skipEntry(&entries, relativePath, d.IsDir(), models.ExcludedByConfig)
+		}
 
-			codeParts := analyzer.ProcessFile(relativePath, content)
+		if d.IsDir() {
+			return gitIgnoreMatcher.AddIgnoreFiles(rootDir, relativePath)
+		}
 
-			// Append the file data to the result
-			result.FileData = append(result.FileData, models.FileData{RelativePath: relativePath, Code: string(content), TreeSitterCode: strings.Join(codeParts, "\n")})
+		if len(analyzer.include) > 0 && !includeMatcher.Matches(relativePath, false) {
+			return skipEntry(&entries, relativePath, false, models.ExcludedNotIncluded)
+		}
+		if utils.IsLockfile(relativePath) {
+			return skipEntry(&entries, relativePath, false, models.ExcludedLockfile)
+		}
 
-			result.RawCodes = append(result.RawCodes, fmt.Sprintf("**File: %s**\n\n%s", relativePath, strings.Join(codeParts, "\n")))
+		// The files above the limit are not read, so a huge project isn't parsed for nothing
+		if analyzer.maxFiles > 0 && queued >= analyzer.maxFiles {
+			tooMany++
+			return skipEntry(&entries, relativePath, false, models.ExcludedTooMany)
 		}
 
+		// The file is read and summarized by the workers
+		queued++
+		entries = append(entries, scanEntry{path: path, relativePath: relativePath})
 		return nil
 	})
 
@@ -121,47 +150,62 @@ func (analyzer *CodeAnalyzer) GetProjectFiles(rootDir string) (*models.FullConte
 		return nil, err
 	}
 
+	if tooMany > 0 {
+		analyzer.mutex.Lock()
+		analyzer.addWarning(fmt.Sprintf("the context is truncated to the first %d files by context.max_files, %d files are skipped", analyzer.maxFiles, tooMany))
+		analyzer.mutex.Unlock()
+	}
+
+	analyzer.scanFiles(entries)
+
+	var result models.FullContextData
+	for _, entry := range entries {
+		switch {
+		case entry.err != nil:
+			return nil, entry.err
+		case entry.reason != "":
+			result.ExcludedFiles = append(result.ExcludedFiles, models.ExcludedFile{RelativePath: entry.relativePath, Reason: entry.reason})
+		default:
+			// Append the file data to the result
+			result.FileData = append(result.FileData, entry.fileData)
+
+			result.RawCodes = append(result.RawCodes, fmt.Sprintf("**File: %s**\n\n%s", entry.relativePath, entry.fileData.TreeSitterCode))
+		}
+	}
+
 	return &result, nil
 }
 
-// ProcessFile processes a single file using Tree-sitter for syntax analysis (for .cs files).
+// skipEntry records an excluded file, or directory with all its files, and skips it in the walk of the project.
+func skipEntry(entries *[]scanEntry, relativePath string, isDir bool, reason string) error {
+	if isDir {
+		*entries = append(*entries, scanEntry{relativePath: relativePath + "/", reason: reason})
+		return filepath.SkipDir
+	}
+	*entries = append(*entries, scanEntry{relativePath: relativePath, reason: reason})
+	return nil
+}
+
+// ProcessFile processes a single file using Tree-sitter for syntax analysis of the supported languages.
 func (analyzer *CodeAnalyzer) ProcessFile(filePath string, sourceCode []byte) []string {
+	elements, _, _ := analyzer.summarizeFile(filePath, utils.GetSupportedLanguage(filePath), sourceCode)
+	return elements
+}
+
+// ProcessFileWithLanguage processes a single file with the grammar and queries of a language, whatever its extension.
+func (analyzer *CodeAnalyzer) ProcessFileWithLanguage(filePath string, languageName string, sourceCode []byte) []string {
+	elements, _, _ := analyzer.summarizeFile(filePath, languageName, sourceCode)
+	return elements
+}
+
+// summarizeFile returns the elements of the summary of a file, the names of its symbols, e.g., 'Foo' for 'function: func Foo()',
+// and the identifiers it references, e.g., the functions it calls.
+func (analyzer *CodeAnalyzer) summarizeFile(filePath string, languageName string, sourceCode []byte) ([]string, []string, []string) {
 	var elements []string
+	var symbols []string
 
-	var parser *sitter.Parser
-	var lang *sitter.Language
-	var query []byte
-
-	language := utils.GetSupportedLanguage(filePath)
-	parser = sitter.NewParser()
-
-	// Determine the parser and language to use
-	switch language {
-	case "csharp":
-		parser.SetLanguage(csharp.GetLanguage())
-		lang = csharp.GetLanguage()
-		query = embed_data.CSharpQuery
-	case "go":
-		parser.SetLanguage(golang.GetLanguage())
-		lang = golang.GetLanguage()
-		query = embed_data.GoQuery
-	case "python":
-		parser.SetLanguage(python.GetLanguage())
-		lang = python.GetLanguage()
-		query = embed_data.PythonQuery
-	case "java":
-		parser.SetLanguage(java.GetLanguage())
-		lang = java.GetLanguage()
-		query = embed_data.JavaQuery
-	case "javascript":
-		parser.SetLanguage(javascript.GetLanguage())
-		lang = javascript.GetLanguage()
-		query = embed_data.JavascriptQuery
-	case "typescript":
-		parser.SetLanguage(typescript.GetLanguage())
-		lang = typescript.GetLanguage()
-		query = embed_data.TypescriptQuery
-	default:
+	compiled := analyzer.compileQueries(languageName)
+	if compiled == nil {
 		// If the language doesn't match, process the original source code directly
 		elements = append(elements, filePath)
 
@@ -169,25 +213,23 @@ func (analyzer *CodeAnalyzer) ProcessFile(filePath string, sourceCode []byte) []
 		// Get the first line
 		elements = append(elements, lines[0]) // Adding First line from the array
 
-		return elements
+		return elements, nil, nil
 	}
 
+	// A parser per file, the parsers can't be shared by the workers scanning the files
+	parser := sitter.NewParser()
+	defer parser.Close()
+	parser.SetLanguage(compiled.grammar)
+
 	// Parse the source code
 	tree := parser.Parse(nil, sourceCode)
+	defer tree.Close()
 
-	// Parse JSON data into a map
-	queries := make(map[string]string)
-	err := json.Unmarshal(query, &queries)
-	if err != nil {
-		log.Fatalf("failed to parse JSON: %v", err)
-	}
+	var summaryElements []summaryElement
+	seen := make(map[string]bool)
 
-	// Execute each query and capture results
-	for tag, queryStr := range queries {
-		query, err := sitter.NewQuery([]byte(queryStr), lang) // Use the appropriate language
-		if err != nil {
-			log.Fatalf("failed to compile query: %v", err)
-		}
+	for _, compiledQuery := range compiled.queries {
+		tag, query := compiledQuery.tag, compiledQuery.query
 
 		cursor := sitter.NewQueryCursor()
 		cursor.Exec(query, tree.RootNode())
@@ -199,16 +241,60 @@ func (analyzer *CodeAnalyzer) ProcessFile(filePath string, sourceCode []byte) []
 				break
 			}
 
+			// Apply the predicates of the query, e.g., '#eq?'
+			match = cursor.FilterPredicates(match, sourceCode)
+
+			// The '@definition' capture holds the whole element for its signature, e.g., a function with its parameters
+			var definition *sitter.Node
+			var names []*sitter.Node
 			for _, cap := range match.Captures {
-				element := cap.Node.Content(sourceCode)
+				captureName := query.CaptureNameForId(cap.Index)
+				switch {
+				case strings.HasPrefix(captureName, "_"):
+					// Captures prefixed with '_' only serve the predicates
+				case strings.HasPrefix(captureName, "definition"):
+					definition = cap.Node
+				default:
+					names = append(names, cap.Node)
+				}
+			}
+
+			for _, name := range names {
 				// Tag the element with its type (e.g., namespace, class, method, interface)
-				taggedElement := fmt.Sprintf("%s: %s", tag, element)
-				elements = append(elements, taggedElement)
+				element := newSummaryElement(languageName, tag, name, definition, sourceCode)
+
+				key := fmt.Sprintf("%d:%s", element.start, element.text)
+				if seen[key] || (analyzer.exportedOnly && !element.exported) {
+					continue
+				}
+				seen[key] = true
+
+				summaryElements = append(summaryElements, element)
 			}
 		}
+		cursor.Close()
 	}
 
-	return elements
+	// Keep the elements in source order
+	sort.SliceStable(summaryElements, func(i, j int) bool {
+		if summaryElements[i].start != summaryElements[j].start {
+			return summaryElements[i].start < summaryElements[j].start
+		}
+		return summaryElements[i].text < summaryElements[j].text
+	})
+
+	for _, element := range summaryElements {
+		if element.doc != "" {
+			elements = append(elements, element.doc)
+		}
+		elements = append(elements, element.text)
+
+		if !slices.Contains(symbols, element.name) {
+			symbols = append(symbols, element.name)
+		}
+	}
+
+	return elements, symbols, referencesOf(tree.RootNode(), sourceCode)
 }
 
 func (analyzer *CodeAnalyzer) TryGetInCompletedCodeBlocK(relativePaths string) (string, error) {
@@ -248,6 +334,84 @@ func (analyzer *CodeAnalyzer) TryGetInCompletedCodeBlocK(relativePaths string) (
 	return requestedContext, nil
 }
 
+// ResolveMentions resolves the '@' mentions of the user input to the full content of files, a mention can be
+// a file path, a directory ending with '/' or a symbol name from the tree-sitter summary. It returns the requested
+// context and the mentions that could not be resolved.
+func (analyzer *CodeAnalyzer) ResolveMentions(mentions []string, fullContext *models.FullContextData) (string, []string) {
+	var codes []string
+	var unresolved []string
+	added := make(map[string]bool)
+
+	addFile := func(fileData models.FileData) {
+		if added[fileData.RelativePath] {
+			return
+		}
+		added[fileData.RelativePath] = true
+		analyzer.addFocusFile(fileData.RelativePath)
+		codes = append(codes, fmt.Sprintf("**File: %s**\n\n%s", fileData.RelativePath, fileData.Code))
+	}
+
+	for _, mention := range mentions {
+		mention = strings.TrimPrefix(filepath.ToSlash(mention), "./")
+		resolved := false
+
+		if fullContext != nil {
+			for _, fileData := range fullContext.FileData {
+				switch {
+				// Exact file path
+				case fileData.RelativePath == mention:
+					addFile(fileData)
+					resolved = true
+				// All files under a directory
+				case strings.HasPrefix(fileData.RelativePath, strings.TrimSuffix(mention, "/")+"/"):
+					addFile(fileData)
+					resolved = true
+				// Files defining a symbol
+				case slices.Contains(fileData.Symbols, mention):
+					addFile(fileData)
+					resolved = true
+				}
+			}
+		}
+
+		if !resolved {
+			unresolved = append(unresolved, mention)
+		}
+	}
+
+	return strings.Join(codes, "\n---------\n\n"), unresolved
+}
+
+// GetMentionCandidates returns the files, directories ending with '/' and symbols that can be mentioned with '@'
+func (analyzer *CodeAnalyzer) GetMentionCandidates(fullContext *models.FullContextData) []string {
+	if fullContext == nil {
+		return nil
+	}
+
+	seen := make(map[string]bool)
+	var candidates []string
+	add := func(candidate string) {
+		if candidate == "" || seen[candidate] {
+			return
+		}
+		seen[candidate] = true
+		candidates = append(candidates, candidate)
+	}
+
+	for _, fileData := range fullContext.FileData {
+		add(fileData.RelativePath)
+		for dir := path.Dir(fileData.RelativePath); dir != "." && dir != "/"; dir = path.Dir(dir) {
+			add(dir + "/")
+		}
+		for _, symbol := range fileData.Symbols {
+			add(symbol)
+		}
+	}
+
+	sort.Strings(candidates)
+	return candidates
+}
+
 func (analyzer *CodeAnalyzer) ExtractCodeChanges(diff string) []models.CodeChange {
 	filePathPattern := regexp.MustCompile("(?i)(?:\\d+\\.\\s*|File:\\s*)[`']?([^\\s*`']+?\\.[a-zA-Z0-9]+)[`']?\\b")
 
@@ -341,6 +505,8 @@ func (analyzer *CodeAnalyzer) ExtractCodeChanges(diff string) []models.CodeChang
 }
 
 func (analyzer *CodeAnalyzer) ApplyChanges(relativePath, diff string) error {
+	analyzer.addFocusFile(relativePath)
+
 	// Ensure the directory structure exists
 	dir := filepath.Dir(relativePath)
 	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
@@ -348,25 +514,10 @@ func (analyzer *CodeAnalyzer) ApplyChanges(relativePath, diff string) error {
 	}
 
 	// Process the diff content: handle additions and deletions
-	diffLines := strings.Split(diff, "\n")
-	var updatedContent []string
-
-	for _, line := range diffLines {
-		trimmedLine := strings.TrimSpace(line)
-		if strings.HasPrefix(trimmedLine, "-") {
-			// Ignore lines that start with "-", effectively deleting them
-			continue
-		} else if strings.HasPrefix(trimmedLine, "+") {
-			// Add lines that start with "+", but remove the "+" symbol
-			updatedContent = append(updatedContent, strings.ReplaceAll(trimmedLine, "+", " "))
-		} else {
-			// Keep all other lines as they are
-			updatedContent = append(updatedContent, line)
-		}
-	}
+	updatedContent := ResolveChangedContent(diff)
 
 	// Handle deletion if code is empty
-	if strings.TrimSpace(strings.Join(updatedContent, "\n")) == "" {
+	if strings.TrimSpace(updatedContent) == "" {
 		// Check if file exists, then delete if it does
 		if err := os.Remove(relativePath); err != nil {
 			if os.IsNotExist(err) {
@@ -382,7 +533,7 @@ func (analyzer *CodeAnalyzer) ApplyChanges(relativePath, diff string) error {
 		}
 	} else {
 		// Write the updated content to the file
-		if err := ioutil.WriteFile(relativePath, []byte(strings.Join(updatedContent, "\n")), 0644); err != nil {
+		if err := ioutil.WriteFile(relativePath, []byte(updatedContent), 0644); err != nil {
 			return fmt.Errorf("failed to write to file: %w", err)
 		}
 	}
@@ -390,6 +541,29 @@ func (analyzer *CodeAnalyzer) ApplyChanges(relativePath, diff string) error {
 	return nil
 }
 
+// ResolveChangedContent returns the content of a file after a code change, lines starting with "-" are deleted and
+// lines starting with "+" are added.
+func ResolveChangedContent(diff string) string {
+	diffLines := strings.Split(diff, "\n")
+	var updatedContent []string
+
+	for _, line := range diffLines {
+		trimmedLine := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmedLine, "-") {
+			// Ignore lines that start with "-", effectively deleting them
+			continue
+		} else if strings.HasPrefix(trimmedLine, "+") {
+			// Add lines that start with "+", but remove the "+" symbol
+			updatedContent = append(updatedContent, strings.ReplaceAll(trimmedLine, "+", " "))
+		} else {
+			// Keep all other lines as they are
+			updatedContent = append(updatedContent, line)
+		}
+	}
+
+	return strings.Join(updatedContent, "\n")
+}
+
 // removeEmptyDirectoryIfNeeded checks if a directory is empty, and if so, deletes it
 func removeEmptyDirectoryIfNeeded(dir string) error {
 	// Check if the directory is empty
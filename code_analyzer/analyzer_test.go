@@ -3,8 +3,10 @@ package code_analyzer
 import (
 	"fmt"
 	"github.com/meysamhadeli/codai/code_analyzer/contracts"
+	"github.com/meysamhadeli/codai/code_analyzer/models"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"testing"
 
@@ -54,7 +56,22 @@ func TestRunInSequence(t *testing.T) {
 	t.Run("TestGeneratePrompt_ActualImplementation", TestGeneratePrompt_ActualImplementation)
 	t.Run("TestNewCodeAnalyzer", TestNewCodeAnalyzer)
 	t.Run("TestGetProjectFiles", TestGetProjectFiles)
+	t.Run("TestGetProjectFilesReportsExcludedFiles", TestGetProjectFilesReportsExcludedFiles)
+	t.Run("TestGetProjectFilesWithGitignoreSemantics", TestGetProjectFilesWithGitignoreSemantics)
+	t.Run("TestGetProjectFilesWithIncludePatterns", TestGetProjectFilesWithIncludePatterns)
+	t.Run("TestGetProjectFilesSkipsGeneratedAndBinaryFiles", TestGetProjectFilesSkipsGeneratedAndBinaryFiles)
+	t.Run("TestGetProjectFilesStopsAtMaxFiles", TestGetProjectFilesStopsAtMaxFiles)
+	t.Run("TestGetProjectFilesReportsProgressInWalkOrder", TestGetProjectFilesReportsProgressInWalkOrder)
+	t.Run("TestGetProjectFilesWithScope", TestGetProjectFilesWithScope)
+	t.Run("TestDetectWorkspaces", TestDetectWorkspaces)
 	t.Run("TestProcessFileWithSupportedLanguageReturnTreeSitterResult", TestProcessFileWithSupportedLanguageReturnTreeSitterResult)
+	t.Run("TestProcessFileWithMoreLanguagesReturnTreeSitterResult", TestProcessFileWithMoreLanguagesReturnTreeSitterResult)
+	t.Run("TestProcessFileReturnSignaturesInSourceOrder", TestProcessFileReturnSignaturesInSourceOrder)
+	t.Run("TestProcessFileWithExportedOnly", TestProcessFileWithExportedOnly)
+	t.Run("TestProcessFileWithUserQueries", TestProcessFileWithUserQueries)
+	t.Run("TestParseQueryFile", TestParseQueryFile)
+	t.Run("TestBuildRepoMap", TestBuildRepoMap)
+	t.Run("TestRepoMapFillsTokenBudget", TestRepoMapFillsTokenBudget)
 	t.Run("TestApplyChanges_NewFile", TestApplyChanges_NewFile)
 	t.Run("TestApplyChanges_ModifyFile", TestApplyChanges_ModifyFile)
 	t.Run("TestApplyChanges_DeletedFile", TestApplyChanges_DeletedFile)
@@ -78,6 +95,8 @@ func TestRunInSequence(t *testing.T) {
 	t.Run("TestExtractCodeChangesWithMultipleCodeBlocksSameFile", TestExtractCodeChangesWithMultipleCodeBlocksSameFile)
 	t.Run("TestTryGetInCompletedCodeBlock", TestTryGetInCompletedCodeBlock)
 	t.Run("TestTryGetInCompletedCodeBlockWithAdditionalCharacters", TestTryGetInCompletedCodeBlockWithAdditionalsCharacters)
+	t.Run("TestResolveMentions", TestResolveMentions)
+	t.Run("TestGeneratePromptWithInstructionsAndTemplate", TestGeneratePromptWithInstructionsAndTemplate)
 }
 
 func TestGeneratePrompt(t *testing.T) {
@@ -94,10 +113,16 @@ func TestGeneratePrompt(t *testing.T) {
 	// Assert that the outputs contain the expected mocked strings
 	assert.Contains(t, finalPrompt, "code1")
 	assert.Contains(t, finalPrompt, "code2")
-	assert.Contains(t, finalPrompt, "prev1")
-	assert.Contains(t, finalPrompt, "prev2")
-	assert.Contains(t, finalPrompt, "Requested context")
+	assert.Contains(t, userInputPrompt, "prev1")
+	assert.Contains(t, userInputPrompt, "prev2")
+	assert.Contains(t, userInputPrompt, "Requested context")
 	assert.Contains(t, userInputPrompt, "User request")
+
+	// The prompt is a stable prefix for the prompt caching of providers, the parts of each turn follow it
+	nextPrompt, _ := analyzer.GeneratePrompt(codes, append(history, "prev3"), "Next request", "")
+	assert.Equal(t, finalPrompt, nextPrompt)
+	assert.NotContains(t, finalPrompt, "prev1")
+	assert.Less(t, strings.Index(userInputPrompt, "prev2"), strings.Index(userInputPrompt, "User request"))
 }
 
 func TestGeneratePrompt_ActualImplementation(t *testing.T) {
@@ -143,7 +168,304 @@ func TestGetProjectFiles(t *testing.T) {
 	for _, file := range fullContext.FileData {
 		assert.NotEmpty(t, file.RelativePath)
 		assert.Equal(t, "test.go", filepath.Base(file.RelativePath))
+		assert.Equal(t, []string{"main"}, file.Symbols)
+	}
+}
+
+func TestGetProjectFilesReportsExcludedFiles(t *testing.T) {
+	setup(t)
+
+	assert.NoError(t, os.MkdirAll(filepath.Join(relativePathTestDir, "node_modules", "lib"), 0755))
+	assert.NoError(t, os.WriteFile(filepath.Join(relativePathTestDir, "node_modules", "lib", "index.js"), []byte("module.exports = {}"), 0644))
+	assert.NoError(t, os.WriteFile(filepath.Join(relativePathTestDir, "main.go"), []byte("package main\nfunc main() {}"), 0644))
+	assert.NoError(t, os.WriteFile(filepath.Join(relativePathTestDir, "secret.go"), []byte("package main"), 0644))
+	assert.NoError(t, os.WriteFile(filepath.Join(relativePathTestDir, "large.txt"), []byte(strings.Repeat("a", 101*1024)), 0644))
+	assert.NoError(t, os.WriteFile(filepath.Join(relativePathTestDir, "image.dat"), []byte{0x89, 0x00, 0x01, 0x02}, 0644))
+	assert.NoError(t, os.WriteFile(filepath.Join(relativePathTestDir, ".codai-gitignore"), []byte("secret.go\n"), 0644))
+
+	fullContext, err := analyzer.GetProjectFiles(relativePathTestDir)
+	assert.NoError(t, err)
+
+	var paths []string
+	for _, fileData := range fullContext.FileData {
+		paths = append(paths, fileData.RelativePath)
+	}
+	assert.Equal(t, []string{"main.go"}, paths)
+
+	assert.Equal(t, []models.ExcludedFile{
+		{RelativePath: ".codai-gitignore", Reason: models.ExcludedByIgnoreRule},
+		{RelativePath: "image.dat", Reason: models.ExcludedBinary},
+		{RelativePath: "large.txt", Reason: models.ExcludedTooLarge},
+		{RelativePath: "node_modules/", Reason: models.ExcludedByIgnoreRule},
+		{RelativePath: "secret.go", Reason: models.ExcludedByGitignore},
+	}, fullContext.ExcludedFiles)
+}
+
+func TestGetProjectFilesWithGitignoreSemantics(t *testing.T) {
+	setup(t)
+
+	files := map[string]string{
+		".gitignore":                "*.txt\n!keep.txt\n/generated\ndocs/**/draft.md\nconfig.json\n",
+		"keep.txt":                  "kept by negation",
+		"notes.txt":                 "ignored",
+		"object.go":                 "package main",
+		"binding.py":                "def bind(): pass",
+		"config.json":               "{}",
+		"generated/api.go":          "package generated",
+		"docs/guide/draft.md":       "ignored",
+		"docs/guide/intro.md":       "kept",
+		"sub/.codai-gitignore":      "!config.json\nlocal.go\n",
+		"sub/config.json":           "{}",
+		"sub/local.go":              "package sub",
+		"sub/generated/models.go":   "package generated",
+		"sub/service_test.go":       "package sub",
+		"sub/node_modules/index.js": "module.exports = {}",
+		".codai/commands/review.md": "Review the changes",
 	}
+	for path, content := range files {
+		assert.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(relativePathTestDir, path)), 0755))
+		assert.NoError(t, os.WriteFile(filepath.Join(relativePathTestDir, path), []byte(content), 0644))
+	}
+
+	analyzer.ConfigureContext(&models.ContextConfig{Exclude: []string{"*_test.go"}})
+
+	fullContext, err := analyzer.GetProjectFiles(relativePathTestDir)
+	assert.NoError(t, err)
+
+	var paths []string
+	for _, fileData := range fullContext.FileData {
+		paths = append(paths, fileData.RelativePath)
+	}
+	assert.Equal(t, []string{"binding.py", "docs/guide/intro.md", "keep.txt", "object.go", "sub/config.json", "sub/generated/models.go"}, paths)
+
+	assert.Equal(t, []models.ExcludedFile{
+		{RelativePath: ".codai/", Reason: models.ExcludedByIgnoreRule},
+		{RelativePath: ".gitignore", Reason: models.ExcludedByIgnoreRule},
+		{RelativePath: "config.json", Reason: models.ExcludedByGitignore},
+		{RelativePath: "docs/guide/draft.md", Reason: models.ExcludedByGitignore},
+		{RelativePath: "generated/", Reason: models.ExcludedByGitignore},
+		{RelativePath: "notes.txt", Reason: models.ExcludedByGitignore},
+		{RelativePath: "sub/.codai-gitignore", Reason: models.ExcludedByIgnoreRule},
+		{RelativePath: "sub/local.go", Reason: models.ExcludedByGitignore},
+		{RelativePath: "sub/node_modules/", Reason: models.ExcludedByIgnoreRule},
+		{RelativePath: "sub/service_test.go", Reason: models.ExcludedByConfig},
+	}, fullContext.ExcludedFiles)
+}
+
+func TestGetProjectFilesWithIncludePatterns(t *testing.T) {
+	setup(t)
+
+	for _, path := range []string{"main.go", "services/billing/invoice.go", "services/billing/README.md", "services/users/user.go"} {
+		assert.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(relativePathTestDir, path)), 0755))
+		assert.NoError(t, os.WriteFile(filepath.Join(relativePathTestDir, path), []byte("package main"), 0644))
+	}
+
+	analyzer.ConfigureContext(&models.ContextConfig{Include: []string{"services/billing/", "main.go"}, Exclude: []string{"*.md"}})
+
+	fullContext, err := analyzer.GetProjectFiles(relativePathTestDir)
+	assert.NoError(t, err)
+
+	var paths []string
+	for _, fileData := range fullContext.FileData {
+		paths = append(paths, fileData.RelativePath)
+	}
+	assert.Equal(t, []string{"main.go", "services/billing/invoice.go"}, paths)
+
+	assert.Equal(t, []models.ExcludedFile{
+		{RelativePath: "services/billing/README.md", Reason: models.ExcludedByConfig},
+		{RelativePath: "services/users/user.go", Reason: models.ExcludedNotIncluded},
+	}, fullContext.ExcludedFiles)
+}
+
+func TestGetProjectFilesSkipsGeneratedAndBinaryFiles(t *testing.T) {
+	setup(t)
+
+	files := map[string]string{
+		"a.go":              "package main",
+		"api.pb.go":         "// Code generated by protoc-gen-go. DO NOT EDIT.\n// source: api.proto\n\npackage api",
+		"app.min.js":        "var a=1;",
+		"b.go":              "package main",
+		"big.go":            "package main\n" + strings.Repeat("// comment\n", 500),
+		"bundle.js":         "var a=1;" + strings.Repeat("function f(){return 1};", 100),
+		"c.go":              "package main",
+		"image.dat":         "\x89PNG\x00\x01",
+		"latin1.txt":        "caf\xe9",
+		"mock_store.go":     "// Code generated by MockGen. DO NOT EDIT.\npackage mocks",
+		"package-lock.json": "{}",
+		"schema.ts":         "/**\n * @generated by the schema compiler\n */\nexport type A = {}",
+	}
+	for path, content := range files {
+		assert.NoError(t, os.WriteFile(filepath.Join(relativePathTestDir, path), []byte(content), 0644))
+	}
+
+	analyzer.ConfigureContext(&models.ContextConfig{MaxFileSizeKB: 4})
+
+	fullContext, err := analyzer.GetProjectFiles(relativePathTestDir)
+	assert.NoError(t, err)
+
+	var paths []string
+	for _, fileData := range fullContext.FileData {
+		paths = append(paths, fileData.RelativePath)
+	}
+	assert.Equal(t, []string{"a.go", "b.go", "c.go"}, paths)
+
+	assert.Equal(t, []models.ExcludedFile{
+		{RelativePath: "api.pb.go", Reason: models.ExcludedGenerated},
+		{RelativePath: "app.min.js", Reason: models.ExcludedMinified},
+		{RelativePath: "big.go", Reason: models.ExcludedTooLarge},
+		{RelativePath: "bundle.js", Reason: models.ExcludedMinified},
+		{RelativePath: "image.dat", Reason: models.ExcludedBinary},
+		{RelativePath: "latin1.txt", Reason: models.ExcludedNotUTF8},
+		{RelativePath: "mock_store.go", Reason: models.ExcludedGenerated},
+		{RelativePath: "package-lock.json", Reason: models.ExcludedLockfile},
+		{RelativePath: "schema.ts", Reason: models.ExcludedGenerated},
+	}, fullContext.ExcludedFiles)
+}
+
+func TestGetProjectFilesStopsAtMaxFiles(t *testing.T) {
+	setup(t)
+
+	for _, path := range []string{"a.go", "b.go", "c.go", "d.go"} {
+		assert.NoError(t, os.WriteFile(filepath.Join(relativePathTestDir, path), []byte("package main"), 0644))
+	}
+
+	var progress [][2]int
+	analyzer.SetProgressHandler(func(processed int, total int) {
+		progress = append(progress, [2]int{processed, total})
+	})
+	analyzer.ConfigureContext(&models.ContextConfig{MaxFiles: 2})
+
+	fullContext, err := analyzer.GetProjectFiles(relativePathTestDir)
+	assert.NoError(t, err)
+
+	// The files above the limit are not read, and the truncation is reported
+	assert.Len(t, fullContext.FileData, 2)
+	assert.Equal(t, [][2]int{{2, 2}}, progress)
+	assert.Equal(t, []models.ExcludedFile{
+		{RelativePath: "c.go", Reason: models.ExcludedTooMany},
+		{RelativePath: "d.go", Reason: models.ExcludedTooMany},
+	}, fullContext.ExcludedFiles)
+	assert.Contains(t, analyzer.Warnings(), "the context is truncated to the first 2 files by context.max_files, 2 files are skipped")
+}
+
+func TestGetProjectFilesReportsProgressInWalkOrder(t *testing.T) {
+	setup(t)
+
+	var expectedPaths []string
+	for i := 0; i < 120; i++ {
+		path := fmt.Sprintf("pkg%d/file%03d.go", i%3, i)
+		assert.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(relativePathTestDir, path)), 0755))
+		assert.NoError(t, os.WriteFile(filepath.Join(relativePathTestDir, path), []byte(fmt.Sprintf("package pkg\nfunc F%d() {}", i)), 0644))
+		expectedPaths = append(expectedPaths, path)
+	}
+	sort.Strings(expectedPaths)
+
+	var progress [][2]int
+	analyzer.SetProgressHandler(func(processed int, total int) {
+		progress = append(progress, [2]int{processed, total})
+	})
+
+	for run := 0; run < 2; run++ {
+		progress = nil
+
+		fullContext, err := analyzer.GetProjectFiles(relativePathTestDir)
+		assert.NoError(t, err)
+
+		var paths []string
+		for i, fileData := range fullContext.FileData {
+			paths = append(paths, fileData.RelativePath)
+			assert.Contains(t, fullContext.RawCodes[i], "**File: "+fileData.RelativePath+"**")
+		}
+		assert.Equal(t, expectedPaths, paths)
+		assert.Equal(t, [][2]int{{50, 120}, {100, 120}, {120, 120}}, progress)
+	}
+}
+
+func TestGetProjectFilesWithScope(t *testing.T) {
+	setup(t)
+
+	for _, path := range []string{"main.go", "services/billing/invoice.go", "services/users/user.go", "libs/common/log.go", "libs/auth/token.go"} {
+		assert.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(relativePathTestDir, path)), 0755))
+		assert.NoError(t, os.WriteFile(filepath.Join(relativePathTestDir, path), []byte("package main"), 0644))
+	}
+
+	analyzer.ConfigureContext(&models.ContextConfig{Scopes: map[string][]string{"shared": {"libs/common"}}})
+	assert.NoError(t, analyzer.SetScope([]string{"services/billing/", "shared"}))
+	assert.Equal(t, []string{"libs/common", "services/billing"}, analyzer.Scope())
+
+	fullContext, err := analyzer.GetProjectFiles(relativePathTestDir)
+	assert.NoError(t, err)
+
+	var paths []string
+	for _, fileData := range fullContext.FileData {
+		paths = append(paths, fileData.RelativePath)
+	}
+	assert.Equal(t, []string{"libs/common/log.go", "services/billing/invoice.go"}, paths)
+
+	assert.Equal(t, []models.ExcludedFile{
+		{RelativePath: "libs/auth/", Reason: models.ExcludedOutOfScope},
+		{RelativePath: "main.go", Reason: models.ExcludedOutOfScope},
+		{RelativePath: "services/users/", Reason: models.ExcludedOutOfScope},
+	}, fullContext.ExcludedFiles)
+
+	// An unknown path keeps the scope, '.' widens it to the whole project
+	assert.Error(t, analyzer.SetScope([]string{"services/missing"}))
+	assert.Equal(t, []string{"libs/common", "services/billing"}, analyzer.Scope())
+	assert.Error(t, analyzer.SetScope([]string{"../outside"}))
+
+	assert.NoError(t, analyzer.SetScope([]string{"."}))
+	assert.Empty(t, analyzer.Scope())
+
+	fullContext, err = analyzer.GetProjectFiles(relativePathTestDir)
+	assert.NoError(t, err)
+	assert.Len(t, fullContext.FileData, 5)
+}
+
+func TestDetectWorkspaces(t *testing.T) {
+	setup(t)
+
+	files := map[string]string{
+		"go.mod":                           "module example.com/monorepo",
+		"services/billing/go.mod":          "module example.com/billing",
+		"services/billing/vendor/x/go.mod": "module x",
+		"node_modules/lib/go.mod":          "module lib",
+		"package.json":                     `{"name": "monorepo", "workspaces": ["packages/*", "apps/web"]}`,
+		"packages/ui/package.json":         `{"name": "ui"}`,
+		"packages/docs/README.md":          "no package.json",
+		"apps/web/package.json":            `{"name": "web"}`,
+		"pom.xml":                          "<project><modules><module>java/core</module><module>java/api</module></modules></project>",
+		"java/core/pom.xml":                "<project><modules><module>storage</module></modules></project>",
+		"java/core/storage/pom.xml":        "<project></project>",
+		"java/api/pom.xml":                 "<project></project>",
+	}
+	for path, content := range files {
+		assert.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(relativePathTestDir, path)), 0755))
+		assert.NoError(t, os.WriteFile(filepath.Join(relativePathTestDir, path), []byte(content), 0644))
+	}
+
+	assert.Equal(t, []models.Workspace{
+		{Path: "apps/web", Kind: models.WorkspaceNpm},
+		{Path: "java/api", Kind: models.WorkspaceMavenModule},
+		{Path: "java/core", Kind: models.WorkspaceMavenModule},
+		{Path: "java/core/storage", Kind: models.WorkspaceMavenModule},
+		{Path: "packages/ui", Kind: models.WorkspaceNpm},
+		{Path: "services/billing", Kind: models.WorkspaceGoModule},
+	}, DetectWorkspaces(relativePathTestDir))
+
+	// The workspaces of npm can also be an object with the packages
+	assert.NoError(t, os.WriteFile(filepath.Join(relativePathTestDir, "package.json"), []byte(`{"workspaces": {"packages": ["apps/*"]}}`), 0644))
+	assert.Equal(t, []models.Workspace{{Path: "apps/web", Kind: models.WorkspaceNpm}}, detectNpmWorkspaces(relativePathTestDir))
+
+	// A '**' glob matches the nested packages, a '!' glob excludes some of them
+	for _, path := range []string{"libs/auth/package.json", "libs/data/sql/package.json", "libs/data/legacy/package.json", "libs/node_modules/x/package.json"} {
+		assert.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(relativePathTestDir, path)), 0755))
+		assert.NoError(t, os.WriteFile(filepath.Join(relativePathTestDir, path), []byte("{}"), 0644))
+	}
+	assert.NoError(t, os.WriteFile(filepath.Join(relativePathTestDir, "package.json"), []byte(`{"workspaces": ["./libs/**", "!libs/data/legacy"]}`), 0644))
+	assert.Equal(t, []models.Workspace{
+		{Path: "libs/auth", Kind: models.WorkspaceNpm},
+		{Path: "libs/data/sql", Kind: models.WorkspaceNpm},
+	}, detectNpmWorkspaces(relativePathTestDir))
 }
 
 // Test for ProcessFile
@@ -153,10 +475,275 @@ func TestProcessFileWithSupportedLanguageReturnTreeSitterResult(t *testing.T) {
 
 	result := analyzer.ProcessFile("test.cs", content)
 
-	assert.Contains(t, result, "class: Test")
+	assert.Contains(t, result, "class: class Test")
 	assert.NotEmpty(t, result)
 }
 
+func TestProcessFileWithMoreLanguagesReturnTreeSitterResult(t *testing.T) {
+	setup(t)
+
+	tests := []struct {
+		filePath string
+		content  string
+		expected []string
+	}{
+		{"lib.rs", "mod net;\nstruct Point { x: i32 }\nenum Shape { Circle }\ntrait Area {}\nimpl Area for Point {}\nfn area() {}", []string{"module: net", "struct: struct Point { x: i32 }", "enum: enum Shape { Circle }", "trait: trait Area", "impl: impl Area for Point", "function: fn area()"}},
+		{"main.c", "struct point { int x; };\ntypedef int size;\nint add(int a, int b) { return a + b; }\nint sub(int a, int b);", []string{"struct: struct point { int x; }", "typedef: typedef int size", "function: int add(int a, int b)", "prototype: int sub(int a, int b)"}},
+		{"shape.cpp", "namespace geo { class Shape { public: int area(); }; }\nint Shape::area() { return 0; }", []string{"namespace: geo", "class: class Shape", "method: int area()", "function: int Shape::area()"}},
+		{"user.rb", "module Accounts\n  class User\n    def name\n    end\n    def self.find\n    end\n  end\nend", []string{"module: Accounts", "class: class User", "method: def name", "singleton_method: def self.find"}},
+		{"user.php", "<?php\nnamespace App;\ninterface HasName {}\nclass User { public function name() {} }\nfunction helper() {}", []string{"namespace: App", "interface: interface HasName {}", "class: class User", "method: public function name()", "function: function helper()"}},
+		{"User.kt", "package app\nclass User {\n  fun name(): String = \"\"\n}\nobject Registry", []string{"package: app", "class: class User", "function: fun name(): String", "object: object Registry"}},
+		{"User.swift", "protocol Named {}\nclass User {\n  func name() {}\n}", []string{"protocol: protocol Named {}", "class: class User", "function: func name()"}},
+		{"User.scala", "package app\ntrait Named\nclass User { def name(): String = \"\" }\nobject Registry", []string{"package: app", "trait: trait Named", "class: class User", "function: def name(): String", "object: object Registry"}},
+		{"build.sh", "build() {\n  echo build\n}", []string{"function: build()"}},
+		{"init.lua", "function M.setup()\nend\nlocal function helper()\nend", []string{"function: function M.setup()", "local_function: local function helper()"}},
+		{"user.ex", "defmodule App.User do\n  def name(user), do: user.name\n  defp secret, do: nil\nend", []string{"module: App.User", "function: def name(user), do: user.name", "function: defp secret, do: nil"}},
+		{"App.tsx", "interface Props {}\nfunction App() { return <div />; }", []string{"interface: interface Props {}", "function: function App()"}},
+	}
+
+	for _, test := range tests {
+		result := analyzer.ProcessFile(test.filePath, []byte(test.content))
+
+		for _, expected := range test.expected {
+			assert.Contains(t, result, expected, test.filePath)
+		}
+	}
+}
+
+func TestProcessFileReturnSignaturesInSourceOrder(t *testing.T) {
+	setup(t)
+	content := []byte(`package store
+
+// Store keeps the values.
+type Store interface {
+	Get(key string) (string, error)
+}
+
+// Server serves the store,
+// on its address.
+type Server struct {
+	addr  string // The address
+	store Store
+}
+
+// Start starts the server.
+func (s *Server) Start(ctx context.Context, port int) error {
+	return nil
+}
+
+func newServer(addr string) *Server {
+	return &Server{addr: addr}
+}
+`)
+
+	expected := []string{
+		"package: store",
+		"// Store keeps the values.",
+		"interface: type Store interface { Get(key string) (string, error) }",
+		"// Server serves the store, on its address.",
+		"struct: type Server struct { addr string; store Store }",
+		"// Start starts the server.",
+		"method: func (s *Server) Start(ctx context.Context, port int) error",
+		"function: func newServer(addr string) *Server",
+	}
+
+	// The summary is in source order, the same on every run
+	for i := 0; i < 5; i++ {
+		assert.Equal(t, expected, analyzer.ProcessFile("store.go", content))
+	}
+
+	result := analyzer.ProcessFile("user.py", []byte("class User(Base):\n    def name(self, prefix: str) -> str:\n        \"\"\"Returns the name of user.\"\"\"\n        return prefix\n"))
+	assert.Equal(t, []string{"class: class User(Base)", "# Returns the name of user.", "function: def name(self, prefix: str) -> str"}, result)
+
+	// Doc comments read with the comment marker of their language
+	result = analyzer.ProcessFile("users.py", []byte("# Finds a user by id.\ndef find(id):\n    return None\n"))
+	assert.Equal(t, []string{"# Finds a user by id.", "function: def find(id)"}, result)
+}
+
+func TestProcessFileWithExportedOnly(t *testing.T) {
+	setup(t)
+	t.Cleanup(func() { analyzer.ConfigureContext(nil) })
+
+	analyzer.ConfigureContext(&models.ContextConfig{ExportedOnly: true})
+
+	result := analyzer.ProcessFile("server.go", []byte("package server\ntype Server struct{}\ntype store struct{}\nfunc (s *Server) Start() {}\nfunc (s *Server) stop() {}\nfunc New() *Server { return nil }"))
+	assert.Equal(t, []string{"package: server", "struct: type Server struct{}", "method: func (s *Server) Start()", "function: func New() *Server"}, result)
+
+	result = analyzer.ProcessFile("lib.rs", []byte("pub fn open() {}\nfn close() {}"))
+	assert.Equal(t, []string{"function: pub fn open()"}, result)
+
+	result = analyzer.ProcessFile("User.java", []byte("public class User { public String name() { return \"\"; } private void secret() {} }"))
+	assert.Equal(t, []string{"class: public class User", "method: public String name()"}, result)
+
+	result = analyzer.ProcessFile("user.ts", []byte("export class User { name(): string { return '' } private secret(): void {} }\nfunction helper() {}"))
+	assert.Equal(t, []string{"class: class User", "method: name(): string"}, result)
+
+	result = analyzer.ProcessFile("user.py", []byte("def name():\n    pass\ndef _secret():\n    pass\n"))
+	assert.Equal(t, []string{"function: def name()"}, result)
+}
+
+func TestProcessFileWithUserQueries(t *testing.T) {
+	setup(t)
+	homeDir := t.TempDir()
+	t.Setenv("HOME", homeDir)
+
+	userQueriesDir := filepath.Join(homeDir, ".config", "codai", "queries")
+	projectQueriesDir := filepath.Join(relativePathTestDir, ".codai", "queries")
+	assert.NoError(t, os.MkdirAll(userQueriesDir, 0755))
+	assert.NoError(t, os.MkdirAll(projectQueriesDir, 0755))
+
+	// The user queries add a tag, the project queries override a tag of them and of the embedded queries
+	assert.NoError(t, os.WriteFile(filepath.Join(userQueriesDir, "go.scm"), []byte(`{"const": "(const_spec name: (identifier) @name)", "function": "(function_declaration) @broken"}`), 0644))
+	assert.NoError(t, os.WriteFile(filepath.Join(projectQueriesDir, "go.scm"), []byte(`{"function": "(function_declaration name: (identifier) @name)"}`), 0644))
+
+	result := analyzer.ProcessFile("main.go", []byte("package main\nconst limit = 10\nfunc main() {}"))
+	assert.Equal(t, []string{"package: main", "const: limit", "function: main"}, result)
+
+	// A language without embedded queries is summarized with the queries of the project
+	assert.NoError(t, os.WriteFile(filepath.Join(projectQueriesDir, "protobuf.scm"), []byte(`{"message": "(message (message_name) @name) @definition"}`), 0644))
+
+	result = analyzer.ProcessFile("user.proto", []byte("syntax = \"proto3\";\nmessage User {\n  string name = 1;\n}"))
+	assert.Equal(t, []string{"message: message User"}, result)
+
+	// The language of a file can be forced, whatever its extension
+	result = analyzer.ProcessFileWithLanguage("main.txt", "go", []byte("package main\nfunc main() {}"))
+	assert.Equal(t, []string{"package: main", "function: main"}, result)
+
+	// A tree-sitter query file tags its patterns by their '@definition.<tag>' capture, like the tags queries of the grammars
+	assert.NoError(t, os.WriteFile(filepath.Join(projectQueriesDir, "rust.scm"), []byte(`; Constants and statics
+(const_item name: (identifier) @name) @definition.constant
+(static_item name: (identifier) @name) @definition.constant
+
+(
+  (line_comment)* @doc
+  .
+  (function_item name: (identifier) @name) @definition.function
+)
+
+(call_expression function: (identifier) @name) @reference.call
+`), 0644))
+
+	result = analyzer.ProcessFile("lib.rs", []byte("const LIMIT: u32 = 10;\nstatic NAME: &str = \"lib\";\nfn open() { close() }"))
+	assert.Equal(t, []string{"constant: const LIMIT: u32 = 10", "constant: static NAME: &str = \"lib\"", "function: fn open()"}, result)
+
+	// A broken query or query file is skipped with a warning
+	assert.NoError(t, os.WriteFile(filepath.Join(projectQueriesDir, "python.scm"), []byte(`{"class": "(class_definition name: (unknown_node) @name)"}`), 0644))
+	assert.NoError(t, os.WriteFile(filepath.Join(projectQueriesDir, "java.scm"), []byte(`(class_declaration name: (identifier) @name)`), 0644))
+
+	result = analyzer.ProcessFile("user.py", []byte("class User:\n    def name(self):\n        pass\n"))
+	assert.Equal(t, []string{"function: def name(self)"}, result)
+
+	result = analyzer.ProcessFile("User.java", []byte("class User {}"))
+	assert.Equal(t, []string{"class: class User"}, result)
+
+	warnings := analyzer.Warnings()
+	assert.Len(t, warnings, 2)
+	assert.Contains(t, warnings[0], "skipped the query 'class' of python")
+	assert.Contains(t, warnings[1], "failed to parse query file")
+	assert.Contains(t, warnings[1], "no pattern is tagged with a capture like '@definition.function'")
+}
+
+func TestParseQueryFile(t *testing.T) {
+	queries, err := parseQueryFile(`{"function": "(function_declaration name: (identifier) @name) @definition"}`)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"function": "(function_declaration name: (identifier) @name) @definition"}, queries)
+
+	queries, err = parseQueryFile(`; The methods, "(not a pattern)"
+(method_declaration name: (field_identifier) @name) @definition.method
+((type_spec name: (type_identifier) @name (#match? @name "^[A-Z]")) @definition.type)
+[(const_spec name: (identifier) @name) (var_spec name: (identifier) @name)] @definition.value
+(comment)+ @doc
+(call_expression function: (identifier) @name) @reference.call
+(method_elem name: (field_identifier) @name) @definition.method`)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"method": "(method_declaration name: (field_identifier) @name) @definition.method\n(method_elem name: (field_identifier) @name) @definition.method",
+		"type":   `((type_spec name: (type_identifier) @name (#match? @name "^[A-Z]")) @definition.type)`,
+		"value":  "[(const_spec name: (identifier) @name) (var_spec name: (identifier) @name)] @definition.value",
+	}, queries)
+
+	_, err = parseQueryFile(`{"function": `)
+	assert.ErrorContains(t, err, "it must map the tags to their queries")
+}
+
+func TestBuildRepoMap(t *testing.T) {
+	setup(t)
+
+	files := map[string]string{
+		"store/store.go":   "package store\nfunc Open() *Store { return nil }\ntype Store struct{}",
+		"server/server.go": "package server\nfunc Serve() { store.Open() }",
+		"main.go":          "package main\nfunc main() { server.Serve() }",
+		"billing/tax.go":   "package billing\nfunc Tax() float64 { return rate() }",
+		"billing/rate.go":  "package billing\nfunc rate() float64 { return 0.2 }",
+	}
+	for path, content := range files {
+		assert.NoError(t, os.MkdirAll(filepath.Join(relativePathTestDir, filepath.Dir(path)), 0755))
+		assert.NoError(t, os.WriteFile(filepath.Join(relativePathTestDir, path), []byte(content), 0644))
+	}
+
+	fullContext, err := analyzer.GetProjectFiles(relativePathTestDir)
+	assert.NoError(t, err)
+
+	// Relative to the server, the store it uses ranks above the unrelated billing
+	rankedFiles := BuildRepoMap(fullContext.FileData, []string{"server/server.go"})
+	var paths []string
+	for _, rankedFile := range rankedFiles {
+		paths = append(paths, rankedFile.RelativePath)
+	}
+	assert.Equal(t, []string{"server/server.go", "store/store.go", "billing/rate.go", "billing/tax.go", "main.go"}, paths)
+	assert.Equal(t, []string{"store/store.go"}, rankedFiles[0].Uses)
+	assert.Equal(t, []string{"main.go"}, rankedFiles[0].UsedBy)
+
+	// Relative to the tax, the rate it uses ranks first after it
+	rankedFiles = BuildRepoMap(fullContext.FileData, []string{"billing/tax.go"})
+	assert.Equal(t, "billing/tax.go", rankedFiles[0].RelativePath)
+	assert.Equal(t, "billing/rate.go", rankedFiles[1].RelativePath)
+
+	// The map is ranked relative to the whole project, with the files each file uses and is used by
+	codes := analyzer.RepoMap(fullContext)
+	assert.Equal(t, "**File: store/store.go**\n\npackage: store\nfunction: func Open() *Store\nstruct: type Store struct{}\nUsed by: server/server.go", codes[0])
+	finalPrompt, userInputPrompt := analyzer.GeneratePrompt(codes, nil, "User request", "")
+	assert.NotContains(t, userInputPrompt, "most related")
+
+	// The mentioned files keep the map and the stable prefix of the prompt, the files related to them follow it
+	_, unresolved := analyzer.ResolveMentions([]string{"Tax"}, fullContext)
+	assert.Empty(t, unresolved)
+
+	mentionedCodes := analyzer.RepoMap(fullContext)
+	assert.Equal(t, codes, mentionedCodes)
+	mentionedPrompt, userInputPrompt := analyzer.GeneratePrompt(mentionedCodes, nil, "User request", "")
+	assert.Equal(t, finalPrompt, mentionedPrompt)
+	assert.Contains(t, userInputPrompt, "## Here are the files of project most related to the files mentioned or edited in this session\n\nbilling/tax.go, billing/rate.go\n\n")
+	assert.Less(t, strings.Index(userInputPrompt, "billing/tax.go"), strings.Index(userInputPrompt, "User request"))
+}
+
+func TestRepoMapFillsTokenBudget(t *testing.T) {
+	setup(t)
+	t.Cleanup(func() { analyzer.ConfigureContext(nil) })
+
+	codes := []string{
+		"**File: a.go**\n\n" + strings.Repeat("function: func A()\n", 10),
+		"**File: b.go**\n\n" + strings.Repeat("function: func B()\n", 10),
+		"**File: c.go**\n\n" + strings.Repeat("function: func C()\n", 10),
+		"**File: d.go**\n\n" + strings.Repeat("function: func D()\n", 10),
+	}
+
+	analyzer.ConfigureContext(&models.ContextConfig{MapTokens: 120})
+	finalPrompt, _ := analyzer.GeneratePrompt(codes, nil, "User request", "")
+
+	assert.Contains(t, finalPrompt, "**File: a.go**")
+	assert.Contains(t, finalPrompt, "**File: b.go**")
+	assert.NotContains(t, finalPrompt, "func C()")
+	assert.Contains(t, finalPrompt, "The summaries of 2 files of lower rank are omitted, request them in full when needed: c.go, d.go")
+
+	// Without a budget, all the summaries are kept
+	analyzer.ConfigureContext(&models.ContextConfig{MapTokens: 0})
+	finalPrompt, _ = analyzer.GeneratePrompt(codes, nil, "User request", "")
+
+	assert.Contains(t, finalPrompt, "func D()")
+	assert.NotContains(t, finalPrompt, "omitted")
+}
+
 // TestApplyChanges_NewFile tests if ApplyChanges creates a new file when it doesn't exist.
 func TestApplyChanges_NewFile(t *testing.T) {
 	setup(t)
@@ -517,3 +1104,112 @@ func TestTryGetInCompletedCodeBlockWithAdditionalsCharacters(t *testing.T) {
 	assert.Contains(t, requestedContext, "package main\nfunc main() {}")
 	assert.Contains(t, requestedContext, "package test\nfunc test() {}")
 }
+
+// Test for ResolveMentions
+func TestResolveMentions(t *testing.T) {
+	setup(t)
+
+	fullContext := &models.FullContextData{
+		FileData: []models.FileData{
+			{RelativePath: "cmd/root.go", Code: "package cmd\nfunc Execute() {}", TreeSitterCode: "package: cmd\nfunction: func Execute()", Symbols: []string{"cmd", "Execute"}},
+			{RelativePath: "cmd/code.go", Code: "package cmd\nfunc handleCodeCommand() {}", TreeSitterCode: "package: cmd\nfunction: func handleCodeCommand()", Symbols: []string{"cmd", "handleCodeCommand"}},
+			{RelativePath: "main.go", Code: "package main\nfunc main() {}", TreeSitterCode: "package: main\nfunction: func main()", Symbols: []string{"main"}},
+		},
+	}
+
+	requestedContext, unresolved := analyzer.ResolveMentions([]string{"main.go", "cmd/", "Execute", "Missing"}, fullContext)
+
+	assert.Contains(t, requestedContext, "**File: main.go**")
+	assert.Contains(t, requestedContext, "**File: cmd/root.go**")
+	assert.Contains(t, requestedContext, "**File: cmd/code.go**")
+	assert.Equal(t, 1, strings.Count(requestedContext, "**File: cmd/root.go**"))
+	assert.Equal(t, []string{"Missing"}, unresolved)
+}
+
+// Test for GeneratePrompt with project instructions and a custom template
+func TestGeneratePromptWithInstructionsAndTemplate(t *testing.T) {
+	setup(t)
+
+	err := os.WriteFile(filepath.Join(relativePathTestDir, "CODAI.md"), []byte("Use table driven tests."), 0644)
+	assert.NoError(t, err)
+	err = os.WriteFile(filepath.Join(relativePathTestDir, "prompt.tmpl"), []byte("Language: {{.Language}}\n{{.ProjectTree}}"), 0644)
+	assert.NoError(t, err)
+
+	err = analyzer.ConfigurePrompt("CODAI.md", "prompt.tmpl")
+	assert.NoError(t, err)
+
+	codes := []string{"**File: main.go**\n\npackage: main", "**File: cmd/root.go**\n\npackage: cmd", "**File: cmd/code.go**\n\npackage: cmd"}
+
+	finalPrompt, _ := analyzer.GeneratePrompt(codes, nil, "User request", "")
+
+	assert.Contains(t, finalPrompt, "Use table driven tests.")
+	assert.Contains(t, finalPrompt, "Language: go")
+	assert.Contains(t, finalPrompt, "cmd/\n  code.go\n  root.go\nmain.go")
+	assert.NotContains(t, finalPrompt, "You are an AI code assistant")
+
+	// The prompt is the same whatever the history of chats, which only follows it
+	assert.NoError(t, analyzer.ConfigurePrompt("CODAI.md", "prompt.tmpl"))
+	firstPrompt, firstInputPrompt := analyzer.GeneratePrompt(codes, []string{"prev1"}, "User request", "")
+	nextPrompt, nextInputPrompt := analyzer.GeneratePrompt(codes, []string{"prev1", "prev2"}, "Next request", "")
+	assert.Equal(t, firstPrompt, nextPrompt)
+	assert.NotContains(t, firstPrompt, "prev1")
+	assert.Contains(t, firstInputPrompt, "prev1")
+	assert.Contains(t, nextInputPrompt, "prev2")
+
+	// A template with the history is rejected, it would change the cached prompt on every turn
+	err = os.WriteFile(filepath.Join(relativePathTestDir, "history.tmpl"), []byte("{{.DefaultPrompt}}\n{{ .History }}"), 0644)
+	assert.NoError(t, err)
+	assert.ErrorContains(t, analyzer.ConfigurePrompt("", "history.tmpl"), "can't use '{{.History}}'")
+
+	// A missing instructions file is skipped and an invalid template is reported
+	assert.NoError(t, analyzer.ConfigurePrompt("missing.md", ""))
+	err = os.WriteFile(filepath.Join(relativePathTestDir, "invalid.tmpl"), []byte("{{.Unknown}}"), 0644)
+	assert.NoError(t, err)
+	assert.Error(t, analyzer.ConfigurePrompt("", "invalid.tmpl"))
+}
+
+// writeBenchmarkProject writes a project of Go and TypeScript files with functions calling each other.
+func writeBenchmarkProject(b *testing.B, files int) string {
+	rootDir := b.TempDir()
+	for i := 0; i < files; i++ {
+		var path, content string
+		if i%2 == 0 {
+			path = filepath.Join(rootDir, fmt.Sprintf("service%d", i%10), fmt.Sprintf("handler%d.go", i))
+			content = fmt.Sprintf("package service\n\n// Handler%d handles a request\nfunc Handler%d(name string) error {\n\treturn Handler%d(name)\n}\n\ntype Store%d struct {\n\tName string\n}\n", i, i, (i+2)%files, i)
+		} else {
+			path = filepath.Join(rootDir, fmt.Sprintf("web%d", i%10), fmt.Sprintf("component%d.ts", i))
+			content = fmt.Sprintf("export class Component%d {\n  render(): string {\n    return 'component';\n  }\n}\n\nexport function create%d(): Component%d {\n  return new Component%d();\n}\n", i, i, i, i)
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			b.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			b.Fatal(err)
+		}
+	}
+	return rootDir
+}
+
+// Benchmark for GetProjectFiles, scanning a project with a pool of workers
+func BenchmarkGetProjectFiles(b *testing.B) {
+	rootDir := writeBenchmarkProject(b, 1000)
+	benchmarkAnalyzer := NewCodeAnalyzer(rootDir)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := benchmarkAnalyzer.GetProjectFiles(rootDir); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// Benchmark for ProcessFile, with the queries of the language compiled once
+func BenchmarkProcessFile(b *testing.B) {
+	benchmarkAnalyzer := NewCodeAnalyzer(b.TempDir())
+	sourceCode := []byte("package main\n\n// Server serves the requests\ntype Server struct {\n\taddr string\n}\n\nfunc (s *Server) Start() error {\n\treturn nil\n}\n")
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		benchmarkAnalyzer.ProcessFile("main.go", sourceCode)
+	}
+}
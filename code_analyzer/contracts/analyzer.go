@@ -6,9 +6,20 @@ import (
 
 type ICodeAnalyzer interface {
 	GetProjectFiles(rootDir string) (*models.FullContextData, error)
+	SetProgressHandler(handler func(processed int, total int))
 	ProcessFile(filePath string, sourceCode []byte) []string
+	ProcessFileWithLanguage(filePath string, languageName string, sourceCode []byte) []string
+	Warnings() []string
+	ConfigurePrompt(instructionsFile string, promptTemplateFile string) error
+	ConfigureContext(contextConfig *models.ContextConfig)
+	SetScope(scope []string) error
+	Scope() []string
+	NamedScopes() map[string][]string
+	RepoMap(fullContext *models.FullContextData) []string
 	GeneratePrompt(codes []string, history []string, userInput string, requestedContext string) (string, string)
 	ExtractCodeChanges(text string) []models.CodeChange
 	ApplyChanges(relativePath, code string) error
 	TryGetInCompletedCodeBlocK(relativePaths string) (string, error)
+	ResolveMentions(mentions []string, fullContext *models.FullContextData) (string, []string)
+	GetMentionCandidates(fullContext *models.FullContextData) []string
 }
@@ -0,0 +1,13 @@
+package models
+
+// ContextConfig configures the summary of context of project sent to the AI
+type ContextConfig struct {
+	ExportedOnly  bool                `mapstructure:"exported_only"`    // Keep only the exported elements in the summary of files, e.g., 'func Foo' in Go
+	MapTokens     int                 `mapstructure:"map_tokens"`       // The token budget of the repository map in the prompt, 0 for no limit
+	Include       []string            `mapstructure:"include"`          // The gitignore patterns of the files kept in the context, e.g., 'src/' or '*.go', all files when empty
+	Exclude       []string            `mapstructure:"exclude"`          // The gitignore patterns of the files removed from the context, on top of '.gitignore' and '.codai-gitignore'
+	MaxFileSizeKB int                 `mapstructure:"max_file_size_kb"` // The size in KB above which a file is skipped, 0 for no limit
+	MaxFiles      int                 `mapstructure:"max_files"`        // The number of files read for the context, the next files are skipped, 0 for no limit
+	Scope         []string            `mapstructure:"scope"`            // The directories or named scopes the context is limited to, e.g., 'services/billing', the whole project when empty
+	Scopes        map[string][]string `mapstructure:"scopes"`           // The named scopes with their directories, e.g., 'billing: [services/billing, libs/common]'
+}
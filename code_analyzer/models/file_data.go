@@ -5,9 +5,34 @@ type FileData struct {
 	RelativePath   string
 	Code           string
 	TreeSitterCode string
+	Symbols        []string // The names of the symbols defined in the file, e.g., its functions and types
+	References     []string // The identifiers used in the file, e.g., the functions it calls
+}
+
+// The reasons of the files excluded from the context of project
+const (
+	ExcludedByIgnoreRule = "default ignore rule"
+	ExcludedOutOfScope   = "outside scope"
+	ExcludedByGitignore  = "gitignore"
+	ExcludedByConfig     = "context.exclude"
+	ExcludedNotIncluded  = "not in context.include"
+	ExcludedLockfile     = "lockfile"
+	ExcludedTooLarge     = "larger than context.max_file_size_kb"
+	ExcludedBinary       = "binary"
+	ExcludedNotUTF8      = "not UTF-8 text"
+	ExcludedGenerated    = "generated"
+	ExcludedMinified     = "minified"
+	ExcludedTooMany      = "over context.max_files"
+)
+
+// ExcludedFile holds the path of a file, or of a directory ending with '/', excluded from the context and the reason
+type ExcludedFile struct {
+	RelativePath string `json:"path"`
+	Reason       string `json:"reason"`
 }
 
 type FullContextData struct {
-	FileData []FileData
-	RawCodes []string
+	FileData      []FileData
+	RawCodes      []string
+	ExcludedFiles []ExcludedFile
 }
@@ -0,0 +1,9 @@
+package models
+
+// PromptTemplateData holds the variables of a custom prompt template, the same on every turn so the rendered prompt
+// stays in the prompt cache of providers
+type PromptTemplateData struct {
+	DefaultPrompt string // The built-in template prompt of codai
+	ProjectTree   string // The tree of files in the context of project
+	Language      string // The main language of project, e.g., "go"
+}
@@ -0,0 +1,9 @@
+package models
+
+// RankedFile is a file of the repository map, ranked by its relevance to the files mentioned or edited in the session
+type RankedFile struct {
+	RelativePath string
+	Rank         float64
+	Uses         []string // The files defining the symbols used by this file
+	UsedBy       []string // The files using the symbols defined by this file
+}
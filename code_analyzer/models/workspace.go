@@ -0,0 +1,14 @@
+package models
+
+// The kinds of the workspaces detected in a monorepo
+const (
+	WorkspaceGoModule    = "go module"
+	WorkspaceNpm         = "npm workspace"
+	WorkspaceMavenModule = "maven module"
+)
+
+// Workspace holds the directory of a package of a monorepo, suggested as a scope of the context, and its kind
+type Workspace struct {
+	Path string
+	Kind string
+}
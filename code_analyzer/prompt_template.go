@@ -0,0 +1,135 @@
+package code_analyzer
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/meysamhadeli/codai/code_analyzer/models"
+	"github.com/meysamhadeli/codai/embed_data"
+	"github.com/meysamhadeli/codai/utils"
+)
+
+// filePathRegex captures the relative path of a summarized code, formatted like "**File: path**"
+var filePathRegex = regexp.MustCompile(`^\*\*File: (.+?)\*\*`)
+
+// historyFieldRegex finds the history in a prompt template, it changes on every turn and would defeat the prompt cache
+var historyFieldRegex = regexp.MustCompile(`\.History\b`)
+
+// ConfigurePrompt loads the project instructions appended to every prompt and the template overriding the default
+// template prompt. A missing instructions file is skipped, relative paths are resolved from the root of project.
+func (analyzer *CodeAnalyzer) ConfigurePrompt(instructionsFile string, promptTemplateFile string) error {
+	analyzer.instructions = ""
+	analyzer.promptTemplate = nil
+
+	if instructionsFile != "" {
+		content, err := os.ReadFile(analyzer.resolvePath(instructionsFile))
+		if err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to read instructions file %s: %w", instructionsFile, err)
+		}
+		analyzer.instructions = strings.TrimSpace(string(content))
+	}
+
+	if promptTemplateFile != "" {
+		content, err := os.ReadFile(analyzer.resolvePath(promptTemplateFile))
+		if err != nil {
+			return fmt.Errorf("failed to read prompt template %s: %w", promptTemplateFile, err)
+		}
+
+		if historyFieldRegex.Match(content) {
+			return fmt.Errorf("the prompt template %s can't use '{{.History}}', the history of chats is sent after the cached part of the prompt", promptTemplateFile)
+		}
+
+		promptTemplate, err := template.New(filepath.Base(promptTemplateFile)).Option("missingkey=error").Parse(string(content))
+		if err != nil {
+			return fmt.Errorf("failed to parse prompt template %s: %w", promptTemplateFile, err)
+		}
+
+		if err := promptTemplate.Execute(&strings.Builder{}, models.PromptTemplateData{}); err != nil {
+			return fmt.Errorf("failed to render prompt template %s: %w", promptTemplateFile, err)
+		}
+
+		analyzer.promptTemplate = promptTemplate
+	}
+
+	return nil
+}
+
+// renderPromptTemplate returns the template prompt, rendered from the custom template if there is one
+func (analyzer *CodeAnalyzer) renderPromptTemplate(codes []string) string {
+	defaultPrompt := string(embed_data.SummarizeFullContextPrompt)
+	if analyzer.promptTemplate == nil {
+		return defaultPrompt
+	}
+
+	var paths []string
+	for _, code := range codes {
+		if match := filePathRegex.FindStringSubmatch(code); match != nil {
+			paths = append(paths, match[1])
+		}
+	}
+
+	var builder strings.Builder
+	err := analyzer.promptTemplate.Execute(&builder, models.PromptTemplateData{
+		DefaultPrompt: defaultPrompt,
+		ProjectTree:   BuildProjectTree(paths),
+		Language:      detectMainLanguage(paths),
+	})
+	if err != nil {
+		return defaultPrompt
+	}
+
+	return builder.String()
+}
+
+func (analyzer *CodeAnalyzer) resolvePath(path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(analyzer.Cwd, path)
+}
+
+// BuildProjectTree renders the relative paths as an indented tree, directories end with '/'
+func BuildProjectTree(paths []string) string {
+	sortedPaths := append([]string(nil), paths...)
+	sort.Strings(sortedPaths)
+
+	var lines []string
+	printedDirs := make(map[string]bool)
+	for _, path := range sortedPaths {
+		parts := strings.Split(path, "/")
+		for i := range parts[:len(parts)-1] {
+			dir := strings.Join(parts[:i+1], "/")
+			if !printedDirs[dir] {
+				printedDirs[dir] = true
+				lines = append(lines, strings.Repeat("  ", i)+parts[i]+"/")
+			}
+		}
+		lines = append(lines, strings.Repeat("  ", len(parts)-1)+parts[len(parts)-1])
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// detectMainLanguage returns the supported programming language with the most files
+func detectMainLanguage(paths []string) string {
+	counts := make(map[string]int)
+	mainLanguage := ""
+	for _, path := range paths {
+		// Only the programming languages count, e.g., not the YAML files of a deployment
+		language := utils.GetSupportedLanguage(path)
+		if language == "" || treeSitterLanguages[language].query == nil {
+			continue
+		}
+		counts[language]++
+		if counts[language] > counts[mainLanguage] || (counts[language] == counts[mainLanguage] && language < mainLanguage) {
+			mainLanguage = language
+		}
+	}
+
+	return mainLanguage
+}
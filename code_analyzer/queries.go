@@ -0,0 +1,240 @@
+package code_analyzer
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/meysamhadeli/codai/utils"
+	sitter "github.com/smacker/go-tree-sitter"
+)
+
+// captureRegex matches the captures of a tree-sitter query, e.g., '@name' or '@definition.function'
+var captureRegex = regexp.MustCompile(`@[\w.-]+`)
+
+// compiledQuery is a query of a language compiled with its grammar, shared by the files of the language
+type compiledQuery struct {
+	tag   string
+	query *sitter.Query
+}
+
+// languageQueries holds the grammar of a language and its compiled queries in the order of their tags
+type languageQueries struct {
+	grammar *sitter.Language
+	queries []compiledQuery
+}
+
+// compileQueries returns the grammar and the compiled queries of a language, compiled once for all its files, or nil
+// without a grammar or queries for the language. A query that doesn't compile with the grammar is skipped with a warning.
+func (analyzer *CodeAnalyzer) compileQueries(languageName string) *languageQueries {
+	analyzer.mutex.Lock()
+	defer analyzer.mutex.Unlock()
+
+	if compiled, exists := analyzer.compiledQueries[languageName]; exists {
+		return compiled
+	}
+
+	var compiled *languageQueries
+	language, exists := treeSitterLanguages[languageName]
+	if queries := analyzer.loadQueries(languageName); exists && len(queries) > 0 {
+		compiled = &languageQueries{grammar: language.grammar()}
+
+		// Execute the queries in the order of their tags, so the summary is the same on every run
+		tags := make([]string, 0, len(queries))
+		for tag := range queries {
+			tags = append(tags, tag)
+		}
+		sort.Strings(tags)
+
+		for _, tag := range tags {
+			query, err := sitter.NewQuery([]byte(queries[tag]), compiled.grammar)
+			if err != nil {
+				// Skip the query that doesn't match the grammar of the language
+				analyzer.addWarning(fmt.Sprintf("skipped the query '%s' of %s: %v", tag, languageName, err))
+				continue
+			}
+			compiled.queries = append(compiled.queries, compiledQuery{tag: tag, query: query})
+		}
+	}
+
+	if analyzer.compiledQueries == nil {
+		analyzer.compiledQueries = make(map[string]*languageQueries)
+	}
+	analyzer.compiledQueries[languageName] = compiled
+
+	return compiled
+}
+
+// loadQueries returns the queries of a language tagged by the type of their elements: the embedded queries, extended
+// or overridden tag by tag with the queries of '~/.config/codai/queries/<lang>.scm' and then of the project in
+// '.codai/queries/<lang>.scm', either a JSON object of the tags and their queries like the embedded ones, or the
+// patterns of a tree-sitter query tagged by their '@definition.<tag>' capture. A broken query file is skipped with a
+// warning. The caller holds the mutex.
+func (analyzer *CodeAnalyzer) loadQueries(language string) map[string]string {
+	if queries, exists := analyzer.queries[language]; exists {
+		return queries
+	}
+
+	queries := make(map[string]string)
+	if embeddedQuery := treeSitterLanguages[language].query; embeddedQuery != nil {
+		if err := json.Unmarshal(embeddedQuery, &queries); err != nil {
+			analyzer.addWarning(fmt.Sprintf("failed to parse the embedded queries of %s: %v", language, err))
+		}
+	}
+
+	var dirs []string
+	if configDir, err := utils.GetCodaiConfigDir(); err == nil {
+		dirs = append(dirs, filepath.Join(configDir, "queries"))
+	}
+	dirs = append(dirs, filepath.Join(analyzer.Cwd, ".codai", "queries"))
+
+	for _, dir := range dirs {
+		queryFile := filepath.Join(dir, language+".scm")
+		content, err := os.ReadFile(queryFile)
+		if err != nil {
+			if !os.IsNotExist(err) {
+				analyzer.addWarning(fmt.Sprintf("failed to read query file %s: %v", queryFile, err))
+			}
+			continue
+		}
+
+		userQueries, err := parseQueryFile(string(content))
+		if err != nil {
+			analyzer.addWarning(fmt.Sprintf("failed to parse query file %s: %v", queryFile, err))
+			continue
+		}
+
+		for tag, query := range userQueries {
+			queries[tag] = query
+		}
+	}
+
+	if analyzer.queries == nil {
+		analyzer.queries = make(map[string]map[string]string)
+	}
+	analyzer.queries[language] = queries
+
+	return queries
+}
+
+// parseQueryFile returns the queries of a query file by their tags, from a JSON object like
+// '{"function": "(function_declaration name: (identifier) @name) @definition"}', or from the patterns of a tree-sitter
+// query like '(function_declaration name: (identifier) @name) @definition.function'.
+func parseQueryFile(content string) (map[string]string, error) {
+	queries := make(map[string]string)
+
+	if strings.HasPrefix(strings.TrimSpace(content), "{") {
+		if err := json.Unmarshal([]byte(content), &queries); err != nil {
+			return nil, fmt.Errorf("it must map the tags to their queries, e.g., {\"function\": \"(function_declaration name: (identifier) @name) @definition\"}: %v", err)
+		}
+		return queries, nil
+	}
+
+	for _, pattern := range splitQueryPatterns(content) {
+		tag := ""
+		pattern = captureRegex.ReplaceAllStringFunc(pattern, func(capture string) string {
+			name := capture[1:]
+			switch {
+			case strings.HasPrefix(name, "definition."):
+				if tag == "" {
+					tag = strings.TrimPrefix(name, "definition.")
+				}
+				return capture
+			case name == "name" || strings.HasPrefix(name, "_"):
+				return capture
+			default:
+				// The other captures, e.g., '@doc' of the tags queries of the grammars, only serve the predicates
+				return "@_" + name
+			}
+		})
+
+		// The patterns without a definition, e.g., '@reference.call', don't summarize an element
+		if tag == "" {
+			continue
+		}
+		if queries[tag] != "" {
+			queries[tag] += "\n"
+		}
+		queries[tag] += pattern
+	}
+
+	if len(queries) == 0 {
+		return nil, fmt.Errorf("no pattern is tagged with a capture like '@definition.function', e.g., (function_declaration name: (identifier) @name) @definition.function")
+	}
+
+	return queries, nil
+}
+
+// splitQueryPatterns splits a tree-sitter query into its top-level patterns, with their captures and without comments.
+func splitQueryPatterns(content string) []string {
+	var patterns []string
+	var current strings.Builder
+	depth := 0
+	inString := false
+
+	flush := func() {
+		if pattern := strings.TrimSpace(current.String()); pattern != "" {
+			patterns = append(patterns, pattern)
+		}
+		current.Reset()
+	}
+
+	for i := 0; i < len(content); i++ {
+		c := content[i]
+
+		switch {
+		case inString:
+			if c == '\\' && i+1 < len(content) {
+				current.WriteByte(c)
+				i++
+				c = content[i]
+			} else if c == '"' {
+				inString = false
+			}
+		case c == ';':
+			// A comment runs to the end of the line
+			for i+1 < len(content) && content[i+1] != '\n' {
+				i++
+			}
+			continue
+		case c == '(' || c == '[' || c == '"':
+			// A pattern starts at the top level, after the captures and quantifiers of the previous one
+			if depth == 0 {
+				flush()
+			}
+			if c == '"' {
+				inString = true
+			} else {
+				depth++
+			}
+		case c == ')' || c == ']':
+			depth = max(depth-1, 0)
+		}
+
+		current.WriteByte(c)
+	}
+	flush()
+
+	return patterns
+}
+
+// addWarning records a warning once, e.g., for a query that doesn't compile with the grammar of its language. The
+// caller holds the mutex.
+func (analyzer *CodeAnalyzer) addWarning(warning string) {
+	if !slices.Contains(analyzer.warnings, warning) {
+		analyzer.warnings = append(analyzer.warnings, warning)
+	}
+}
+
+// Warnings returns the warnings of the analysis of files, e.g., the queries skipped because they are broken.
+func (analyzer *CodeAnalyzer) Warnings() []string {
+	analyzer.mutex.Lock()
+	defer analyzer.mutex.Unlock()
+
+	return slices.Clone(analyzer.warnings)
+}
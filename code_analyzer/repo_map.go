@@ -0,0 +1,272 @@
+package code_analyzer
+
+import (
+	"fmt"
+	"math"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/meysamhadeli/codai/code_analyzer/models"
+	"github.com/meysamhadeli/codai/token_management"
+	sitter "github.com/smacker/go-tree-sitter"
+)
+
+const (
+	// rankDamping is the probability to follow a reference instead of jumping back to a mentioned or edited file
+	rankDamping = 0.85
+	// rankIterations caps the iterations of the ranking, it usually converges before
+	rankIterations = 100
+	// maxMapLinks limits the files listed in 'Uses' and 'Used by' of a file of the map
+	maxMapLinks = 10
+	// codesSeparator separates the summaries of files in the prompt
+	codesSeparator = "\n---------\n\n"
+	// maxRelatedFiles limits the files listed as the most related to the mentioned or edited files of the session
+	maxRelatedFiles = 10
+)
+
+// referenceTypes are the leaf nodes referencing a symbol besides the identifiers, e.g., the constants of Ruby
+var referenceTypes = []string{"constant", "name", "alias"}
+
+// referencesOf returns the sorted identifiers of a syntax tree, e.g., the names of the called functions and used types.
+func referencesOf(root *sitter.Node, sourceCode []byte) []string {
+	seen := make(map[string]bool)
+	var references []string
+
+	cursor := sitter.NewTreeCursor(root)
+	defer cursor.Close()
+
+	for {
+		node := cursor.CurrentNode()
+		if node.ChildCount() == 0 && node.IsNamed() && (strings.Contains(node.Type(), "identifier") || slices.Contains(referenceTypes, node.Type())) {
+			if reference := node.Content(sourceCode); len(reference) > 1 && !seen[reference] {
+				seen[reference] = true
+				references = append(references, reference)
+			}
+		}
+
+		if cursor.GoToFirstChild() {
+			continue
+		}
+		for !cursor.GoToNextSibling() {
+			if !cursor.GoToParent() {
+				sort.Strings(references)
+				return references
+			}
+		}
+	}
+}
+
+// BuildRepoMap ranks the files by their references to the symbols of each other, with a PageRank relative to the focus
+// files, e.g., the files mentioned or edited in the session, or relative to all files without them. A reference to a
+// symbol defined in several files, e.g., 'New', weighs less on each of them, and a symbol defined in the file itself is
+// not a reference to the other files.
+func BuildRepoMap(fileData []models.FileData, focusFiles []string) []models.RankedFile {
+	files := make([]models.FileData, len(fileData))
+	copy(files, fileData)
+	sort.Slice(files, func(i, j int) bool { return files[i].RelativePath < files[j].RelativePath })
+
+	index := make(map[string]int, len(files))
+	definers := make(map[string][]int)
+	for i, file := range files {
+		index[file.RelativePath] = i
+		for _, symbol := range file.Symbols {
+			definers[symbol] = append(definers[symbol], i)
+		}
+	}
+
+	// The weights of the references from a file to the files defining the symbols it uses
+	weights := make([]map[int]float64, len(files))
+	totals := make([]float64, len(files))
+	for i, file := range files {
+		weights[i] = make(map[int]float64)
+		for _, reference := range file.References {
+			// A symbol defined in the file itself, e.g., its package, resolves to it
+			if slices.Contains(file.Symbols, reference) {
+				continue
+			}
+			for _, definer := range definers[reference] {
+				if definer != i {
+					weights[i][definer] += 1 / float64(len(definers[reference]))
+					totals[i] += 1 / float64(len(definers[reference]))
+				}
+			}
+		}
+	}
+
+	// The ranking jumps back to the focus files, or to any file without them
+	personalization := make([]float64, len(files))
+	var focusIndexes []int
+	for _, focusFile := range focusFiles {
+		if i, exists := index[focusFile]; exists && !slices.Contains(focusIndexes, i) {
+			focusIndexes = append(focusIndexes, i)
+		}
+	}
+	for i := range personalization {
+		if len(focusIndexes) == 0 {
+			personalization[i] = 1 / float64(len(files))
+		} else if slices.Contains(focusIndexes, i) {
+			personalization[i] = 1 / float64(len(focusIndexes))
+		}
+	}
+
+	ranks := append([]float64(nil), personalization...)
+	for iteration := 0; iteration < rankIterations; iteration++ {
+		next := make([]float64, len(files))
+		dangling := 0.0
+		for i := range files {
+			if totals[i] == 0 {
+				dangling += ranks[i]
+				continue
+			}
+			for definer, weight := range weights[i] {
+				next[definer] += rankDamping * ranks[i] * weight / totals[i]
+			}
+		}
+
+		difference := 0.0
+		for i := range next {
+			next[i] += (1-rankDamping)*personalization[i] + rankDamping*dangling*personalization[i]
+			difference += math.Abs(next[i] - ranks[i])
+		}
+		ranks = next
+
+		if difference < 1e-9 {
+			break
+		}
+	}
+
+	rankedFiles := make([]models.RankedFile, len(files))
+	for i, file := range files {
+		rankedFiles[i] = models.RankedFile{RelativePath: file.RelativePath, Rank: ranks[i]}
+	}
+	for i, file := range files {
+		for definer := range weights[i] {
+			rankedFiles[i].Uses = append(rankedFiles[i].Uses, files[definer].RelativePath)
+			rankedFiles[definer].UsedBy = append(rankedFiles[definer].UsedBy, file.RelativePath)
+		}
+	}
+	for i := range rankedFiles {
+		sort.Strings(rankedFiles[i].Uses)
+		sort.Strings(rankedFiles[i].UsedBy)
+	}
+
+	sort.SliceStable(rankedFiles, func(i, j int) bool {
+		if rankedFiles[i].Rank != rankedFiles[j].Rank {
+			return rankedFiles[i].Rank > rankedFiles[j].Rank
+		}
+		return rankedFiles[i].RelativePath < rankedFiles[j].RelativePath
+	})
+
+	return rankedFiles
+}
+
+// RepoMap returns the summaries of files ranked by their relevance to the whole project, each with the files it uses
+// and the files using it. The map is the same on every turn so the prompt caching of providers keeps it, the files
+// most related to the files mentioned or edited in the session are kept apart for the part of the prompt after it.
+func (analyzer *CodeAnalyzer) RepoMap(fullContext *models.FullContextData) []string {
+	analyzer.relatedFiles = nil
+	if fullContext == nil {
+		return nil
+	}
+
+	summaries := make(map[string]string, len(fullContext.FileData))
+	for _, fileData := range fullContext.FileData {
+		summaries[fileData.RelativePath] = fileData.TreeSitterCode
+	}
+
+	var codes []string
+	for _, rankedFile := range BuildRepoMap(fullContext.FileData, nil) {
+		code := fmt.Sprintf("**File: %s**\n\n%s", rankedFile.RelativePath, summaries[rankedFile.RelativePath])
+		if len(rankedFile.Uses) > 0 {
+			code += "\nUses: " + joinLinks(rankedFile.Uses)
+		}
+		if len(rankedFile.UsedBy) > 0 {
+			code += "\nUsed by: " + joinLinks(rankedFile.UsedBy)
+		}
+		codes = append(codes, code)
+	}
+
+	if len(analyzer.focusFiles) > 0 {
+		for _, rankedFile := range BuildRepoMap(fullContext.FileData, analyzer.focusFiles) {
+			if len(analyzer.relatedFiles) == maxRelatedFiles || rankedFile.Rank == 0 {
+				break
+			}
+			analyzer.relatedFiles = append(analyzer.relatedFiles, rankedFile.RelativePath)
+		}
+	}
+
+	return codes
+}
+
+// joinLinks joins the paths of the linked files, at most maxMapLinks of them.
+func joinLinks(paths []string) string {
+	if len(paths) <= maxMapLinks {
+		return strings.Join(paths, ", ")
+	}
+	return fmt.Sprintf("%s and %d more", strings.Join(paths[:maxMapLinks], ", "), len(paths)-maxMapLinks)
+}
+
+// addFocusFile records a file mentioned or edited in the session, the related files of the prompt are ranked relative to
+// these files.
+func (analyzer *CodeAnalyzer) addFocusFile(path string) {
+	if filepath.IsAbs(path) {
+		if relativePath, err := filepath.Rel(analyzer.Cwd, path); err == nil {
+			path = relativePath
+		}
+	}
+	path = filepath.ToSlash(filepath.Clean(path))
+
+	if !slices.Contains(analyzer.focusFiles, path) {
+		analyzer.focusFiles = append(analyzer.focusFiles, path)
+	}
+}
+
+// fillTokenBudget joins the summaries of files in their order until the token budget of the map is used, then lists
+// the paths of the omitted files while the budget allows it. A budget of 0 keeps all the summaries.
+func (analyzer *CodeAnalyzer) fillTokenBudget(codes []string) string {
+	if analyzer.mapTokens <= 0 {
+		return strings.Join(codes, codesSeparator)
+	}
+
+	usedTokens := 0
+	included := 0
+	for ; included < len(codes); included++ {
+		tokens := token_management.EstimateTokens(codes[included] + codesSeparator)
+		if usedTokens+tokens > analyzer.mapTokens {
+			break
+		}
+		usedTokens += tokens
+	}
+
+	if included == len(codes) {
+		return strings.Join(codes, codesSeparator)
+	}
+
+	var omittedPaths []string
+	for _, code := range codes[included:] {
+		match := filePathRegex.FindStringSubmatch(code)
+		if match == nil {
+			continue
+		}
+
+		tokens := token_management.EstimateTokens(match[1] + ", ")
+		if usedTokens+tokens > analyzer.mapTokens {
+			break
+		}
+		usedTokens += tokens
+		omittedPaths = append(omittedPaths, match[1])
+	}
+
+	omitted := len(codes) - included
+	note := fmt.Sprintf("The summaries of %d files of lower rank are omitted", omitted)
+	if len(omittedPaths) > 0 {
+		note += ", request them in full when needed: " + strings.Join(omittedPaths, ", ")
+		if len(omittedPaths) < omitted {
+			note += fmt.Sprintf(" and %d more", omitted-len(omittedPaths))
+		}
+	}
+
+	return strings.Join(append(codes[:included:included], note), codesSeparator)
+}
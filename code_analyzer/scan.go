@@ -0,0 +1,118 @@
+package code_analyzer
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"runtime"
+	"strings"
+	"sync"
+
+	"github.com/meysamhadeli/codai/code_analyzer/models"
+	"github.com/meysamhadeli/codai/utils"
+)
+
+// progressStep is the number of scanned files between two reports of the progress
+const progressStep = 50
+
+// scanEntry is a file or directory of the walk of the project, excluded by the walk with its reason or scanned by a worker
+type scanEntry struct {
+	path         string
+	relativePath string
+	reason       string // The reason of the exclusion of the file, e.g., an ignore rule or a binary content
+	fileData     models.FileData
+	err          error
+}
+
+// SetProgressHandler sets the handler reporting the files scanned among the files of the project, e.g., in a spinner.
+func (analyzer *CodeAnalyzer) SetProgressHandler(handler func(processed int, total int)) {
+	analyzer.onProgress = handler
+}
+
+// scanFiles reads and summarizes the files not excluded by the walk with a worker per CPU. Each worker fills the entry
+// of its file, so the entries keep the order of the walk.
+func (analyzer *CodeAnalyzer) scanFiles(entries []scanEntry) {
+	var indexes []int
+	for i, entry := range entries {
+		if entry.reason == "" {
+			indexes = append(indexes, i)
+		}
+	}
+
+	jobs := make(chan int)
+	done := make(chan struct{})
+
+	var wg sync.WaitGroup
+	for worker := 0; worker < min(runtime.NumCPU(), len(indexes)); worker++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				analyzer.scanFile(&entries[i])
+				done <- struct{}{}
+			}
+		}()
+	}
+
+	go func() {
+		for _, i := range indexes {
+			jobs <- i
+		}
+		close(jobs)
+		wg.Wait()
+		close(done)
+	}()
+
+	processed := 0
+	for range done {
+		processed++
+		if analyzer.onProgress != nil && (processed%progressStep == 0 || processed == len(indexes)) {
+			analyzer.onProgress(processed, len(indexes))
+		}
+	}
+}
+
+// scanFile reads and summarizes a file, or records the reason to skip it, e.g., its size or a generated content.
+func (analyzer *CodeAnalyzer) scanFile(entry *scanEntry) {
+	// Check file size
+	fileInfo, err := os.Stat(entry.path)
+	if err != nil {
+		entry.err = fmt.Errorf("failed to get file info: %s, error: %w", entry.relativePath, err)
+		return
+	}
+	if analyzer.maxFileSizeKB > 0 && fileInfo.Size() > int64(analyzer.maxFileSizeKB)*1024 {
+		entry.reason = models.ExcludedTooLarge
+		return
+	}
+
+	// Read the file content using the full path
+	content, err := ioutil.ReadFile(entry.path)
+	if err != nil {
+		entry.err = fmt.Errorf("failed to read file: %s, error: %w", entry.relativePath, err)
+		return
+	}
+
+	if entry.reason = skipReasonOfContent(entry.relativePath, content); entry.reason != "" {
+		return
+	}
+
+	codeParts, symbols, references := analyzer.summarizeFile(entry.relativePath, utils.GetSupportedLanguage(entry.relativePath), content)
+
+	entry.fileData = models.FileData{RelativePath: entry.relativePath, Code: string(content), TreeSitterCode: strings.Join(codeParts, "\n"), Symbols: symbols, References: references}
+}
+
+// skipReasonOfContent returns the reason to skip a file by its content, e.g., a binary or generated file, or an empty
+// string to keep it.
+func skipReasonOfContent(relativePath string, content []byte) string {
+	switch {
+	case utils.IsBinaryContent(content):
+		return models.ExcludedBinary
+	case !utils.IsTextEncoded(content):
+		return models.ExcludedNotUTF8
+	case utils.IsGeneratedContent(content):
+		return models.ExcludedGenerated
+	case utils.IsMinifiedContent(relativePath, content):
+		return models.ExcludedMinified
+	}
+	return ""
+}
@@ -0,0 +1,255 @@
+package code_analyzer
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/meysamhadeli/codai/code_analyzer/models"
+	"github.com/meysamhadeli/codai/utils"
+)
+
+// SetScope limits the context to directories or files of the project, given by their paths or by the names of the
+// scopes of 'context.scopes', e.g., 'services/billing' or 'billing'. An empty scope, or '.', is the whole project.
+func (analyzer *CodeAnalyzer) SetScope(scope []string) error {
+	var paths []string
+	var missing []string
+	wholeProject := false
+
+	for _, entry := range scope {
+		for _, name := range strings.Split(entry, ",") {
+			name = strings.TrimSpace(name)
+			if name == "" {
+				continue
+			}
+
+			scopePaths := []string{name}
+			if namedPaths, exists := analyzer.namedScopes[name]; exists {
+				scopePaths = namedPaths
+			}
+
+			for _, scopePath := range scopePaths {
+				scopePath = analyzer.relativeScopePath(scopePath)
+				if scopePath == "." {
+					wholeProject = true
+					continue
+				}
+				if scopePath == ".." || strings.HasPrefix(scopePath, "../") {
+					missing = append(missing, scopePath)
+					continue
+				}
+				if _, err := os.Stat(filepath.Join(analyzer.Cwd, filepath.FromSlash(scopePath))); err != nil {
+					missing = append(missing, scopePath)
+					continue
+				}
+				if !slices.Contains(paths, scopePath) {
+					paths = append(paths, scopePath)
+				}
+			}
+		}
+	}
+
+	if len(missing) > 0 {
+		return fmt.Errorf("the scope '%s' is neither a path of the project nor a scope of 'context.scopes'", strings.Join(missing, "', '"))
+	}
+
+	sort.Strings(paths)
+	if wholeProject {
+		paths = nil
+	}
+	analyzer.scope = paths
+
+	return nil
+}
+
+// Scope returns the paths the context is limited to, empty for the whole project.
+func (analyzer *CodeAnalyzer) Scope() []string {
+	return slices.Clone(analyzer.scope)
+}
+
+// NamedScopes returns the scopes of 'context.scopes' with their paths.
+func (analyzer *CodeAnalyzer) NamedScopes() map[string][]string {
+	return analyzer.namedScopes
+}
+
+// relativeScopePath returns the path of a scope relative to the root of the project, with '/' separators.
+func (analyzer *CodeAnalyzer) relativeScopePath(scopePath string) string {
+	if filepath.IsAbs(scopePath) {
+		if relativePath, err := filepath.Rel(analyzer.Cwd, scopePath); err == nil {
+			scopePath = relativePath
+		}
+	}
+	return filepath.ToSlash(filepath.Clean(scopePath))
+}
+
+// inScope reports whether a path of the walk of the project is in the scope, or is a directory holding a path of the scope.
+func (analyzer *CodeAnalyzer) inScope(relativePath string, isDir bool) bool {
+	if len(analyzer.scope) == 0 {
+		return true
+	}
+
+	for _, scopePath := range analyzer.scope {
+		if relativePath == scopePath || strings.HasPrefix(relativePath, scopePath+"/") {
+			return true
+		}
+		if isDir && strings.HasPrefix(scopePath, relativePath+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// DetectWorkspaces returns the packages of a monorepo suggested as scopes, sorted by path: the nested Go modules, the
+// workspaces of the root 'package.json' and the modules of the Maven 'pom.xml' files.
+func DetectWorkspaces(rootDir string) []models.Workspace {
+	var workspaces []models.Workspace
+	workspaces = append(workspaces, detectGoModules(rootDir)...)
+	workspaces = append(workspaces, detectNpmWorkspaces(rootDir)...)
+	workspaces = append(workspaces, detectMavenModules(rootDir, ".", make(map[string]bool))...)
+
+	sort.SliceStable(workspaces, func(i, j int) bool {
+		if workspaces[i].Path != workspaces[j].Path {
+			return workspaces[i].Path < workspaces[j].Path
+		}
+		return workspaces[i].Kind < workspaces[j].Kind
+	})
+
+	return slices.Compact(workspaces)
+}
+
+// detectGoModules returns the directories with a 'go.mod' below the root, skipping the ignored and vendored directories.
+func detectGoModules(rootDir string) []models.Workspace {
+	var workspaces []models.Workspace
+
+	_ = filepath.WalkDir(rootDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+
+		relativePath, err := filepath.Rel(rootDir, path)
+		if err != nil {
+			return nil
+		}
+		relativePath = filepath.ToSlash(relativePath)
+
+		if d.IsDir() {
+			if relativePath != "." && (utils.IsDefaultIgnored(relativePath, true) || d.Name() == "vendor" || d.Name() == "testdata") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if d.Name() == "go.mod" && filepath.Dir(relativePath) != "." {
+			workspaces = append(workspaces, models.Workspace{Path: filepath.ToSlash(filepath.Dir(relativePath)), Kind: models.WorkspaceGoModule})
+		}
+		return nil
+	})
+
+	return workspaces
+}
+
+// detectNpmWorkspaces returns the directories with a 'package.json' matching the 'workspaces' of the root 'package.json',
+// either a list of globs or an object with the globs in 'packages', the globs starting with '!' excluding directories.
+func detectNpmWorkspaces(rootDir string) []models.Workspace {
+	content, err := os.ReadFile(filepath.Join(rootDir, "package.json"))
+	if err != nil {
+		return nil
+	}
+
+	var packageJson struct {
+		Workspaces json.RawMessage `json:"workspaces"`
+	}
+	if err := json.Unmarshal(content, &packageJson); err != nil || packageJson.Workspaces == nil {
+		return nil
+	}
+
+	var patterns []string
+	if err := json.Unmarshal(packageJson.Workspaces, &patterns); err != nil {
+		var workspacesObject struct {
+			Packages []string `json:"packages"`
+		}
+		if err := json.Unmarshal(packageJson.Workspaces, &workspacesObject); err != nil {
+			return nil
+		}
+		patterns = workspacesObject.Packages
+	}
+
+	var includes, excludes []string
+	for _, pattern := range patterns {
+		if strings.HasPrefix(pattern, "!") {
+			excludes = append(excludes, pattern[1:])
+		} else {
+			includes = append(includes, pattern)
+		}
+	}
+
+	// The globs are matched on a walk of the project since 'filepath.Glob' doesn't support '**', e.g., 'packages/**'
+	var workspaces []models.Workspace
+	_ = filepath.WalkDir(rootDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || !d.IsDir() {
+			return nil
+		}
+
+		relativePath, err := filepath.Rel(rootDir, path)
+		if err != nil {
+			return nil
+		}
+		relativePath = filepath.ToSlash(relativePath)
+
+		if relativePath == "." {
+			return nil
+		}
+		if utils.IsDefaultIgnored(relativePath, true) {
+			return filepath.SkipDir
+		}
+
+		matches := func(pattern string) bool { return utils.MatchGlob(pattern, relativePath) }
+		if !slices.ContainsFunc(includes, matches) || slices.ContainsFunc(excludes, matches) {
+			return nil
+		}
+		if _, err := os.Stat(filepath.Join(path, "package.json")); err == nil {
+			workspaces = append(workspaces, models.Workspace{Path: relativePath, Kind: models.WorkspaceNpm})
+		}
+		return nil
+	})
+
+	return workspaces
+}
+
+// detectMavenModules returns the modules of the 'pom.xml' of a directory, and of their own 'pom.xml' recursively.
+func detectMavenModules(rootDir string, dir string, seen map[string]bool) []models.Workspace {
+	if seen[dir] {
+		return nil
+	}
+	seen[dir] = true
+
+	content, err := os.ReadFile(filepath.Join(rootDir, filepath.FromSlash(dir), "pom.xml"))
+	if err != nil {
+		return nil
+	}
+
+	var pom struct {
+		Modules []string `xml:"modules>module"`
+	}
+	if err := xml.Unmarshal(content, &pom); err != nil {
+		return nil
+	}
+
+	var workspaces []models.Workspace
+	for _, module := range pom.Modules {
+		modulePath := filepath.ToSlash(filepath.Clean(filepath.Join(filepath.FromSlash(dir), strings.TrimSpace(module))))
+		if modulePath == "." || strings.HasPrefix(modulePath, "../") {
+			continue
+		}
+		workspaces = append(workspaces, models.Workspace{Path: modulePath, Kind: models.WorkspaceMavenModule})
+		workspaces = append(workspaces, detectMavenModules(rootDir, modulePath, seen)...)
+	}
+
+	return workspaces
+}
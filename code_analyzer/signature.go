@@ -0,0 +1,300 @@
+package code_analyzer
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/meysamhadeli/codai/code_analyzer/models"
+	sitter "github.com/smacker/go-tree-sitter"
+)
+
+// maxSignatureLength limits the length of a signature or a doc comment in the summary of a file
+const maxSignatureLength = 500
+
+// fieldsTags are the tags of elements whose body, e.g., the fields of a struct or the methods of an interface, is kept in their signature
+var fieldsTags = []string{"struct", "union", "enum", "record", "interface", "protocol"}
+
+// wrapperTypes are the nodes wrapping a definition, e.g., 'export function Foo()', holding its doc comment
+var wrapperTypes = []string{"export_statement", "decorated_definition", "template_declaration", "ambient_declaration"}
+
+// bodyTypes are the nodes of a body in the grammars without the field 'body', e.g., Kotlin and Elixir
+var bodyTypes = []string{"class_body", "function_body", "enum_class_body", "do_block"}
+
+// commentMarkers matches the markers of a comment line, e.g., '//', '/**', '*', '#' or '--'
+var commentMarkers = regexp.MustCompile(`^(///?|/\*+|\*+/|\*|#+|--+|"""|''')\s?`)
+
+// summaryElement is an element of the summary of a file, e.g., a function with its signature and doc comment
+type summaryElement struct {
+	start    uint32
+	name     string
+	text     string
+	doc      string
+	exported bool
+}
+
+// ConfigureContext configures the context of project, e.g., to keep only the exported elements, the token budget of the
+// repository map or the limits of the size and number of files.
+func (analyzer *CodeAnalyzer) ConfigureContext(contextConfig *models.ContextConfig) {
+	analyzer.exportedOnly = contextConfig != nil && contextConfig.ExportedOnly
+	analyzer.mapTokens = 0
+	analyzer.include = nil
+	analyzer.exclude = nil
+	analyzer.maxFileSizeKB = defaultMaxFileSizeKB
+	analyzer.maxFiles = 0
+	analyzer.namedScopes = nil
+	if contextConfig != nil {
+		analyzer.mapTokens = contextConfig.MapTokens
+		analyzer.include = contextConfig.Include
+		analyzer.exclude = contextConfig.Exclude
+		analyzer.maxFileSizeKB = contextConfig.MaxFileSizeKB
+		analyzer.maxFiles = contextConfig.MaxFiles
+		analyzer.namedScopes = contextConfig.Scopes
+	}
+}
+
+// newSummaryElement tags an element with its type, the signature of its definition replaces its name when it's captured.
+func newSummaryElement(language string, tag string, name *sitter.Node, definition *sitter.Node, sourceCode []byte) summaryElement {
+	nameContent := name.Content(sourceCode)
+
+	if definition == nil {
+		return summaryElement{start: name.StartByte(), name: nameContent, text: tag + ": " + nameContent, exported: true}
+	}
+
+	body := findBody(definition)
+	signature := definitionSignature(definition, body, sourceCode, slices.Contains(fieldsTags, tag))
+
+	doc := docComment(definition, sourceCode)
+	if doc == "" {
+		doc = docString(body, sourceCode)
+	}
+	if doc != "" {
+		doc = commentPrefix(language) + doc
+	}
+
+	return summaryElement{
+		start:    definition.StartByte(),
+		name:     nameContent,
+		text:     tag + ": " + signature,
+		doc:      doc,
+		exported: isExported(language, definition, nameContent, signature),
+	}
+}
+
+// definitionSignature returns the definition without its body and comments, compacted on a single line.
+// Without a body in the grammar, e.g., a Ruby method, only the first line of the definition is kept.
+func definitionSignature(definition *sitter.Node, body *sitter.Node, sourceCode []byte, keepBody bool) string {
+	end := definition.EndByte()
+	if !keepBody {
+		if body != nil {
+			end = body.StartByte()
+		} else {
+			content := definition.Content(sourceCode)
+			offset := len(content) - len(strings.TrimLeft(content, " \t\r\n"))
+			if index := strings.IndexByte(content[offset:], '\n'); index >= 0 {
+				end = definition.StartByte() + uint32(offset+index)
+			}
+		}
+	}
+
+	// Remove the comments inside the signature, e.g., the comments of the fields of a struct
+	var builder strings.Builder
+	position := definition.StartByte()
+	for _, comment := range findComments(definition, end) {
+		builder.Write(sourceCode[position:comment.StartByte()])
+		position = comment.EndByte()
+	}
+	if position < end {
+		builder.Write(sourceCode[position:end])
+	}
+
+	signature := compactLines(builder.String())
+	if !keepBody {
+		// The opening of the removed body, e.g., '{' in Java, ':' in Python or 'do' in Elixir
+		signature = strings.TrimSuffix(strings.TrimRight(signature, " {:=;"), " do")
+	}
+
+	return truncate(signature)
+}
+
+// findBody returns the first node of the definition in breadth-first order with the field 'body', e.g., the block of a function.
+func findBody(definition *sitter.Node) *sitter.Node {
+	nodes := []*sitter.Node{definition}
+	for len(nodes) > 0 {
+		node := nodes[0]
+		nodes = nodes[1:]
+
+		for i := 0; i < int(node.ChildCount()); i++ {
+			if node.FieldNameForChild(i) == "body" || slices.Contains(bodyTypes, node.Child(i).Type()) {
+				return node.Child(i)
+			}
+			nodes = append(nodes, node.Child(i))
+		}
+	}
+	return nil
+}
+
+// findComments returns the comments of the definition starting before the end, in source order.
+func findComments(node *sitter.Node, end uint32) []*sitter.Node {
+	var comments []*sitter.Node
+	for i := 0; i < int(node.NamedChildCount()); i++ {
+		child := node.NamedChild(i)
+		if child.StartByte() >= end {
+			break
+		}
+		if strings.Contains(child.Type(), "comment") {
+			if child.EndByte() <= end {
+				comments = append(comments, child)
+			}
+			continue
+		}
+		comments = append(comments, findComments(child, end)...)
+	}
+	return comments
+}
+
+// docComment returns the comments right above the definition, or above the node wrapping it, without their markers.
+func docComment(definition *sitter.Node, sourceCode []byte) string {
+	node := definition
+	for node.Parent() != nil && slices.Contains(wrapperTypes, node.Parent().Type()) {
+		node = node.Parent()
+	}
+
+	var lines []string
+	row := node.StartPoint().Row
+	for sibling := node.PrevNamedSibling(); sibling != nil && strings.Contains(sibling.Type(), "comment"); sibling = sibling.PrevNamedSibling() {
+		// Only the comments adjacent to the definition, a blank line ends the doc comment
+		if sibling.EndPoint().Row+1 < row {
+			break
+		}
+		row = sibling.StartPoint().Row
+
+		var commentLines []string
+		for _, line := range strings.Split(sibling.Content(sourceCode), "\n") {
+			line = strings.TrimSpace(commentMarkers.ReplaceAllString(strings.TrimSpace(line), ""))
+			line = strings.TrimSpace(strings.TrimSuffix(line, "*/"))
+			if line != "" {
+				commentLines = append(commentLines, line)
+			}
+		}
+		lines = append(commentLines, lines...)
+	}
+
+	if len(lines) == 0 {
+		return ""
+	}
+	return truncate(strings.Join(lines, " "))
+}
+
+// docString returns the string starting the body as the doc comment, e.g., the docstring of a Python function.
+func docString(body *sitter.Node, sourceCode []byte) string {
+	if body == nil || body.NamedChildCount() == 0 {
+		return ""
+	}
+
+	statement := body.NamedChild(0)
+	if statement.Type() != "expression_statement" || statement.NamedChildCount() == 0 || statement.NamedChild(0).Type() != "string" {
+		return ""
+	}
+
+	var lines []string
+	for _, line := range strings.Split(statement.NamedChild(0).Content(sourceCode), "\n") {
+		line = strings.TrimSpace(strings.Trim(strings.TrimSpace(line), `"'`))
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+
+	if len(lines) == 0 {
+		return ""
+	}
+	return truncate(strings.Join(lines, " "))
+}
+
+// commentPrefix returns the marker of a line comment in the language, so a doc comment reads like in its source, e.g., '# ' in Python.
+func commentPrefix(language string) string {
+	switch language {
+	case "python", "ruby", "bash", "elixir", "dockerfile", "hcl", "toml", "yaml":
+		return "# "
+	case "lua", "elm", "sql":
+		return "-- "
+	default:
+		return "// "
+	}
+}
+
+// compactLines joins the lines of a signature, with a '; ' between the lines that are separate statements, e.g., the fields of a struct.
+func compactLines(text string) string {
+	var builder strings.Builder
+	previous := ""
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.Join(strings.Fields(line), " ")
+		if line == "" {
+			continue
+		}
+		if previous != "" {
+			if strings.HasSuffix(previous, ",") || strings.HasSuffix(previous, ";") || strings.HasSuffix(previous, "(") ||
+				strings.HasSuffix(previous, "[") || strings.HasSuffix(previous, "{") ||
+				strings.HasPrefix(line, ")") || strings.HasPrefix(line, "]") || strings.HasPrefix(line, "}") {
+				builder.WriteString(" ")
+			} else {
+				builder.WriteString("; ")
+			}
+		}
+		builder.WriteString(line)
+		previous = line
+	}
+	return strings.TrimSpace(builder.String())
+}
+
+// truncate limits the text to maxSignatureLength runes.
+func truncate(text string) string {
+	if utf8.RuneCountInString(text) <= maxSignatureLength {
+		return text
+	}
+	return string([]rune(text)[:maxSignatureLength]) + "…"
+}
+
+// isExported reports whether the element is visible outside its file or package by the conventions of its language,
+// e.g., the capitalized names in Go, 'pub' in Rust or the members not marked 'private' in Java.
+func isExported(language string, definition *sitter.Node, name string, signature string) bool {
+	// The modifiers of the element are the words of its signature before its name
+	modifiers := signature
+	if index := strings.Index(signature, name); index >= 0 {
+		modifiers = signature[:index]
+	}
+	words := strings.FieldsFunc(modifiers, func(r rune) bool { return !unicode.IsLetter(r) })
+
+	switch language {
+	case "go":
+		first, _ := utf8.DecodeRuneInString(name)
+		return unicode.IsUpper(first)
+	case "python":
+		return !strings.HasPrefix(name, "_") || (strings.HasPrefix(name, "__") && strings.HasSuffix(name, "__"))
+	case "rust":
+		return definition.Type() == "impl_item" || slices.Contains(words, "pub")
+	case "c", "cpp":
+		return !slices.Contains(words, "static")
+	case "elixir":
+		return !slices.Contains(words, "defp") && !slices.Contains(words, "defmacrop")
+	case "lua":
+		return !slices.Contains(words, "local")
+	case "javascript", "typescript", "tsx":
+		// An element is exported only when the declaration at the top of its module has 'export'
+		node := definition
+		for node.Parent() != nil && node.Parent().Type() != "program" {
+			node = node.Parent()
+		}
+		if node.Type() != "export_statement" {
+			return false
+		}
+		return !strings.HasPrefix(name, "#") && !slices.Contains(words, "private") && !slices.Contains(words, "protected")
+	case "ruby", "bash":
+		return true
+	default:
+		return !slices.Contains(words, "private") && !slices.Contains(words, "protected") &&
+			!slices.Contains(words, "internal") && !slices.Contains(words, "fileprivate")
+	}
+}
@@ -0,0 +1,91 @@
+package code_analyzer
+
+import (
+	"sort"
+
+	"github.com/meysamhadeli/codai/embed_data"
+	sitter "github.com/smacker/go-tree-sitter"
+	"github.com/smacker/go-tree-sitter/bash"
+	"github.com/smacker/go-tree-sitter/c"
+	"github.com/smacker/go-tree-sitter/cpp"
+	"github.com/smacker/go-tree-sitter/csharp"
+	"github.com/smacker/go-tree-sitter/css"
+	"github.com/smacker/go-tree-sitter/cue"
+	"github.com/smacker/go-tree-sitter/dockerfile"
+	"github.com/smacker/go-tree-sitter/elixir"
+	"github.com/smacker/go-tree-sitter/elm"
+	"github.com/smacker/go-tree-sitter/golang"
+	"github.com/smacker/go-tree-sitter/groovy"
+	"github.com/smacker/go-tree-sitter/hcl"
+	"github.com/smacker/go-tree-sitter/html"
+	"github.com/smacker/go-tree-sitter/java"
+	"github.com/smacker/go-tree-sitter/javascript"
+	"github.com/smacker/go-tree-sitter/kotlin"
+	"github.com/smacker/go-tree-sitter/lua"
+	"github.com/smacker/go-tree-sitter/ocaml"
+	"github.com/smacker/go-tree-sitter/php"
+	"github.com/smacker/go-tree-sitter/protobuf"
+	"github.com/smacker/go-tree-sitter/python"
+	"github.com/smacker/go-tree-sitter/ruby"
+	"github.com/smacker/go-tree-sitter/rust"
+	"github.com/smacker/go-tree-sitter/scala"
+	"github.com/smacker/go-tree-sitter/sql"
+	"github.com/smacker/go-tree-sitter/svelte"
+	"github.com/smacker/go-tree-sitter/swift"
+	"github.com/smacker/go-tree-sitter/toml"
+	"github.com/smacker/go-tree-sitter/typescript/tsx"
+	"github.com/smacker/go-tree-sitter/typescript/typescript"
+	"github.com/smacker/go-tree-sitter/yaml"
+)
+
+// treeSitterLanguage holds the grammar of a language and the embedded queries of its elements, tagged by their type
+type treeSitterLanguage struct {
+	grammar func() *sitter.Language
+	query   []byte
+}
+
+// treeSitterLanguages maps the languages returned by utils.GetSupportedLanguage to their grammar and queries,
+// the languages without embedded queries are summarized with the queries of '.codai/queries/<lang>.scm'
+var treeSitterLanguages = map[string]treeSitterLanguage{
+	"csharp":     {csharp.GetLanguage, embed_data.CSharpQuery},
+	"go":         {golang.GetLanguage, embed_data.GoQuery},
+	"python":     {python.GetLanguage, embed_data.PythonQuery},
+	"java":       {java.GetLanguage, embed_data.JavaQuery},
+	"javascript": {javascript.GetLanguage, embed_data.JavascriptQuery},
+	"typescript": {typescript.GetLanguage, embed_data.TypescriptQuery},
+	"tsx":        {tsx.GetLanguage, embed_data.TypescriptQuery},
+	"rust":       {rust.GetLanguage, embed_data.RustQuery},
+	"c":          {c.GetLanguage, embed_data.CQuery},
+	"cpp":        {cpp.GetLanguage, embed_data.CppQuery},
+	"ruby":       {ruby.GetLanguage, embed_data.RubyQuery},
+	"php":        {php.GetLanguage, embed_data.PhpQuery},
+	"kotlin":     {kotlin.GetLanguage, embed_data.KotlinQuery},
+	"swift":      {swift.GetLanguage, embed_data.SwiftQuery},
+	"scala":      {scala.GetLanguage, embed_data.ScalaQuery},
+	"bash":       {bash.GetLanguage, embed_data.BashQuery},
+	"lua":        {lua.GetLanguage, embed_data.LuaQuery},
+	"elixir":     {elixir.GetLanguage, embed_data.ElixirQuery},
+	"css":        {css.GetLanguage, nil},
+	"cue":        {cue.GetLanguage, nil},
+	"dockerfile": {dockerfile.GetLanguage, nil},
+	"elm":        {elm.GetLanguage, nil},
+	"groovy":     {groovy.GetLanguage, nil},
+	"hcl":        {hcl.GetLanguage, nil},
+	"html":       {html.GetLanguage, nil},
+	"ocaml":      {ocaml.GetLanguage, nil},
+	"protobuf":   {protobuf.GetLanguage, nil},
+	"sql":        {sql.GetLanguage, nil},
+	"svelte":     {svelte.GetLanguage, nil},
+	"toml":       {toml.GetLanguage, nil},
+	"yaml":       {yaml.GetLanguage, nil},
+}
+
+// TreeSitterLanguages returns the sorted names of the languages with a Tree-sitter grammar, e.g., for 'codai analyze --lang'
+func TreeSitterLanguages() []string {
+	languages := make([]string, 0, len(treeSitterLanguages))
+	for language := range treeSitterLanguages {
+		languages = append(languages, language)
+	}
+	sort.Strings(languages)
+	return languages
+}
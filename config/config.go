@@ -2,8 +2,10 @@ package config
 
 import (
 	"fmt"
+	code_analyzer_models "github.com/meysamhadeli/codai/code_analyzer/models"
 	"github.com/meysamhadeli/codai/constants/lipgloss"
 	"github.com/meysamhadeli/codai/providers"
+	token_models "github.com/meysamhadeli/codai/token_management/models"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 	"os"
@@ -12,15 +14,25 @@ import (
 
 // Config represents the structure of the configuration file
 type Config struct {
-	Version          string                      `mapstructure:"version"`
-	Theme            string                      `mapstructure:"theme"`
-	AIProviderConfig *providers.AIProviderConfig `mapstructure:"ai_provider_config"`
+	Version           string                              `mapstructure:"version"`
+	Theme             string                              `mapstructure:"theme"`
+	CollapseReasoning bool                                `mapstructure:"collapse_reasoning"`
+	SaveSessions      bool                                `mapstructure:"save_sessions"`
+	InstructionsFile  string                              `mapstructure:"instructions_file"`
+	PromptTemplate    string                              `mapstructure:"prompt_template"`
+	AIProviderConfig  *providers.AIProviderConfig         `mapstructure:"ai_provider_config"`
+	Budget            *token_models.BudgetConfig          `mapstructure:"budget"`
+	Context           *code_analyzer_models.ContextConfig `mapstructure:"context"`
 }
 
 // DefaultConfig values
 var DefaultConfig = Config{
-	Version: "1.8.4",
-	Theme:   "dracula",
+	Version:           "1.8.4",
+	Theme:             "dracula",
+	CollapseReasoning: false,
+	SaveSessions:      true,
+	InstructionsFile:  "CODAI.md",
+	PromptTemplate:    "",
 	AIProviderConfig: &providers.AIProviderConfig{
 		Provider:        "openai",
 		BaseURL:         "https://api.openai.com/v1",
@@ -29,9 +41,27 @@ var DefaultConfig = Config{
 		EncodingFormat:  "float",
 		Temperature:     nil,
 		ReasoningEffort: nil,
+		ThinkingBudget:  nil,
 		ApiVersion:      "",
 		ApiKey:          "",
 	},
+	Budget: &token_models.BudgetConfig{
+		PerRequestUSD: 0,
+		PerSessionUSD: 0,
+		DailyUSD:      0,
+		WarnThreshold: 0.8,
+		OnExceed:      "confirm",
+	},
+	Context: &code_analyzer_models.ContextConfig{
+		ExportedOnly:  false,
+		MapTokens:     8192,
+		Include:       []string{},
+		Exclude:       []string{},
+		MaxFileSizeKB: 100,
+		MaxFiles:      5000,
+		Scope:         []string{},
+		Scopes:        map[string][]string{},
+	},
 }
 
 // cfgFile holds the path to the configuration file (set via CLI)
@@ -66,7 +96,8 @@ func LoadConfigs(rootCmd *cobra.Command, cwd string) *Config {
 			viper.SetConfigType("json")
 			if err := viper.ReadInConfig(); err != nil {
 				// If both fail, we'll continue with defaults
-				fmt.Println(lipgloss.Yellow.Render("No configuration file found, using defaults"))
+				// On stderr, the output of commands like 'codai context --json' stays parsable
+				fmt.Fprintln(os.Stderr, lipgloss.Yellow.Render("No configuration file found, using defaults"))
 			}
 		}
 	}
@@ -95,39 +126,93 @@ func LoadConfigs(rootCmd *cobra.Command, cwd string) *Config {
 func setDefaults() {
 	viper.SetDefault("version", DefaultConfig.Version)
 	viper.SetDefault("theme", DefaultConfig.Theme)
+	viper.SetDefault("collapse_reasoning", DefaultConfig.CollapseReasoning)
+	viper.SetDefault("save_sessions", DefaultConfig.SaveSessions)
+	viper.SetDefault("instructions_file", DefaultConfig.InstructionsFile)
+	viper.SetDefault("prompt_template", DefaultConfig.PromptTemplate)
 	viper.SetDefault("ai_provider_config.provider", DefaultConfig.AIProviderConfig.Provider)
 	viper.SetDefault("ai_provider_config.base_url", DefaultConfig.AIProviderConfig.BaseURL)
 	viper.SetDefault("ai_provider_config.model", DefaultConfig.AIProviderConfig.Model)
 	viper.SetDefault("ai_provider_config.encoding_format", DefaultConfig.AIProviderConfig.EncodingFormat)
 	viper.SetDefault("ai_provider_config.temperature", DefaultConfig.AIProviderConfig.Temperature)
 	viper.SetDefault("ai_provider_config.reasoning_effort", DefaultConfig.AIProviderConfig.ReasoningEffort)
+	viper.SetDefault("ai_provider_config.thinking_budget", DefaultConfig.AIProviderConfig.ThinkingBudget)
 	viper.SetDefault("ai_provider_config.stream", DefaultConfig.AIProviderConfig.Stream)
 	viper.SetDefault("ai_provider_config.api_key", DefaultConfig.AIProviderConfig.ApiKey)
 	viper.SetDefault("ai_provider_config.api_version", DefaultConfig.AIProviderConfig.ApiVersion)
+	viper.SetDefault("budget.per_request_usd", DefaultConfig.Budget.PerRequestUSD)
+	viper.SetDefault("budget.per_session_usd", DefaultConfig.Budget.PerSessionUSD)
+	viper.SetDefault("budget.daily_usd", DefaultConfig.Budget.DailyUSD)
+	viper.SetDefault("budget.warn_threshold", DefaultConfig.Budget.WarnThreshold)
+	viper.SetDefault("budget.on_exceed", DefaultConfig.Budget.OnExceed)
+	viper.SetDefault("context.exported_only", DefaultConfig.Context.ExportedOnly)
+	viper.SetDefault("context.map_tokens", DefaultConfig.Context.MapTokens)
+	viper.SetDefault("context.include", DefaultConfig.Context.Include)
+	viper.SetDefault("context.exclude", DefaultConfig.Context.Exclude)
+	viper.SetDefault("context.max_file_size_kb", DefaultConfig.Context.MaxFileSizeKB)
+	viper.SetDefault("context.max_files", DefaultConfig.Context.MaxFiles)
+	viper.SetDefault("context.scope", DefaultConfig.Context.Scope)
+	viper.SetDefault("context.scopes", DefaultConfig.Context.Scopes)
 }
 
 // bindEnv explicitly binds environment variables to configuration keys
 func bindEnv() {
 	_ = viper.BindEnv("theme", "THEME")
+	_ = viper.BindEnv("collapse_reasoning", "COLLAPSE_REASONING")
+	_ = viper.BindEnv("save_sessions", "SAVE_SESSIONS")
+	_ = viper.BindEnv("instructions_file", "INSTRUCTIONS_FILE")
+	_ = viper.BindEnv("prompt_template", "PROMPT_TEMPLATE")
 	_ = viper.BindEnv("ai_provider_config.provider", "PROVIDER")
 	_ = viper.BindEnv("ai_provider_config.base_url", "BASE_URL")
 	_ = viper.BindEnv("ai_provider_config.model", "MODEL")
 	_ = viper.BindEnv("ai_provider_config.temperature", "TEMPERATURE")
 	_ = viper.BindEnv("ai_provider_config.reasoning_effort", "REASONING_EFFORT")
+	_ = viper.BindEnv("ai_provider_config.thinking_budget", "THINKING_BUDGET")
+	_ = viper.BindEnv("ai_provider_config.stream", "STREAM")
 	_ = viper.BindEnv("ai_provider_config.api_key", "API_KEY")
 	_ = viper.BindEnv("ai_provider_config.api_version", "API_VERSION")
+	_ = viper.BindEnv("budget.per_request_usd", "BUDGET_PER_REQUEST_USD")
+	_ = viper.BindEnv("budget.per_session_usd", "BUDGET_PER_SESSION_USD")
+	_ = viper.BindEnv("budget.daily_usd", "BUDGET_DAILY_USD")
+	_ = viper.BindEnv("budget.warn_threshold", "BUDGET_WARN_THRESHOLD")
+	_ = viper.BindEnv("budget.on_exceed", "BUDGET_ON_EXCEED")
+	_ = viper.BindEnv("context.exported_only", "CONTEXT_EXPORTED_ONLY")
+	_ = viper.BindEnv("context.map_tokens", "CONTEXT_MAP_TOKENS")
+	_ = viper.BindEnv("context.include", "CONTEXT_INCLUDE")
+	_ = viper.BindEnv("context.exclude", "CONTEXT_EXCLUDE")
+	_ = viper.BindEnv("context.max_file_size_kb", "CONTEXT_MAX_FILE_SIZE_KB")
+	_ = viper.BindEnv("context.max_files", "CONTEXT_MAX_FILES")
+	_ = viper.BindEnv("context.scope", "CONTEXT_SCOPE")
 }
 
-// bindFlags binds the CLI flags to configuration values.
+// bindFlags binds the CLI flags to configuration values, including the persistent flags inherited by subcommands.
 func bindFlags(rootCmd *cobra.Command) {
-	_ = viper.BindPFlag("theme", rootCmd.PersistentFlags().Lookup("theme"))
-	_ = viper.BindPFlag("ai_provider_config.provider", rootCmd.PersistentFlags().Lookup("provider"))
-	_ = viper.BindPFlag("ai_provider_config.base_url", rootCmd.PersistentFlags().Lookup("base_url"))
-	_ = viper.BindPFlag("ai_provider_config.model", rootCmd.PersistentFlags().Lookup("model"))
-	_ = viper.BindPFlag("ai_provider_config.temperature", rootCmd.PersistentFlags().Lookup("temperature"))
-	_ = viper.BindPFlag("ai_provider_config.reasoning_effort", rootCmd.PersistentFlags().Lookup("reasoning_effort"))
-	_ = viper.BindPFlag("ai_provider_config.api_key", rootCmd.PersistentFlags().Lookup("api_key"))
-	_ = viper.BindPFlag("ai_provider_config.api_version", rootCmd.PersistentFlags().Lookup("api_version"))
+	_ = viper.BindPFlag("theme", rootCmd.Flags().Lookup("theme"))
+	_ = viper.BindPFlag("collapse_reasoning", rootCmd.Flags().Lookup("collapse_reasoning"))
+	_ = viper.BindPFlag("save_sessions", rootCmd.Flags().Lookup("save_sessions"))
+	_ = viper.BindPFlag("instructions_file", rootCmd.Flags().Lookup("instructions_file"))
+	_ = viper.BindPFlag("prompt_template", rootCmd.Flags().Lookup("prompt_template"))
+	_ = viper.BindPFlag("ai_provider_config.provider", rootCmd.Flags().Lookup("provider"))
+	_ = viper.BindPFlag("ai_provider_config.base_url", rootCmd.Flags().Lookup("base_url"))
+	_ = viper.BindPFlag("ai_provider_config.model", rootCmd.Flags().Lookup("model"))
+	_ = viper.BindPFlag("ai_provider_config.temperature", rootCmd.Flags().Lookup("temperature"))
+	_ = viper.BindPFlag("ai_provider_config.reasoning_effort", rootCmd.Flags().Lookup("reasoning_effort"))
+	_ = viper.BindPFlag("ai_provider_config.thinking_budget", rootCmd.Flags().Lookup("thinking_budget"))
+	_ = viper.BindPFlag("ai_provider_config.stream", rootCmd.Flags().Lookup("stream"))
+	_ = viper.BindPFlag("ai_provider_config.api_key", rootCmd.Flags().Lookup("api_key"))
+	_ = viper.BindPFlag("ai_provider_config.api_version", rootCmd.Flags().Lookup("api_version"))
+	_ = viper.BindPFlag("budget.per_request_usd", rootCmd.Flags().Lookup("budget_per_request_usd"))
+	_ = viper.BindPFlag("budget.per_session_usd", rootCmd.Flags().Lookup("budget_per_session_usd"))
+	_ = viper.BindPFlag("budget.daily_usd", rootCmd.Flags().Lookup("budget_daily_usd"))
+	_ = viper.BindPFlag("budget.warn_threshold", rootCmd.Flags().Lookup("budget_warn_threshold"))
+	_ = viper.BindPFlag("budget.on_exceed", rootCmd.Flags().Lookup("budget_on_exceed"))
+	_ = viper.BindPFlag("context.exported_only", rootCmd.Flags().Lookup("context_exported_only"))
+	_ = viper.BindPFlag("context.map_tokens", rootCmd.Flags().Lookup("context_map_tokens"))
+	_ = viper.BindPFlag("context.include", rootCmd.Flags().Lookup("context_include"))
+	_ = viper.BindPFlag("context.exclude", rootCmd.Flags().Lookup("context_exclude"))
+	_ = viper.BindPFlag("context.max_file_size_kb", rootCmd.Flags().Lookup("context_max_file_size_kb"))
+	_ = viper.BindPFlag("context.max_files", rootCmd.Flags().Lookup("context_max_files"))
+	_ = viper.BindPFlag("context.scope", rootCmd.Flags().Lookup("scope"))
 }
 
 // InitFlags initializes the flags for the root command.
@@ -138,6 +223,14 @@ func InitFlags(rootCmd *cobra.Command) {
 	// Theme configuration
 	rootCmd.PersistentFlags().String("theme", DefaultConfig.Theme, "Set customize theme for buffering response from ai. (e.g., 'dracula', 'light', 'dark')")
 
+	// Reasoning display configuration
+	rootCmd.PersistentFlags().Bool("collapse_reasoning", DefaultConfig.CollapseReasoning, "Collapse the reasoning (thinking) of the model into a single line, use ':reasoning' to expand it.")
+	rootCmd.PersistentFlags().Bool("save_sessions", DefaultConfig.SaveSessions, "Save the record of the sessions in '~/.config/codai/sessions' for 'codai sessions', set it to false to keep them only in memory.")
+
+	// Prompt configuration
+	rootCmd.PersistentFlags().String("instructions_file", DefaultConfig.InstructionsFile, "The path of the project instructions (e.g., team conventions) appended to every prompt.")
+	rootCmd.PersistentFlags().String("prompt_template", DefaultConfig.PromptTemplate, "The path of a Go text/template that overrides the default prompt template.")
+
 	// Version flag
 	rootCmd.Flags().BoolP("version", "v", false, "Specifies the version of the application.")
 
@@ -147,8 +240,26 @@ func InitFlags(rootCmd *cobra.Command) {
 	rootCmd.PersistentFlags().String("model", DefaultConfig.AIProviderConfig.Model, "The name of the model used for chat completions, such as 'gpt-4o'.")
 	rootCmd.PersistentFlags().Float32("temperature", 0, "Adjusts the AI model's creativity (0-1, default 0.2).")
 	rootCmd.PersistentFlags().String("reasoning_effort", "", "Adjusts the AI Reasoning model's effort (e.g., 'low', 'medium', 'high').")
+	rootCmd.PersistentFlags().Int("thinking_budget", 0, "The token budget for extended thinking of reasoning models (e.g., Anthropic and Gemini).")
+	rootCmd.PersistentFlags().Bool("stream", DefaultConfig.AIProviderConfig.Stream, "Stream the response of the AI provider, set it to false for gateways or local servers that don't support server-sent events.")
 	rootCmd.PersistentFlags().String("api_key", DefaultConfig.AIProviderConfig.ApiKey, "The API key used to authenticate with the AI service provider.")
 	rootCmd.PersistentFlags().String("api_version", DefaultConfig.AIProviderConfig.ApiVersion, "The API version used to authenticate with the chat AI service provider.")
+
+	// Budget configuration
+	rootCmd.PersistentFlags().Float64("budget_per_request_usd", DefaultConfig.Budget.PerRequestUSD, "The limit in dollars of the cost of a request, 0 disables it.")
+	rootCmd.PersistentFlags().Float64("budget_per_session_usd", DefaultConfig.Budget.PerSessionUSD, "The limit in dollars of the cost of a session, 0 disables it.")
+	rootCmd.PersistentFlags().Float64("budget_daily_usd", DefaultConfig.Budget.DailyUSD, "The limit in dollars of the cost of a day, recorded in the usage ledger, 0 disables it.")
+	rootCmd.PersistentFlags().Float64("budget_warn_threshold", DefaultConfig.Budget.WarnThreshold, "The fraction of a budget limit from which a warning is shown (e.g., 0.8).")
+	rootCmd.PersistentFlags().String("budget_on_exceed", DefaultConfig.Budget.OnExceed, "The action on a request above a budget limit, 'confirm' to ask or 'block' to skip it.")
+
+	// Context configuration
+	rootCmd.PersistentFlags().Bool("context_exported_only", DefaultConfig.Context.ExportedOnly, "Keep only the exported functions, types and members in the summary of context of project.")
+	rootCmd.PersistentFlags().Int("context_map_tokens", DefaultConfig.Context.MapTokens, "The token budget of the repository map in the prompt, filled with the files most relevant to the mentioned or edited files, 0 for no limit.")
+	rootCmd.PersistentFlags().StringSlice("context_include", DefaultConfig.Context.Include, "The gitignore patterns of the files kept in the context of project (e.g., 'src/,*.go'), all files when empty.")
+	rootCmd.PersistentFlags().StringSlice("context_exclude", DefaultConfig.Context.Exclude, "The gitignore patterns of the files removed from the context of project, on top of '.gitignore' and '.codai-gitignore'.")
+	rootCmd.PersistentFlags().Int("context_max_file_size_kb", DefaultConfig.Context.MaxFileSizeKB, "The size in KB above which a file is skipped in the context of project, 0 for no limit.")
+	rootCmd.PersistentFlags().Int("context_max_files", DefaultConfig.Context.MaxFiles, "The number of files read for the context of project, the next files are skipped with a warning, 0 for no limit.")
+	rootCmd.PersistentFlags().StringSlice("scope", DefaultConfig.Context.Scope, "The directories or named scopes of 'context.scopes' the context of project is limited to (e.g., 'services/billing,libs/common').")
 }
 
 // GetConfigFileType returns the type of the configuration file based on its extension
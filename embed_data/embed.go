@@ -25,3 +25,36 @@ var JavascriptQuery []byte
 
 //go:embed tree-sitter/queries/typescript.scm
 var TypescriptQuery []byte
+
+//go:embed tree-sitter/queries/rust.scm
+var RustQuery []byte
+
+//go:embed tree-sitter/queries/c.scm
+var CQuery []byte
+
+//go:embed tree-sitter/queries/cpp.scm
+var CppQuery []byte
+
+//go:embed tree-sitter/queries/ruby.scm
+var RubyQuery []byte
+
+//go:embed tree-sitter/queries/php.scm
+var PhpQuery []byte
+
+//go:embed tree-sitter/queries/kotlin.scm
+var KotlinQuery []byte
+
+//go:embed tree-sitter/queries/swift.scm
+var SwiftQuery []byte
+
+//go:embed tree-sitter/queries/scala.scm
+var ScalaQuery []byte
+
+//go:embed tree-sitter/queries/bash.scm
+var BashQuery []byte
+
+//go:embed tree-sitter/queries/lua.scm
+var LuaQuery []byte
+
+//go:embed tree-sitter/queries/elixir.scm
+var ElixirQuery []byte
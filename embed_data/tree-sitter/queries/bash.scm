@@ -0,0 +1,3 @@
+{
+    "function": "(function_definition name: (word) @name) @definition"
+}
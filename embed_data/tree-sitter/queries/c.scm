@@ -0,0 +1,8 @@
+{
+    "struct": "(struct_specifier name: (type_identifier) @name body: (field_declaration_list)) @definition",
+    "union": "(union_specifier name: (type_identifier) @name body: (field_declaration_list)) @definition",
+    "enum": "(enum_specifier name: (type_identifier) @name body: (enumerator_list)) @definition",
+    "typedef": "(type_definition declarator: (type_identifier) @name) @definition",
+    "function": "(function_definition declarator: [(function_declarator declarator: (identifier) @name) (pointer_declarator declarator: (function_declarator declarator: (identifier) @name))]) @definition",
+    "prototype": "(declaration declarator: [(function_declarator declarator: (identifier) @name) (pointer_declarator declarator: (function_declarator declarator: (identifier) @name))]) @definition"
+}
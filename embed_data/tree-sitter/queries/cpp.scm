@@ -0,0 +1,8 @@
+{
+    "namespace": "(namespace_definition name: (_) @name)",
+    "class": "(class_specifier name: (type_identifier) @name body: (field_declaration_list)) @definition",
+    "struct": "(struct_specifier name: (type_identifier) @name body: (field_declaration_list)) @definition",
+    "enum": "(enum_specifier name: (type_identifier) @name body: (enumerator_list)) @definition",
+    "function": "(function_definition declarator: [(function_declarator declarator: (_) @name) (pointer_declarator declarator: (function_declarator declarator: (_) @name)) (reference_declarator (function_declarator declarator: (_) @name))]) @definition",
+    "method": "(field_declaration_list [(field_declaration declarator: (function_declarator declarator: (_) @name)) @definition (declaration declarator: (function_declarator declarator: (_) @name)) @definition])"
+}
@@ -1,11 +1,11 @@
 {
     "file_scoped_namespace": "(file_scoped_namespace_declaration name: (identifier)? @name.file_scoped_namespace name: (qualified_name)? @name)",
     "namespace": "(namespace_declaration name: (qualified_name)? @name.namespace  name: (identifier)? @name)",
-    "class": "(class_declaration name: (identifier) @name)",
-    "interface": "(interface_declaration name: (identifier) @name)",
-    "method": "(method_declaration name: (identifier) @name)",
-    "enum": "(enum_declaration name: (identifier) @name)",
-    "struct": "(struct_declaration name: (identifier) @name)",
-    "record": "(record_declaration name: (identifier) @name)",
-    "property": "(property_declaration name: (identifier) @name)"
-}
\ No newline at end of file
+    "class": "(class_declaration name: (identifier) @name) @definition",
+    "interface": "(interface_declaration name: (identifier) @name) @definition",
+    "method": "(method_declaration name: (identifier) @name) @definition",
+    "enum": "(enum_declaration name: (identifier) @name) @definition",
+    "struct": "(struct_declaration name: (identifier) @name) @definition",
+    "record": "(record_declaration name: (identifier) @name) @definition",
+    "property": "(property_declaration name: (identifier) @name) @definition"
+}
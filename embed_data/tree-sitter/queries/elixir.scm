@@ -0,0 +1,6 @@
+{
+    "module": "(call target: (identifier) @_keyword (arguments (alias) @name) (#eq? @_keyword \"defmodule\"))",
+    "protocol": "(call target: (identifier) @_keyword (arguments (alias) @name) (#eq? @_keyword \"defprotocol\")) @definition",
+    "function": "(call target: (identifier) @_keyword (arguments [(identifier) @name (call target: (identifier) @name) (binary_operator left: (call target: (identifier) @name))]) (#match? @_keyword \"^defp?$\")) @definition",
+    "macro": "(call target: (identifier) @_keyword (arguments [(identifier) @name (call target: (identifier) @name)]) (#match? @_keyword \"^defmacrop?$\")) @definition"
+}
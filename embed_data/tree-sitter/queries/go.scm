@@ -1,8 +1,7 @@
 {
     "package": "(package_clause (package_identifier) @name)",
-	"function": "(function_declaration name: (identifier) @name)",
-	"method": "(method_declaration name: (field_identifier) @name)",
-    "interface": "(type_declaration (type_spec name: (type_identifier) @name type: (interface_type)))",
-    "struct": "(type_declaration (type_spec name: name: (type_identifier) @name type: (struct_type)))"
+    "function": "(function_declaration name: (identifier) @name) @definition",
+    "method": "(method_declaration name: (field_identifier) @name) @definition",
+    "interface": "(type_declaration (type_spec name: (type_identifier) @name type: (interface_type))) @definition",
+    "struct": "(type_declaration (type_spec name: (type_identifier) @name type: (struct_type))) @definition"
 }
-
@@ -1,7 +1,7 @@
 {
     "package": "(package_declaration (scoped_identifier name: (identifier) @name))",
-    "class": "(class_declaration name: (identifier) @name)",
-    "interface": "(interface_declaration name: (identifier) @name)",
-    "method": "(method_declaration name: (identifier) @name)",
-    "enum": "(enum_declaration name: (identifier) @name)"
-}
\ No newline at end of file
+    "class": "(class_declaration name: (identifier) @name) @definition",
+    "interface": "(interface_declaration name: (identifier) @name) @definition",
+    "method": "(method_declaration name: (identifier) @name) @definition",
+    "enum": "(enum_declaration name: (identifier) @name) @definition"
+}
@@ -1,7 +1,6 @@
 {
-     "class" : "(class_declaration name: (identifier) @name)",
-     "method": "(method_definition name: (property_identifier) @name)",
-     "function": "(function_declaration name: (identifier) @name)",
-     "anonymous_function": "(lexical_declaration(variable_declarator name: (identifier) @name))"
+     "class" : "(class_declaration name: (identifier) @name) @definition",
+     "method": "(method_definition name: (property_identifier) @name) @definition",
+     "function": "(function_declaration name: (identifier) @name) @definition",
+     "anonymous_function": "(lexical_declaration(variable_declarator name: (identifier) @name)) @definition"
 }
-
@@ -0,0 +1,6 @@
+{
+    "package": "(package_header (identifier) @name)",
+    "class": "(class_declaration (type_identifier) @name) @definition",
+    "object": "(object_declaration (type_identifier) @name) @definition",
+    "function": "(function_declaration (simple_identifier) @name) @definition"
+}
@@ -0,0 +1,4 @@
+{
+    "function": "(function_statement name: (function_name) @name) @definition",
+    "local_function": "(function_statement (local) name: (identifier) @name) @definition"
+}
@@ -0,0 +1,9 @@
+{
+    "namespace": "(namespace_definition name: (namespace_name) @name)",
+    "class": "(class_declaration name: (name) @name) @definition",
+    "interface": "(interface_declaration name: (name) @name) @definition",
+    "trait": "(trait_declaration name: (name) @name) @definition",
+    "enum": "(enum_declaration name: (name) @name) @definition",
+    "function": "(function_definition name: (name) @name) @definition",
+    "method": "(method_declaration name: (name) @name) @definition"
+}
@@ -1,5 +1,5 @@
 {
-     "class": "(class_definition name: (identifier) @name)",
-     "function": "(function_definition name: (identifier) @name)",
+     "class": "(class_definition name: (identifier) @name) @definition",
+     "function": "(function_definition name: (identifier) @name) @definition",
      "decorated" : "(decorated_definition(decorator((identifier) @name)))"
-}
\ No newline at end of file
+}
@@ -0,0 +1,6 @@
+{
+    "module": "(module name: [(constant) (scope_resolution)] @name)",
+    "class": "(class name: [(constant) (scope_resolution)] @name) @definition",
+    "method": "(method name: (_) @name) @definition",
+    "singleton_method": "(singleton_method name: (_) @name) @definition"
+}
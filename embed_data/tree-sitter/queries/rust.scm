@@ -0,0 +1,10 @@
+{
+    "module": "(mod_item name: (identifier) @name)",
+    "struct": "(struct_item name: (type_identifier) @name) @definition",
+    "enum": "(enum_item name: (type_identifier) @name) @definition",
+    "trait": "(trait_item name: (type_identifier) @name) @definition",
+    "impl": "(impl_item type: [(type_identifier) (generic_type) (scoped_type_identifier)] @name) @definition",
+    "type": "(type_item name: (type_identifier) @name) @definition",
+    "function": "(function_item name: (identifier) @name) @definition",
+    "macro": "(macro_definition name: (identifier) @name) @definition"
+}
@@ -0,0 +1,7 @@
+{
+    "package": "(package_clause name: (package_identifier) @name)",
+    "class": "(class_definition name: (identifier) @name) @definition",
+    "object": "(object_definition name: (identifier) @name) @definition",
+    "trait": "(trait_definition name: (identifier) @name) @definition",
+    "function": "(function_definition name: (identifier) @name) @definition"
+}
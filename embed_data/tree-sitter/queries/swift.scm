@@ -0,0 +1,6 @@
+{
+    "class": "(class_declaration name: (type_identifier) @name) @definition",
+    "extension": "(class_declaration name: (user_type (type_identifier) @name)) @definition",
+    "protocol": "(protocol_declaration name: (type_identifier) @name) @definition",
+    "function": "(function_declaration name: (simple_identifier) @name) @definition"
+}
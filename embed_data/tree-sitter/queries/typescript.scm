@@ -1,8 +1,8 @@
 {
-     "class" : "(class_declaration name: ((type_identifier) @name))",
-     "interface": "(interface_declaration name: ((type_identifier) @name))",
+     "class" : "(class_declaration name: ((type_identifier) @name)) @definition",
+     "interface": "(interface_declaration name: ((type_identifier) @name)) @definition",
      "enum": "(enum_declaration name: ((identifier) @name)) @definition.enum",
-     "method": "(method_definition name: ((property_identifier) @name))",
-     "function": "(function_declaration name: ((identifier) @name))",
-     "anonymous_function": "(lexical_declaration(variable_declarator name: (identifier) @name))"
-}
\ No newline at end of file
+     "method": "(method_definition name: ((property_identifier) @name)) @definition",
+     "function": "(function_declaration name: ((identifier) @name)) @definition",
+     "anonymous_function": "(lexical_declaration(variable_declarator name: (identifier) @name)) @definition"
+}
@@ -23,6 +23,7 @@ type AIProviderConfig struct {
 	Stream          bool     `mapstructure:"stream"`
 	Temperature     *float32 `mapstructure:"temperature"`
 	ReasoningEffort *string  `mapstructure:"reasoning_effort"`
+	ThinkingBudget  *int     `mapstructure:"thinking_budget"`
 	EncodingFormat  string   `mapstructure:"encoding_format"`
 	MaxTokens       int      `mapstructure:"max_tokens"`
 	ApiKey          string   `mapstructure:"api_key"`
@@ -38,6 +39,7 @@ func ChatProviderFactory(config *AIProviderConfig, tokenManagement contracts2.IT
 			ReasoningEffort: config.ReasoningEffort,
 			EncodingFormat:  config.EncodingFormat,
 			Model:           config.Model,
+			Stream:          config.Stream,
 			BaseURL:         config.BaseURL,
 			MaxTokens:       config.MaxTokens,
 			TokenManagement: tokenManagement,
@@ -48,6 +50,7 @@ func ChatProviderFactory(config *AIProviderConfig, tokenManagement contracts2.IT
 			ReasoningEffort: config.ReasoningEffort,
 			EncodingFormat:  config.EncodingFormat,
 			Model:           config.Model,
+			Stream:          config.Stream,
 			BaseURL:         config.BaseURL,
 			ApiKey:          config.ApiKey,
 			MaxTokens:       config.MaxTokens,
@@ -59,6 +62,7 @@ func ChatProviderFactory(config *AIProviderConfig, tokenManagement contracts2.IT
 			ReasoningEffort: config.ReasoningEffort,
 			EncodingFormat:  config.EncodingFormat,
 			Model:           config.Model,
+			Stream:          config.Stream,
 			BaseURL:         config.BaseURL,
 			ApiKey:          config.ApiKey,
 			MaxTokens:       config.MaxTokens,
@@ -71,6 +75,7 @@ func ChatProviderFactory(config *AIProviderConfig, tokenManagement contracts2.IT
 			ReasoningEffort: config.ReasoningEffort,
 			EncodingFormat:  config.EncodingFormat,
 			Model:           config.Model,
+			Stream:          config.Stream,
 			BaseURL:         config.BaseURL,
 			ApiKey:          config.ApiKey,
 			MaxTokens:       config.MaxTokens,
@@ -84,6 +89,7 @@ func ChatProviderFactory(config *AIProviderConfig, tokenManagement contracts2.IT
 			ReasoningEffort: config.ReasoningEffort,
 			EncodingFormat:  config.EncodingFormat,
 			Model:           config.Model,
+			Stream:          config.Stream,
 			BaseURL:         config.BaseURL,
 			ApiKey:          config.ApiKey,
 			MaxTokens:       config.MaxTokens,
@@ -94,8 +100,10 @@ func ChatProviderFactory(config *AIProviderConfig, tokenManagement contracts2.IT
 	case "anthropic":
 		return anthropic.NewAnthropicMessageProvider(&anthropic.AnthropicConfig{
 			Temperature:     config.Temperature,
+			ThinkingBudget:  config.ThinkingBudget,
 			EncodingFormat:  config.EncodingFormat,
 			Model:           config.Model,
+			Stream:          config.Stream,
 			BaseURL:         config.BaseURL,
 			ApiKey:          config.ApiKey,
 			MaxTokens:       config.MaxTokens,
@@ -106,7 +114,9 @@ func ChatProviderFactory(config *AIProviderConfig, tokenManagement contracts2.IT
 	case "gemini":
 		return gemini.NewGeminiChatProvider(&gemini.GeminiConfig{
 			Temperature:     config.Temperature,
+			ThinkingBudget:  config.ThinkingBudget,
 			Model:           config.Model,
+			Stream:          config.Stream,
 			BaseURL:         config.BaseURL,
 			ApiKey:          config.ApiKey,
 			MaxTokens:       config.MaxTokens,
@@ -117,6 +127,7 @@ func ChatProviderFactory(config *AIProviderConfig, tokenManagement contracts2.IT
 		return qwen.NewQwenChatProvider(&qwen.QwenConfig{
 			Temperature:     config.Temperature,
 			Model:           config.Model,
+			Stream:          config.Stream,
 			BaseURL:         config.BaseURL,
 			ApiKey:          config.ApiKey,
 			MaxTokens:       config.MaxTokens,
@@ -127,6 +138,7 @@ func ChatProviderFactory(config *AIProviderConfig, tokenManagement contracts2.IT
 		return mistral.NewMistralChatProvider(&mistral.MistralConfig{
 			Temperature:     config.Temperature,
 			Model:           config.Model,
+			Stream:          config.Stream,
 			BaseURL:         config.BaseURL,
 			ApiKey:          config.ApiKey,
 			MaxTokens:       config.MaxTokens,
@@ -137,6 +149,7 @@ func ChatProviderFactory(config *AIProviderConfig, tokenManagement contracts2.IT
 		return grok.NewGrokChatProvider(&grok.GrokConfig{
 			Temperature:     config.Temperature,
 			Model:           config.Model,
+			Stream:          config.Stream,
 			BaseURL:         config.BaseURL,
 			ApiKey:          config.ApiKey,
 			MaxTokens:       config.MaxTokens,
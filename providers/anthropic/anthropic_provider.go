@@ -21,7 +21,9 @@ import (
 type AnthropicConfig struct {
 	BaseURL         string
 	Model           string
+	Stream          bool
 	Temperature     *float32
+	ThinkingBudget  *int
 	EncodingFormat  string
 	ApiKey          string
 	MaxTokens       int
@@ -30,7 +32,8 @@ type AnthropicConfig struct {
 }
 
 const (
-	defaultBaseURL = "https://api.anthropic.com/v1"
+	defaultBaseURL   = "https://api.anthropic.com/v1"
+	defaultMaxTokens = 8192
 )
 
 // NewAnthropicMessageProvider initializes a new OpenAPIProvider.
@@ -43,7 +46,9 @@ func NewAnthropicMessageProvider(config *AnthropicConfig) contracts.IChatAIProvi
 	return &AnthropicConfig{
 		BaseURL:         config.BaseURL,
 		Model:           config.Model,
+		Stream:          config.Stream,
 		Temperature:     config.Temperature,
+		ThinkingBudget:  config.ThinkingBudget,
 		EncodingFormat:  config.EncodingFormat,
 		MaxTokens:       config.MaxTokens,
 		ApiKey:          config.ApiKey,
@@ -52,23 +57,54 @@ func NewAnthropicMessageProvider(config *AnthropicConfig) contracts.IChatAIProvi
 	}
 }
 
-func (anthropicProvider *AnthropicConfig) ChatCompletionRequest(ctx context.Context, userInput string, prompt string) <-chan general_models.StreamResponse {
+func (anthropicProvider *AnthropicConfig) ChatCompletionRequest(ctx context.Context, userInput string, prompt string, images []general_models.Image) <-chan general_models.StreamResponse {
 	responseChan := make(chan general_models.StreamResponse)
-	var markdownBuffer strings.Builder // Accumulate content for streaming responses
-	var usage models.Usage             // To track token usage
+	var markdownBuffer strings.Builder  // Accumulate content for streaming responses
+	var reasoningBuffer strings.Builder // Accumulate thinking for streaming responses
+	var usage models.Usage              // To track token usage
 
 	go func() {
 		defer close(responseChan)
 
+		// Attach images as content blocks before the text of the user message for vision models
+		var userContent any = userInput
+		if len(images) > 0 {
+			var blocks []models.MessageContent
+			for _, image := range images {
+				blocks = append(blocks, models.MessageContent{
+					Type:   "image",
+					Source: &models.ImageSource{Type: "base64", MediaType: image.MediaType, Data: image.Data},
+				})
+			}
+			userContent = append(blocks, models.MessageContent{Type: "text", Text: userInput})
+		}
+
 		// Prepare the request body
+		// The system prompt holds the stable prefix, the template and the context of project, cached up to its breakpoint
 		reqBody := models.AnthropicMessageRequest{
+			System: []models.SystemBlock{
+				{Type: "text", Text: prompt, CacheControl: &models.CacheControl{Type: "ephemeral"}},
+			},
 			Messages: []models.Message{
-				{Role: "system", Content: prompt},
-				{Role: "user", Content: userInput},
+				{Role: "user", Content: userContent},
 			},
 			Model:       anthropicProvider.Model,
 			Temperature: anthropicProvider.Temperature,
-			Stream:      true,
+			Stream:      anthropicProvider.Stream,
+			MaxTokens:   anthropicProvider.MaxTokens,
+		}
+
+		if reqBody.MaxTokens <= 0 {
+			reqBody.MaxTokens = defaultMaxTokens
+		}
+
+		// Enable extended thinking, the budget must stay below max tokens and temperature is not supported with it
+		if anthropicProvider.ThinkingBudget != nil && *anthropicProvider.ThinkingBudget > 0 {
+			reqBody.Thinking = &models.Thinking{Type: "enabled", BudgetTokens: *anthropicProvider.ThinkingBudget}
+			reqBody.Temperature = nil
+			if reqBody.MaxTokens <= reqBody.Thinking.BudgetTokens {
+				reqBody.MaxTokens = reqBody.Thinking.BudgetTokens + defaultMaxTokens
+			}
 		}
 
 		jsonData, err := json.Marshal(reqBody)
@@ -114,6 +150,32 @@ func (anthropicProvider *AnthropicConfig) ChatCompletionRequest(ctx context.Cont
 			return
 		}
 
+		// Return the full message at once when streaming is disabled
+		if !anthropicProvider.Stream {
+			var response models.AnthropicMessageResponse
+			if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+				responseChan <- general_models.StreamResponse{Err: fmt.Errorf("error decoding response: %v", err)}
+				return
+			}
+
+			// Count total tokens usage
+			if response.Usage != nil {
+				anthropicProvider.countTokens(*response.Usage)
+			}
+
+			for _, block := range response.Content {
+				switch block.Type {
+				case "thinking":
+					responseChan <- general_models.StreamResponse{ReasoningContent: block.Thinking}
+				case "text":
+					markdownBuffer.WriteString(block.Text)
+				}
+			}
+			responseChan <- general_models.StreamResponse{Content: markdownBuffer.String()}
+			responseChan <- general_models.StreamResponse{Done: true}
+			return
+		}
+
 		// Process the streaming response
 		reader := bufio.NewReader(resp.Body)
 		for {
@@ -143,27 +205,54 @@ func (anthropicProvider *AnthropicConfig) ChatCompletionRequest(ctx context.Cont
 				// Handle content and final message updates
 				switch response.Type {
 				case "content_block_delta":
+					if response.Delta.Type == "thinking_delta" {
+						reasoningBuffer.WriteString(response.Delta.Thinking)
+						if strings.Contains(response.Delta.Thinking, "\n") {
+							responseChan <- general_models.StreamResponse{ReasoningContent: reasoningBuffer.String()}
+							reasoningBuffer.Reset()
+						}
+					}
 					if response.Delta.Type == "text_delta" {
+						// Flush the rest of the thinking once the answer starts
+						if reasoningBuffer.Len() > 0 {
+							responseChan <- general_models.StreamResponse{ReasoningContent: reasoningBuffer.String()}
+							reasoningBuffer.Reset()
+						}
 						markdownBuffer.WriteString(response.Delta.Text)
 						if strings.Contains(response.Delta.Text, "\n") {
 							responseChan <- general_models.StreamResponse{Content: markdownBuffer.String()}
 							markdownBuffer.Reset()
 						}
 					}
+				case "message_start":
+					if response.Message != nil && response.Message.Usage != nil {
+						usage = *response.Message.Usage // Input and cache tokens are only sent at the start
+					}
 				case "message_delta":
 					if response.Usage != nil {
-						usage = *response.Usage // Capture usage details
+						usage.OutputTokens = response.Usage.OutputTokens // Output tokens are cumulative
 					}
 				case "message_stop":
-					responseChan <- general_models.StreamResponse{Content: markdownBuffer.String(), Done: true}
-					if usage.TotalTokens > 0 {
-						anthropicProvider.TokenManagement.UsedTokens(usage.InputTokens, usage.OutputTokens)
+					// Send the reasoning left when the stream ends before any answer or newline
+					if reasoningBuffer.Len() > 0 {
+						responseChan <- general_models.StreamResponse{ReasoningContent: reasoningBuffer.String()}
+						reasoningBuffer.Reset()
 					}
+
+					// Count the tokens before ending the answer, so the turn usage includes them
+					anthropicProvider.countTokens(usage)
+					responseChan <- general_models.StreamResponse{Content: markdownBuffer.String(), Done: true}
 					return
 				}
 			}
 		}
 
+		// Send the reasoning left when the stream ends before any answer or newline
+		if reasoningBuffer.Len() > 0 {
+			responseChan <- general_models.StreamResponse{ReasoningContent: reasoningBuffer.String()}
+			reasoningBuffer.Reset()
+		}
+
 		// Send any remaining content in the buffer
 		if markdownBuffer.Len() > 0 {
 			responseChan <- general_models.StreamResponse{Content: markdownBuffer.String()}
@@ -172,3 +261,16 @@ func (anthropicProvider *AnthropicConfig) ChatCompletionRequest(ctx context.Cont
 
 	return responseChan
 }
+
+// countTokens counts the tokens of a response, the input tokens of Anthropic exclude the ones read from and written
+// to the prompt cache, so they are added to get the whole input.
+func (anthropicProvider *AnthropicConfig) countTokens(usage models.Usage) {
+	inputTokens := usage.InputTokens + usage.CacheReadInputTokens + usage.CacheCreationInputTokens
+	if inputTokens == 0 && usage.OutputTokens == 0 {
+		return
+	}
+
+	anthropicProvider.TokenManagement.UsedTokens(inputTokens, usage.OutputTokens)
+	anthropicProvider.TokenManagement.UsedCachedTokens(usage.CacheReadInputTokens)
+	anthropicProvider.TokenManagement.UsedCacheWriteTokens(usage.CacheCreationInputTokens)
+}
@@ -2,14 +2,49 @@ package models
 
 // AnthropicMessageRequest represents the request body for Anthropic message.
 type AnthropicMessageRequest struct {
-	Model       string    `json:"model"`                 // Model ID, e.g., "claude-3-5-sonnet-latest"
-	Messages    []Message `json:"messages"`              // Array of message history
-	Temperature *float32  `json:"temperature,omitempty"` // Sampling temperature (0.0-1.0)
-	Stream      bool      `json:"stream,omitempty"`      // Enable/disable streaming
+	Model       string        `json:"model"`                 // Model ID, e.g., "claude-3-5-sonnet-latest"
+	System      []SystemBlock `json:"system,omitempty"`      // System prompt as content blocks, marked for prompt caching
+	Messages    []Message     `json:"messages"`              // Array of message history
+	Temperature *float32      `json:"temperature,omitempty"` // Sampling temperature (0.0-1.0)
+	Stream      bool          `json:"stream,omitempty"`      // Enable/disable streaming
+	MaxTokens   int           `json:"max_tokens,omitempty"`  // Maximum number of tokens to generate
+	Thinking    *Thinking     `json:"thinking,omitempty"`    // Extended thinking configuration
+}
+
+// SystemBlock represents a text block of the system prompt.
+type SystemBlock struct {
+	Type         string        `json:"type"`                    // Always "text"
+	Text         string        `json:"text"`                    // Text of the block
+	CacheControl *CacheControl `json:"cache_control,omitempty"` // Breakpoint caching the prompt up to this block
+}
+
+// CacheControl marks the end of a prompt prefix cached by Anthropic.
+type CacheControl struct {
+	Type string `json:"type"` // Always "ephemeral"
+}
+
+// Thinking enables extended thinking with a token budget for reasoning.
+type Thinking struct {
+	Type         string `json:"type"`          // Always "enabled"
+	BudgetTokens int    `json:"budget_tokens"` // Tokens the model may spend on thinking, must be less than max_tokens
 }
 
 // Message Define the request body structure
 type Message struct {
 	Role    string `json:"role"`    // Valid roles: "system", "user", "assistant"
-	Content string `json:"content"` // The text content for this message
+	Content any    `json:"content"` // The text content for this message or a list of content blocks
+}
+
+// MessageContent represents a content block of a message, e.g., text or image.
+type MessageContent struct {
+	Type   string       `json:"type"`             // Type of the block, "text" or "image"
+	Text   string       `json:"text,omitempty"`   // Text of a "text" block
+	Source *ImageSource `json:"source,omitempty"` // Source of an "image" block
+}
+
+// ImageSource holds the base64 encoded data of an image block.
+type ImageSource struct {
+	Type      string `json:"type"`       // Always "base64"
+	MediaType string `json:"media_type"` // Media type of the image, e.g., "image/png"
+	Data      string `json:"data"`       // Base64 encoded content of the image
 }
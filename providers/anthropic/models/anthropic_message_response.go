@@ -2,10 +2,19 @@ package models
 
 // AnthropicMessageResponse represents the full response structure for Anthropic's chat completion API (streaming).
 type AnthropicMessageResponse struct {
-	Type    string   `json:"type"`              // Type of the response chunk, e.g., "message_start", "content_block_delta", etc.
-	Choices []Choice `json:"choices,omitempty"` // Array of choices for response content
-	Usage   *Usage   `json:"usage,omitempty"`   // Optional token usage details (appears in certain chunks)
-	Delta   *Delta   `json:"delta,omitempty"`   // Optional content updates or deltas
+	Type    string                    `json:"type"`              // Type of the response chunk, e.g., "message_start", "content_block_delta", etc.
+	Choices []Choice                  `json:"choices,omitempty"` // Array of choices for response content
+	Usage   *Usage                    `json:"usage,omitempty"`   // Optional token usage details (appears in certain chunks)
+	Delta   *Delta                    `json:"delta,omitempty"`   // Optional content updates or deltas
+	Content []ContentBlock            `json:"content,omitempty"` // Full content blocks when streaming is disabled
+	Message *AnthropicMessageResponse `json:"message,omitempty"` // Message of the "message_start" chunk, with the input tokens usage
+}
+
+// ContentBlock represents a block of the full message content, e.g., "text" or "thinking".
+type ContentBlock struct {
+	Type     string `json:"type"`               // Type of block, e.g., "text" or "thinking"
+	Text     string `json:"text,omitempty"`     // Text of a "text" block
+	Thinking string `json:"thinking,omitempty"` // Thinking of a "thinking" block
 }
 
 // Choice represents an individual choice in the response.
@@ -15,16 +24,18 @@ type Choice struct {
 
 // Delta represents the streamed content or updates.
 type Delta struct {
-	Type       string `json:"type,omitempty"`        // Type of delta, e.g., "text_delta"
+	Type       string `json:"type,omitempty"`        // Type of delta, e.g., "text_delta" or "thinking_delta"
 	Text       string `json:"text,omitempty"`        // Text content streamed in chunks
+	Thinking   string `json:"thinking,omitempty"`    // Thinking content streamed in chunks
 	StopReason string `json:"stop_reason,omitempty"` // Reason for stopping (e.g., "end_turn")
 }
 
 // Usage represents token usage details for Anthropic responses.
 type Usage struct {
-	InputTokens  int `json:"input_tokens"`  // Number of tokens in the input
-	OutputTokens int `json:"output_tokens"` // Number of tokens in the output
-	TotalTokens  int `json:"total_tokens"`  // Total tokens used
+	InputTokens              int `json:"input_tokens"`                // Number of tokens in the input after the last cache breakpoint
+	OutputTokens             int `json:"output_tokens"`               // Number of tokens in the output
+	CacheCreationInputTokens int `json:"cache_creation_input_tokens"` // Number of input tokens written to the prompt cache
+	CacheReadInputTokens     int `json:"cache_read_input_tokens"`     // Number of input tokens read from the prompt cache
 }
 
 // AnthropicError represents the error response structure from Anthropic's API.
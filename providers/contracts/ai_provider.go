@@ -6,5 +6,5 @@ import (
 )
 
 type IChatAIProvider interface {
-	ChatCompletionRequest(ctx context.Context, userInput string, prompt string) <-chan models.StreamResponse
+	ChatCompletionRequest(ctx context.Context, userInput string, prompt string, images []models.Image) <-chan models.StreamResponse
 }
@@ -21,6 +21,7 @@ import (
 type DeepSeekConfig struct {
 	BaseURL         string
 	Model           string
+	Stream          bool
 	Temperature     *float32
 	ReasoningEffort *string
 	EncodingFormat  string
@@ -44,6 +45,7 @@ func NewDeepSeekChatProvider(config *DeepSeekConfig) contracts.IChatAIProvider {
 	return &DeepSeekConfig{
 		BaseURL:         config.BaseURL,
 		Model:           config.Model,
+		Stream:          config.Stream,
 		Temperature:     config.Temperature,
 		ReasoningEffort: config.ReasoningEffort,
 		EncodingFormat:  config.EncodingFormat,
@@ -53,14 +55,20 @@ func NewDeepSeekChatProvider(config *DeepSeekConfig) contracts.IChatAIProvider {
 		TokenManagement: config.TokenManagement,
 	}
 }
-func (deepSeekProvider *DeepSeekConfig) ChatCompletionRequest(ctx context.Context, userInput string, prompt string) <-chan models.StreamResponse {
+func (deepSeekProvider *DeepSeekConfig) ChatCompletionRequest(ctx context.Context, userInput string, prompt string, images []models.Image) <-chan models.StreamResponse {
 	responseChan := make(chan models.StreamResponse)
-	var markdownBuffer strings.Builder // Buffer to accumulate content until newline
-	var usage deepseek_models.Usage    // Variable to hold usage data
+	var markdownBuffer strings.Builder  // Buffer to accumulate content until newline
+	var reasoningBuffer strings.Builder // Buffer to accumulate reasoning until newline
+	var usage deepseek_models.Usage     // Variable to hold usage data
 
 	go func() {
 		defer close(responseChan)
 
+		if len(images) > 0 {
+			responseChan <- models.StreamResponse{Err: fmt.Errorf("image input is not supported by the 'deepseek' provider")}
+			return
+		}
+
 		// Prepare the request body
 		reqBody := deepseek_models.DeepSeekChatCompletionRequest{
 			Model: deepSeekProvider.Model,
@@ -68,7 +76,7 @@ func (deepSeekProvider *DeepSeekConfig) ChatCompletionRequest(ctx context.Contex
 				{Role: "system", Content: prompt},
 				{Role: "user", Content: userInput},
 			},
-			Stream:          true,
+			Stream:          deepSeekProvider.Stream,
 			Temperature:     deepSeekProvider.Temperature,
 			ReasoningEffort: deepSeekProvider.ReasoningEffort,
 		}
@@ -117,6 +125,32 @@ func (deepSeekProvider *DeepSeekConfig) ChatCompletionRequest(ctx context.Contex
 			return
 		}
 
+		// Return the full completion at once when streaming is disabled
+		if !deepSeekProvider.Stream {
+			var response deepseek_models.DeepSeekChatCompletionResponse
+			if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+				responseChan <- models.StreamResponse{Err: fmt.Errorf("error decoding response: %v", err)}
+				return
+			}
+
+			// Count total tokens usage
+			if response.Usage.TotalTokens > 0 {
+				deepSeekProvider.TokenManagement.UsedTokens(response.Usage.PromptTokens, response.Usage.CompletionTokens)
+				deepSeekProvider.TokenManagement.UsedReasoningTokens(response.Usage.CompletionTokensDetails.ReasoningTokens)
+				deepSeekProvider.TokenManagement.UsedCachedTokens(response.Usage.PromptCacheHitTokens)
+			}
+
+			if len(response.Choices) > 0 {
+				if response.Choices[0].Message.ReasoningContent != "" {
+					responseChan <- models.StreamResponse{ReasoningContent: response.Choices[0].Message.ReasoningContent}
+				}
+				responseChan <- models.StreamResponse{Content: response.Choices[0].Message.Content}
+			}
+
+			responseChan <- models.StreamResponse{Done: true}
+			return
+		}
+
 		reader := bufio.NewReader(resp.Body)
 
 		// Stream processing
@@ -130,14 +164,21 @@ func (deepSeekProvider *DeepSeekConfig) ChatCompletionRequest(ctx context.Contex
 						responseChan <- models.StreamResponse{Content: markdownBuffer.String()}
 					}
 
-					// Notify that the stream is done
-					responseChan <- models.StreamResponse{Done: true}
-
 					// Count total tokens usage
 					if usage.TotalTokens > 0 {
 						deepSeekProvider.TokenManagement.UsedTokens(usage.PromptTokens, usage.CompletionTokens)
+						deepSeekProvider.TokenManagement.UsedReasoningTokens(usage.CompletionTokensDetails.ReasoningTokens)
+						deepSeekProvider.TokenManagement.UsedCachedTokens(usage.PromptCacheHitTokens)
 					}
 
+					// Send the reasoning left when the stream ends before any answer or newline
+					if reasoningBuffer.Len() > 0 {
+						responseChan <- models.StreamResponse{ReasoningContent: reasoningBuffer.String()}
+						reasoningBuffer.Reset()
+					}
+					// Notify that the stream is done
+					responseChan <- models.StreamResponse{Done: true}
+
 					break
 				}
 				responseChan <- models.StreamResponse{Err: fmt.Errorf("error reading stream: %v", err)}
@@ -162,18 +203,33 @@ func (deepSeekProvider *DeepSeekConfig) ChatCompletionRequest(ctx context.Contex
 				if len(response.Choices) > 0 && response.Choices[0].FinishReason != "" {
 					// Stream completed for this choice
 					responseChan <- models.StreamResponse{Content: markdownBuffer.String()}
-					responseChan <- models.StreamResponse{Done: true}
-
 					// Count total tokens usage
 					if usage.TotalTokens > 0 {
 						deepSeekProvider.TokenManagement.UsedTokens(usage.PromptTokens, usage.CompletionTokens)
+						deepSeekProvider.TokenManagement.UsedReasoningTokens(usage.CompletionTokensDetails.ReasoningTokens)
+						deepSeekProvider.TokenManagement.UsedCachedTokens(usage.PromptCacheHitTokens)
 					}
 
+					// Send the reasoning left when the stream ends before any answer or newline
+					if reasoningBuffer.Len() > 0 {
+						responseChan <- models.StreamResponse{ReasoningContent: reasoningBuffer.String()}
+						reasoningBuffer.Reset()
+					}
+					responseChan <- models.StreamResponse{Done: true}
+
 					break
 				}
 
 				// Accumulate and send response content
 				if len(response.Choices) > 0 {
+					// Send reasoning apart from the answer and flush it once the answer starts
+					reasoning := response.Choices[0].Delta.ReasoningContent
+					reasoningBuffer.WriteString(reasoning)
+					if strings.Contains(reasoning, "\n") || (response.Choices[0].Delta.Content != "" && reasoningBuffer.Len() > 0) {
+						responseChan <- models.StreamResponse{ReasoningContent: reasoningBuffer.String()}
+						reasoningBuffer.Reset()
+					}
+
 					content := response.Choices[0].Delta.Content
 					markdownBuffer.WriteString(content)
 
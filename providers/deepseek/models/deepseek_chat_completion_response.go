@@ -9,17 +9,27 @@ type DeepSeekChatCompletionResponse struct {
 // Choice represents an individual choice in the response.
 type Choice struct {
 	Delta        Delta  `json:"delta"`
+	Message      Delta  `json:"message"`       // Full message when streaming is disabled
 	FinishReason string `json:"finish_reason"` // Check for completion
 }
 
 // Delta represents the delta object in each choice containing the content.
 type Delta struct {
-	Content string `json:"content"`
+	Content          string `json:"content"`
+	ReasoningContent string `json:"reasoning_content"` // Chain of thought returned by 'deepseek-reasoner'
 }
 
 // Usage defines the token usage information for the response.
 type Usage struct {
-	PromptTokens     int `json:"prompt_tokens"`     // Number of tokens in the prompt
-	CompletionTokens int `json:"completion_tokens"` // Number of tokens in the completion
-	TotalTokens      int `json:"total_tokens"`      // Total tokens used
+	PromptTokens            int                     `json:"prompt_tokens"`             // Number of tokens in the prompt
+	CompletionTokens        int                     `json:"completion_tokens"`         // Number of tokens in the completion
+	TotalTokens             int                     `json:"total_tokens"`              // Total tokens used
+	CompletionTokensDetails CompletionTokensDetails `json:"completion_tokens_details"` // Breakdown of the completion tokens
+	PromptCacheHitTokens    int                     `json:"prompt_cache_hit_tokens"`   // Number of prompt tokens read from the prompt cache
+	PromptCacheMissTokens   int                     `json:"prompt_cache_miss_tokens"`  // Number of prompt tokens not found in the prompt cache
+}
+
+// CompletionTokensDetails defines the breakdown of the completion tokens.
+type CompletionTokensDetails struct {
+	ReasoningTokens int `json:"reasoning_tokens"` // Number of tokens used for reasoning
 }
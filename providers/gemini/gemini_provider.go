@@ -1,6 +1,7 @@
 package gemini
 
 import (
+	"bufio"
 	"bytes"
 	"context"
 	"encoding/json"
@@ -19,7 +20,9 @@ import (
 type GeminiConfig struct {
 	BaseURL         string
 	Model           string
+	Stream          bool
 	Temperature     *float32
+	ThinkingBudget  *int
 	MaxTokens       int
 	ApiKey          string
 	TokenManagement contracts2.ITokenManagement
@@ -37,32 +40,45 @@ func NewGeminiChatProvider(config *GeminiConfig) contracts.IChatAIProvider {
 		baseURL = defaultBaseURL
 	}
 	return &GeminiConfig{
-		BaseURL:         config.BaseURL,
+		BaseURL:         baseURL,
 		Model:           config.Model,
+		Stream:          config.Stream,
 		Temperature:     config.Temperature,
+		ThinkingBudget:  config.ThinkingBudget,
 		MaxTokens:       config.MaxTokens,
 		ApiKey:          config.ApiKey,
 		TokenManagement: config.TokenManagement,
 	}
 }
 
-func (geminiProvider *GeminiConfig) ChatCompletionRequest(ctx context.Context, userInput string, prompt string) <-chan models.StreamResponse {
+func (geminiProvider *GeminiConfig) ChatCompletionRequest(ctx context.Context, userInput string, prompt string, images []models.Image) <-chan models.StreamResponse {
 	responseChan := make(chan models.StreamResponse)
-	var markdownBuffer strings.Builder
+	var markdownBuffer strings.Builder  // Buffer to accumulate content until newline
+	var reasoningBuffer strings.Builder // Buffer to accumulate thoughts until newline
+	var usage gemini_models.UsageMetadata
 
 	go func() {
 		defer close(responseChan)
 
-		// Combine system prompt and user input into a single user message
-		fullContent := fmt.Sprintf("%s\n\n%s", prompt, userInput)
+		// Attach images as inline data parts of the user turn for vision models
+		userParts := []gemini_models.Part{{Text: userInput}}
+		for _, image := range images {
+			userParts = append(userParts, gemini_models.Part{
+				InlineData: &gemini_models.InlineData{MimeType: image.MediaType, Data: image.Data},
+			})
+		}
 
+		// Send the prompt as system instruction and keep the user turn for the user input
 		reqBody := gemini_models.GeminiChatCompletionRequest{
+			SystemInstruction: &gemini_models.Content{
+				Parts: []gemini_models.Part{
+					{Text: prompt},
+				},
+			},
 			Contents: []gemini_models.Content{
 				{
-					Role: "user",
-					Parts: []gemini_models.Part{
-						{Text: fullContent},
-					},
+					Role:  "user",
+					Parts: userParts,
 				},
 			},
 			GenerationConfig: &gemini_models.GenerationConfig{
@@ -71,25 +87,34 @@ func (geminiProvider *GeminiConfig) ChatCompletionRequest(ctx context.Context, u
 			},
 		}
 
+		// Ask thinking models to return their thoughts apart from the answer
+		if geminiProvider.ThinkingBudget != nil && *geminiProvider.ThinkingBudget > 0 {
+			reqBody.GenerationConfig.ThinkingConfig = &gemini_models.ThinkingConfig{
+				IncludeThoughts: true,
+				ThinkingBudget:  *geminiProvider.ThinkingBudget,
+			}
+		}
+
 		jsonData, err := json.Marshal(reqBody)
 		if err != nil {
 			responseChan <- models.StreamResponse{Err: fmt.Errorf("error marshalling request body: %v", err)}
 			return
 		}
 
-		req, err := http.NewRequestWithContext(
-			ctx,
-			"POST",
-			fmt.Sprintf("%s/models/%s:generateContent", geminiProvider.BaseURL, geminiProvider.Model),
-			bytes.NewBuffer(jsonData),
-		)
+		// Use the server-sent events endpoint for streaming, otherwise wait for the full answer
+		endpoint := fmt.Sprintf("%s/models/%s:streamGenerateContent?alt=sse", geminiProvider.BaseURL, geminiProvider.Model)
+		if !geminiProvider.Stream {
+			endpoint = fmt.Sprintf("%s/models/%s:generateContent", geminiProvider.BaseURL, geminiProvider.Model)
+		}
+
+		req, err := http.NewRequestWithContext(ctx, "POST", endpoint, bytes.NewBuffer(jsonData))
 		if err != nil {
 			responseChan <- models.StreamResponse{Err: fmt.Errorf("error creating request: %v", err)}
 			return
 		}
 
 		req.Header.Set("Content-Type", "application/json")
-		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", geminiProvider.ApiKey))
+		req.Header.Set("x-goog-api-key", geminiProvider.ApiKey)
 
 		client := &http.Client{}
 		resp, err := client.Do(req)
@@ -114,23 +139,113 @@ func (geminiProvider *GeminiConfig) ChatCompletionRequest(ctx context.Context, u
 			return
 		}
 
-		var fullResponse gemini_models.GeminiChatCompletionResponse
-		if err := json.NewDecoder(resp.Body).Decode(&fullResponse); err != nil {
-			responseChan <- models.StreamResponse{Err: fmt.Errorf("error decoding response: %v", err)}
+		// Return the full answer at once when streaming is disabled
+		if !geminiProvider.Stream {
+			var response gemini_models.GeminiChatCompletionResponse
+			if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+				responseChan <- models.StreamResponse{Err: fmt.Errorf("error decoding response: %v", err)}
+				return
+			}
+
+			// Count total tokens usage, thoughts are billed as output tokens
+			if response.UsageMetadata != nil {
+				usage = *response.UsageMetadata
+				geminiProvider.TokenManagement.UsedTokens(usage.PromptTokenCount, usage.CandidatesTokenCount+usage.ThoughtsTokenCount)
+				geminiProvider.TokenManagement.UsedReasoningTokens(usage.ThoughtsTokenCount)
+				geminiProvider.TokenManagement.UsedCachedTokens(usage.CachedContentTokenCount)
+			}
+
+			if len(response.Candidates) > 0 {
+				for _, part := range response.Candidates[0].Content.Parts {
+					if part.Thought {
+						responseChan <- models.StreamResponse{ReasoningContent: part.Text}
+						continue
+					}
+					markdownBuffer.WriteString(part.Text)
+				}
+			}
+			responseChan <- models.StreamResponse{Content: markdownBuffer.String()}
+			responseChan <- models.StreamResponse{Done: true}
 			return
 		}
 
-		if len(fullResponse.Candidates) > 0 && len(fullResponse.Candidates[0].Content.Parts) > 0 {
-			content := fullResponse.Candidates[0].Content.Parts[0].Text
-			markdownBuffer.WriteString(content)
+		reader := bufio.NewReader(resp.Body)
+
+		// Stream processing, each server-sent event carries a partial 'GenerateContentResponse'
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				if err == io.EOF {
+					break
+				}
+				responseChan <- models.StreamResponse{Err: fmt.Errorf("error reading stream: %v", err)}
+				return
+			}
+
+			if !strings.HasPrefix(line, "data: ") {
+				continue
+			}
+
+			jsonPart := strings.TrimPrefix(line, "data: ")
+			var response gemini_models.GeminiChatCompletionResponse
+			if err := json.Unmarshal([]byte(jsonPart), &response); err != nil {
+				responseChan <- models.StreamResponse{Err: fmt.Errorf("error unmarshalling chunk: %v", err)}
+				return
+			}
+
+			// Usage metadata is cumulative, so the last one holds the totals
+			if response.UsageMetadata != nil {
+				usage = *response.UsageMetadata
+			}
+
+			if len(response.Candidates) == 0 {
+				continue
+			}
+
+			for _, part := range response.Candidates[0].Content.Parts {
+				if part.Thought {
+					reasoningBuffer.WriteString(part.Text)
+
+					// Send thought chunk if it contains a newline, and then reset the buffer
+					if strings.Contains(part.Text, "\n") {
+						responseChan <- models.StreamResponse{ReasoningContent: reasoningBuffer.String()}
+						reasoningBuffer.Reset()
+					}
+					continue
+				}
+
+				// Flush the rest of the thoughts once the answer starts
+				if reasoningBuffer.Len() > 0 {
+					responseChan <- models.StreamResponse{ReasoningContent: reasoningBuffer.String()}
+					reasoningBuffer.Reset()
+				}
+
+				markdownBuffer.WriteString(part.Text)
+
+				// Send chunk if it contains a newline, and then reset the buffer
+				if strings.Contains(part.Text, "\n") {
+					responseChan <- models.StreamResponse{Content: markdownBuffer.String()}
+					markdownBuffer.Reset()
+				}
+			}
+		}
+
+		// Send the reasoning left when the stream ends before any answer or newline
+		if reasoningBuffer.Len() > 0 {
+			responseChan <- models.StreamResponse{ReasoningContent: reasoningBuffer.String()}
+			reasoningBuffer.Reset()
+		}
+
+		// Send any remaining content in the buffer
+		if markdownBuffer.Len() > 0 {
 			responseChan <- models.StreamResponse{Content: markdownBuffer.String()}
 		}
 
-		if fullResponse.UsageMetadata != nil {
-			geminiProvider.TokenManagement.UsedTokens(
-				fullResponse.UsageMetadata.PromptTokenCount,
-				fullResponse.UsageMetadata.CandidatesTokenCount,
-			)
+		// Count total tokens usage, thoughts are billed as output tokens
+		if usage.TotalTokens > 0 {
+			geminiProvider.TokenManagement.UsedTokens(usage.PromptTokenCount, usage.CandidatesTokenCount+usage.ThoughtsTokenCount)
+			geminiProvider.TokenManagement.UsedReasoningTokens(usage.ThoughtsTokenCount)
+			geminiProvider.TokenManagement.UsedCachedTokens(usage.CachedContentTokenCount)
 		}
 
 		responseChan <- models.StreamResponse{Done: true}
@@ -20,6 +20,7 @@ import (
 type GrokConfig struct {
 	BaseURL         string
 	Model           string
+	Stream          bool
 	Temperature     *float32
 	MaxTokens       int
 	ApiVersion      string
@@ -41,6 +42,7 @@ func NewGrokChatProvider(config *GrokConfig) contracts.IChatAIProvider {
 	return &GrokConfig{
 		BaseURL:         config.BaseURL,
 		Model:           config.Model,
+		Stream:          config.Stream,
 		Temperature:     config.Temperature,
 		MaxTokens:       config.MaxTokens,
 		ApiVersion:      config.ApiVersion,
@@ -49,14 +51,20 @@ func NewGrokChatProvider(config *GrokConfig) contracts.IChatAIProvider {
 	}
 }
 
-func (grokProvider *GrokConfig) ChatCompletionRequest(ctx context.Context, userInput string, prompt string) <-chan models.StreamResponse {
+func (grokProvider *GrokConfig) ChatCompletionRequest(ctx context.Context, userInput string, prompt string, images []models.Image) <-chan models.StreamResponse {
 	responseChan := make(chan models.StreamResponse)
 	var markdownBuffer strings.Builder
+	var reasoningBuffer strings.Builder
 	var usage grok_models.Usage
 
 	go func() {
 		defer close(responseChan)
 
+		if len(images) > 0 {
+			responseChan <- models.StreamResponse{Err: fmt.Errorf("image input is not supported by the 'grok' provider")}
+			return
+		}
+
 		reqBody := grok_models.GrokChatCompletionRequest{
 			Model: grokProvider.Model,
 			Messages: []grok_models.Message{
@@ -65,7 +73,7 @@ func (grokProvider *GrokConfig) ChatCompletionRequest(ctx context.Context, userI
 			},
 			Temperature: grokProvider.Temperature,
 			MaxTokens:   grokProvider.MaxTokens,
-			Stream:      true,
+			Stream:      grokProvider.Stream,
 		}
 
 		jsonData, err := json.Marshal(reqBody)
@@ -107,6 +115,32 @@ func (grokProvider *GrokConfig) ChatCompletionRequest(ctx context.Context, userI
 			return
 		}
 
+		// Return the full completion at once when streaming is disabled
+		if !grokProvider.Stream {
+			var response grok_models.GrokChatCompletionResponse
+			if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+				responseChan <- models.StreamResponse{Err: fmt.Errorf("error decoding response: %v", err)}
+				return
+			}
+
+			// Count total tokens usage
+			if response.Usage.TotalTokens > 0 {
+				grokProvider.TokenManagement.UsedTokens(response.Usage.PromptTokens, response.Usage.CompletionTokens)
+				grokProvider.TokenManagement.UsedReasoningTokens(response.Usage.CompletionTokensDetails.ReasoningTokens)
+				grokProvider.TokenManagement.UsedCachedTokens(response.Usage.PromptTokensDetails.CachedTokens)
+			}
+
+			if len(response.Choices) > 0 {
+				if response.Choices[0].Message.ReasoningContent != "" {
+					responseChan <- models.StreamResponse{ReasoningContent: response.Choices[0].Message.ReasoningContent}
+				}
+				responseChan <- models.StreamResponse{Content: response.Choices[0].Message.Content}
+			}
+
+			responseChan <- models.StreamResponse{Done: true}
+			return
+		}
+
 		reader := bufio.NewReader(resp.Body)
 
 		for {
@@ -132,6 +166,14 @@ func (grokProvider *GrokConfig) ChatCompletionRequest(ctx context.Context, userI
 				}
 
 				if len(response.Choices) > 0 {
+					// Send reasoning apart from the answer and flush it once the answer starts
+					reasoning := response.Choices[0].Delta.ReasoningContent
+					reasoningBuffer.WriteString(reasoning)
+					if strings.Contains(reasoning, "\n") || (response.Choices[0].Delta.Content != "" && reasoningBuffer.Len() > 0) {
+						responseChan <- models.StreamResponse{ReasoningContent: reasoningBuffer.String()}
+						reasoningBuffer.Reset()
+					}
+
 					content := response.Choices[0].Delta.Content
 					markdownBuffer.WriteString(content)
 
@@ -147,10 +189,18 @@ func (grokProvider *GrokConfig) ChatCompletionRequest(ctx context.Context, userI
 			responseChan <- models.StreamResponse{Content: markdownBuffer.String()}
 		}
 
-		responseChan <- models.StreamResponse{Done: true}
 		if usage.TotalTokens > 0 {
 			grokProvider.TokenManagement.UsedTokens(usage.PromptTokens, usage.CompletionTokens)
+			grokProvider.TokenManagement.UsedReasoningTokens(usage.CompletionTokensDetails.ReasoningTokens)
+			grokProvider.TokenManagement.UsedCachedTokens(usage.PromptTokensDetails.CachedTokens)
 		}
+
+		// Send the reasoning left when the stream ends before any answer or newline
+		if reasoningBuffer.Len() > 0 {
+			responseChan <- models.StreamResponse{ReasoningContent: reasoningBuffer.String()}
+			reasoningBuffer.Reset()
+		}
+		responseChan <- models.StreamResponse{Done: true}
 	}()
 
 	return responseChan
@@ -20,6 +20,7 @@ import (
 type MistralConfig struct {
 	BaseURL         string
 	Model           string
+	Stream          bool
 	Temperature     *float32
 	MaxTokens       int
 	ApiKey          string
@@ -40,6 +41,7 @@ func NewMistralChatProvider(config *MistralConfig) contracts.IChatAIProvider {
 	return &MistralConfig{
 		BaseURL:         config.BaseURL,
 		Model:           config.Model,
+		Stream:          config.Stream,
 		Temperature:     config.Temperature,
 		MaxTokens:       config.MaxTokens,
 		ApiKey:          config.ApiKey,
@@ -47,7 +49,7 @@ func NewMistralChatProvider(config *MistralConfig) contracts.IChatAIProvider {
 	}
 }
 
-func (mistralProvider *MistralConfig) ChatCompletionRequest(ctx context.Context, userInput string, prompt string) <-chan models.StreamResponse {
+func (mistralProvider *MistralConfig) ChatCompletionRequest(ctx context.Context, userInput string, prompt string, images []models.Image) <-chan models.StreamResponse {
 	responseChan := make(chan models.StreamResponse)
 	var markdownBuffer strings.Builder
 	var usage mistral_models.Usage
@@ -55,6 +57,11 @@ func (mistralProvider *MistralConfig) ChatCompletionRequest(ctx context.Context,
 	go func() {
 		defer close(responseChan)
 
+		if len(images) > 0 {
+			responseChan <- models.StreamResponse{Err: fmt.Errorf("image input is not supported by the 'mistral' provider")}
+			return
+		}
+
 		reqBody := mistral_models.MistralChatCompletionRequest{
 			Model: mistralProvider.Model,
 			Messages: []mistral_models.Message{
@@ -63,7 +70,7 @@ func (mistralProvider *MistralConfig) ChatCompletionRequest(ctx context.Context,
 			},
 			Temperature: mistralProvider.Temperature,
 			MaxTokens:   mistralProvider.MaxTokens,
-			Stream:      true,
+			Stream:      mistralProvider.Stream,
 		}
 
 		jsonData, err := json.Marshal(reqBody)
@@ -104,6 +111,27 @@ func (mistralProvider *MistralConfig) ChatCompletionRequest(ctx context.Context,
 			return
 		}
 
+		// Return the full completion at once when streaming is disabled
+		if !mistralProvider.Stream {
+			var response mistral_models.MistralChatCompletionResponse
+			if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+				responseChan <- models.StreamResponse{Err: fmt.Errorf("error decoding response: %v", err)}
+				return
+			}
+
+			// Count total tokens usage
+			if response.Usage.TotalTokens > 0 {
+				mistralProvider.TokenManagement.UsedTokens(response.Usage.PromptTokens, response.Usage.CompletionTokens)
+			}
+
+			if len(response.Choices) > 0 {
+				responseChan <- models.StreamResponse{Content: response.Choices[0].Message.Content}
+			}
+
+			responseChan <- models.StreamResponse{Done: true}
+			return
+		}
+
 		reader := bufio.NewReader(resp.Body)
 
 		for {
@@ -144,10 +172,11 @@ func (mistralProvider *MistralConfig) ChatCompletionRequest(ctx context.Context,
 			responseChan <- models.StreamResponse{Content: markdownBuffer.String()}
 		}
 
-		responseChan <- models.StreamResponse{Done: true}
 		if usage.TotalTokens > 0 {
 			mistralProvider.TokenManagement.UsedTokens(usage.PromptTokens, usage.CompletionTokens)
 		}
+
+		responseChan <- models.StreamResponse{Done: true}
 	}()
 
 	return responseChan
@@ -7,7 +7,8 @@ type MistralChatCompletionResponse struct {
 }
 
 type Choice struct {
-	Delta Delta `json:"delta"`
+	Delta   Delta `json:"delta"`
+	Message Delta `json:"message"`
 }
 
 type Delta struct {
@@ -1,9 +1,10 @@
 package models
 
 type StreamResponse struct {
-	Content string // Holds content chunks
-	Err     error  // Holds error details
-	Done    bool   // Signals end of stream
+	Content          string // Holds content chunks
+	ReasoningContent string // Holds reasoning (thinking) chunks, kept apart from the answer
+	Err              error  // Holds error details
+	Done             bool   // Signals end of stream
 }
 
 type Error struct {
@@ -0,0 +1,8 @@
+package models
+
+// Image holds an image attached to the user input for vision-capable models.
+type Image struct {
+	Path      string // Relative path of the image, used for display
+	MediaType string // Media type of the image, e.g., "image/png"
+	Data      string // Base64 encoded content of the image
+}
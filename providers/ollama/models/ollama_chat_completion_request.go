@@ -7,10 +7,12 @@ type OllamaChatCompletionRequest struct {
 	Temperature     *float32  `json:"temperature,omitempty"`      // Optional field (pointer to float32)
 	ReasoningEffort *string   `json:"reasoning_effort,omitempty"` // Optional field (pointer to string)
 	Stream          bool      `json:"stream"`
+	Think           *bool     `json:"think,omitempty"` // Optional field, asks thinking models to return their thinking separately
 }
 
 // Message Define the request body structure
 type Message struct {
-	Role    string `json:"role"`
-	Content string `json:"content"`
+	Role    string   `json:"role"`
+	Content string   `json:"content"`
+	Images  []string `json:"images,omitempty"` // Optional field, base64 encoded images for vision models
 }
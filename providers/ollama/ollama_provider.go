@@ -21,6 +21,7 @@ import (
 type OllamaConfig struct {
 	BaseURL         string
 	Model           string
+	Stream          bool
 	Temperature     *float32
 	ReasoningEffort *string
 	EncodingFormat  string
@@ -42,6 +43,7 @@ func NewOllamaChatProvider(config *OllamaConfig) contracts.IChatAIProvider {
 	return &OllamaConfig{
 		BaseURL:         config.BaseURL,
 		Model:           config.Model,
+		Stream:          config.Stream,
 		Temperature:     config.Temperature,
 		ReasoningEffort: config.ReasoningEffort,
 		EncodingFormat:  config.EncodingFormat,
@@ -50,24 +52,37 @@ func NewOllamaChatProvider(config *OllamaConfig) contracts.IChatAIProvider {
 	}
 }
 
-func (ollamaProvider *OllamaConfig) ChatCompletionRequest(ctx context.Context, userInput string, prompt string) <-chan models.StreamResponse {
+func (ollamaProvider *OllamaConfig) ChatCompletionRequest(ctx context.Context, userInput string, prompt string, images []models.Image) <-chan models.StreamResponse {
 	responseChan := make(chan models.StreamResponse)
-	var markdownBuffer strings.Builder // Buffer to accumulate content until newline
+	var markdownBuffer strings.Builder  // Buffer to accumulate content until newline
+	var reasoningBuffer strings.Builder // Buffer to accumulate thinking until newline
 
 	go func() {
 		defer close(responseChan)
 
+		// Attach base64 encoded images to the user message for vision models
+		var userImages []string
+		for _, image := range images {
+			userImages = append(userImages, image.Data)
+		}
+
 		// Prepare the request body
 		reqBody := ollama_models.OllamaChatCompletionRequest{
 			Model: ollamaProvider.Model,
 			Messages: []ollama_models.Message{
 				{Role: "system", Content: prompt},
-				{Role: "user", Content: userInput},
+				{Role: "user", Content: userInput, Images: userImages},
 			},
-			Stream:      true,
+			Stream:      ollamaProvider.Stream,
 			Temperature: ollamaProvider.Temperature,
 		}
 
+		// Ask thinking models to return their thinking separately when reasoning is requested
+		if ollamaProvider.ReasoningEffort != nil && *ollamaProvider.ReasoningEffort != "" {
+			think := true
+			reqBody.Think = &think
+		}
+
 		jsonData, err := json.Marshal(reqBody)
 		if err != nil {
 			markdownBuffer.Reset()
@@ -112,6 +127,27 @@ func (ollamaProvider *OllamaConfig) ChatCompletionRequest(ctx context.Context, u
 			return
 		}
 
+		// Return the full completion at once when streaming is disabled
+		if !ollamaProvider.Stream {
+			var response ollama_models.OllamaChatCompletionResponse
+			if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+				responseChan <- models.StreamResponse{Err: fmt.Errorf("error decoding response: %v", err)}
+				return
+			}
+
+			// Count total tokens usage
+			if response.PromptEvalCount > 0 {
+				ollamaProvider.TokenManagement.UsedTokens(response.PromptEvalCount, response.EvalCount)
+			}
+
+			if response.Message.Thinking != "" {
+				responseChan <- models.StreamResponse{ReasoningContent: response.Message.Thinking}
+			}
+			responseChan <- models.StreamResponse{Content: response.Message.Content}
+			responseChan <- models.StreamResponse{Done: true}
+			return
+		}
+
 		reader := bufio.NewReader(resp.Body)
 
 		// Stream processing
@@ -135,7 +171,23 @@ func (ollamaProvider *OllamaConfig) ChatCompletionRequest(ctx context.Context, u
 				return
 			}
 
+			if len(response.Message.Thinking) > 0 {
+				reasoningBuffer.WriteString(response.Message.Thinking)
+
+				// Send thinking chunk if it contains a newline, and then reset the buffer
+				if strings.Contains(response.Message.Thinking, "\n") {
+					responseChan <- models.StreamResponse{ReasoningContent: reasoningBuffer.String()}
+					reasoningBuffer.Reset()
+				}
+			}
+
 			if len(response.Message.Content) > 0 {
+				// Flush the rest of the thinking once the answer starts
+				if reasoningBuffer.Len() > 0 {
+					responseChan <- models.StreamResponse{ReasoningContent: reasoningBuffer.String()}
+					reasoningBuffer.Reset()
+				}
+
 				content := response.Message.Content
 				markdownBuffer.WriteString(content)
 
@@ -150,13 +202,14 @@ func (ollamaProvider *OllamaConfig) ChatCompletionRequest(ctx context.Context, u
 			if response.Done {
 				//	// Signal end of stream
 				responseChan <- models.StreamResponse{Content: markdownBuffer.String()}
-				responseChan <- models.StreamResponse{Done: true}
 
 				// Count total tokens usage
 				if response.PromptEvalCount > 0 {
 					ollamaProvider.TokenManagement.UsedTokens(response.PromptEvalCount, response.EvalCount)
 				}
 
+				responseChan <- models.StreamResponse{Done: true}
+
 				break
 			}
 		}
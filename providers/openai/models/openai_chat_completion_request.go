@@ -2,18 +2,30 @@ package models
 
 // OpenAIChatCompletionRequest Define the request body structure
 type OpenAIChatCompletionRequest struct {
-	Model           string        `json:"model"`
-	Messages        []Message     `json:"messages"`
-	Temperature     *float32      `json:"temperature,omitempty"`      // Optional field (pointer to float32)
-	ReasoningEffort *string       `json:"reasoning_effort,omitempty"` // Optional field (pointer to string)
-	Stream          bool          `json:"stream"`
-	StreamOptions   StreamOptions `json:"stream_options"`
+	Model           string         `json:"model"`
+	Messages        []Message      `json:"messages"`
+	Temperature     *float32       `json:"temperature,omitempty"`      // Optional field (pointer to float32)
+	ReasoningEffort *string        `json:"reasoning_effort,omitempty"` // Optional field (pointer to string)
+	Stream          bool           `json:"stream"`
+	StreamOptions   *StreamOptions `json:"stream_options,omitempty"` // Only allowed when streaming
 }
 
 // Message Define the request body structure
 type Message struct {
 	Role    string `json:"role"`
-	Content string `json:"content"`
+	Content any    `json:"content"` // Either a string or a list of content parts
+}
+
+// ContentPart represents a part of a multi-part message content, e.g., text or image.
+type ContentPart struct {
+	Type     string    `json:"type"`                // Type of the part, "text" or "image_url"
+	Text     string    `json:"text,omitempty"`      // Text of a "text" part
+	ImageURL *ImageURL `json:"image_url,omitempty"` // Image of an "image_url" part
+}
+
+// ImageURL holds the image of a content part as a URL.
+type ImageURL struct {
+	URL string `json:"url"` // Data URL of the base64 encoded image
 }
 
 // StreamOptions includes configurations for streaming behavior
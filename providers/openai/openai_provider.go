@@ -21,6 +21,7 @@ import (
 type OpenAIConfig struct {
 	BaseURL         string
 	Model           string
+	Stream          bool
 	Temperature     *float32
 	ReasoningEffort *string
 	EncodingFormat  string
@@ -44,6 +45,7 @@ func NewOpenAIChatProvider(config *OpenAIConfig) contracts.IChatAIProvider {
 	return &OpenAIConfig{
 		BaseURL:         baseURL,
 		Model:           config.Model,
+		Stream:          config.Stream,
 		Temperature:     config.Temperature,
 		ReasoningEffort: config.ReasoningEffort,
 		EncodingFormat:  config.EncodingFormat,
@@ -54,27 +56,45 @@ func NewOpenAIChatProvider(config *OpenAIConfig) contracts.IChatAIProvider {
 	}
 }
 
-func (openAIProvider *OpenAIConfig) ChatCompletionRequest(ctx context.Context, userInput string, prompt string) <-chan models.StreamResponse {
+func (openAIProvider *OpenAIConfig) ChatCompletionRequest(ctx context.Context, userInput string, prompt string, images []models.Image) <-chan models.StreamResponse {
 	responseChan := make(chan models.StreamResponse)
-	var markdownBuffer strings.Builder // Buffer to accumulate content until newline
-	var usage openai_models.Usage      // Variable to hold usage data
+	var markdownBuffer strings.Builder  // Buffer to accumulate content until newline
+	var reasoningBuffer strings.Builder // Buffer to accumulate reasoning until newline
+	var usage openai_models.Usage       // Variable to hold usage data
 
 	go func() {
 		defer close(responseChan)
 
+		// Attach images as content parts of the user message for vision models
+		var userContent any = userInput
+		if len(images) > 0 {
+			parts := []openai_models.ContentPart{{Type: "text", Text: userInput}}
+			for _, image := range images {
+				parts = append(parts, openai_models.ContentPart{
+					Type:     "image_url",
+					ImageURL: &openai_models.ImageURL{URL: fmt.Sprintf("data:%s;base64,%s", image.MediaType, image.Data)},
+				})
+			}
+			userContent = parts
+		}
+
 		// Prepare the request body
 		reqBody := openai_models.OpenAIChatCompletionRequest{
 			Model: openAIProvider.Model,
 			Messages: []openai_models.Message{
 				{Role: "system", Content: prompt},
-				{Role: "user", Content: userInput},
+				{Role: "user", Content: userContent},
 			},
-			Stream:          true,
+			Stream:          openAIProvider.Stream,
 			Temperature:     openAIProvider.Temperature,
 			ReasoningEffort: openAIProvider.ReasoningEffort,
-			StreamOptions: openai_models.StreamOptions{
+		}
+
+		// Request usage with the last chunk, stream options are only allowed when streaming
+		if reqBody.Stream {
+			reqBody.StreamOptions = &openai_models.StreamOptions{
 				IncludeUsage: true,
-			},
+			}
 		}
 
 		jsonData, err := json.Marshal(reqBody)
@@ -121,6 +141,32 @@ func (openAIProvider *OpenAIConfig) ChatCompletionRequest(ctx context.Context, u
 			return
 		}
 
+		// Return the full completion at once when streaming is disabled
+		if !openAIProvider.Stream {
+			var response openai_models.OpenAIChatCompletionResponse
+			if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+				responseChan <- models.StreamResponse{Err: fmt.Errorf("error decoding response: %v", err)}
+				return
+			}
+
+			// Count total tokens usage
+			if response.Usage.TotalTokens > 0 {
+				openAIProvider.TokenManagement.UsedTokens(response.Usage.PromptTokens, response.Usage.CompletionTokens)
+				openAIProvider.TokenManagement.UsedReasoningTokens(response.Usage.CompletionTokensDetails.ReasoningTokens)
+				openAIProvider.TokenManagement.UsedCachedTokens(response.Usage.PromptTokensDetails.CachedTokens)
+			}
+
+			if len(response.Choices) > 0 {
+				if response.Choices[0].Message.ReasoningContent != "" {
+					responseChan <- models.StreamResponse{ReasoningContent: response.Choices[0].Message.ReasoningContent}
+				}
+				responseChan <- models.StreamResponse{Content: response.Choices[0].Message.Content}
+			}
+
+			responseChan <- models.StreamResponse{Done: true}
+			return
+		}
+
 		reader := bufio.NewReader(resp.Body)
 
 		// Stream processing
@@ -139,13 +185,20 @@ func (openAIProvider *OpenAIConfig) ChatCompletionRequest(ctx context.Context, u
 				// Send the final content
 				responseChan <- models.StreamResponse{Content: markdownBuffer.String()}
 
-				responseChan <- models.StreamResponse{Done: true}
-
 				// Count total tokens usage
 				if usage.TotalTokens > 0 {
 					openAIProvider.TokenManagement.UsedTokens(usage.PromptTokens, usage.CompletionTokens)
+					openAIProvider.TokenManagement.UsedReasoningTokens(usage.CompletionTokensDetails.ReasoningTokens)
+					openAIProvider.TokenManagement.UsedCachedTokens(usage.PromptTokensDetails.CachedTokens)
 				}
 
+				// Send the reasoning left when the stream ends before any answer or newline
+				if reasoningBuffer.Len() > 0 {
+					responseChan <- models.StreamResponse{ReasoningContent: reasoningBuffer.String()}
+					reasoningBuffer.Reset()
+				}
+				responseChan <- models.StreamResponse{Done: true}
+
 				break
 			}
 
@@ -166,6 +219,14 @@ func (openAIProvider *OpenAIConfig) ChatCompletionRequest(ctx context.Context, u
 
 				// Accumulate and send response content
 				if len(response.Choices) > 0 {
+					// Send reasoning apart from the answer and flush it once the answer starts
+					reasoning := response.Choices[0].Delta.ReasoningContent
+					reasoningBuffer.WriteString(reasoning)
+					if strings.Contains(reasoning, "\n") || (response.Choices[0].Delta.Content != "" && reasoningBuffer.Len() > 0) {
+						responseChan <- models.StreamResponse{ReasoningContent: reasoningBuffer.String()}
+						reasoningBuffer.Reset()
+					}
+
 					content := response.Choices[0].Delta.Content
 					markdownBuffer.WriteString(content)
 
@@ -178,6 +239,12 @@ func (openAIProvider *OpenAIConfig) ChatCompletionRequest(ctx context.Context, u
 			}
 		}
 
+		// Send the reasoning left when the stream ends before any answer or newline
+		if reasoningBuffer.Len() > 0 {
+			responseChan <- models.StreamResponse{ReasoningContent: reasoningBuffer.String()}
+			reasoningBuffer.Reset()
+		}
+
 		// Send any remaining content in the buffer
 		if markdownBuffer.Len() > 0 {
 			responseChan <- models.StreamResponse{Content: markdownBuffer.String()}
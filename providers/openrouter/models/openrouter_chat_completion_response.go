@@ -9,17 +9,31 @@ type OpenRouterChatCompletionResponse struct {
 // Choice represents an individual choice in the response.
 type Choice struct {
 	Delta        Delta  `json:"delta"`
+	Message      Delta  `json:"message"`       // Full message when streaming is disabled
 	FinishReason string `json:"finish_reason"` // Final chunk: reason for stopping (e.g., "stop").
 }
 
 // Delta represents the delta object in each choice containing the content.
 type Delta struct {
-	Content string `json:"content"`
+	Content   string `json:"content"`
+	Reasoning string `json:"reasoning"` // Normalized reasoning tokens of the underlying model
 }
 
 // Usage defines the token usage information for the response.
 type Usage struct {
-	PromptTokens     int `json:"prompt_tokens"`     // Number of tokens in the prompt
-	CompletionTokens int `json:"completion_tokens"` // Number of tokens in the completion
-	TotalTokens      int `json:"total_tokens"`      // Total tokens used
+	PromptTokens            int                     `json:"prompt_tokens"`             // Number of tokens in the prompt
+	CompletionTokens        int                     `json:"completion_tokens"`         // Number of tokens in the completion
+	TotalTokens             int                     `json:"total_tokens"`              // Total tokens used
+	CompletionTokensDetails CompletionTokensDetails `json:"completion_tokens_details"` // Breakdown of the completion tokens
+	PromptTokensDetails     PromptTokensDetails     `json:"prompt_tokens_details"`     // Breakdown of the prompt tokens
+}
+
+// CompletionTokensDetails defines the breakdown of the completion tokens.
+type CompletionTokensDetails struct {
+	ReasoningTokens int `json:"reasoning_tokens"` // Number of tokens used for reasoning
+}
+
+// PromptTokensDetails defines the breakdown of the prompt tokens.
+type PromptTokensDetails struct {
+	CachedTokens int `json:"cached_tokens"` // Number of prompt tokens read from the prompt cache
 }
@@ -22,6 +22,7 @@ import (
 type OpenRouterConfig struct {
 	BaseURL         string
 	Model           string
+	Stream          bool
 	Temperature     *float32
 	ReasoningEffort *string
 	EncodingFormat  string
@@ -45,6 +46,7 @@ func NewOpenRouterChatProvider(config *OpenRouterConfig) contracts.IChatAIProvid
 	return &OpenRouterConfig{
 		BaseURL:         baseURL,
 		Model:           config.Model,
+		Stream:          config.Stream,
 		Temperature:     config.Temperature,
 		ReasoningEffort: config.ReasoningEffort,
 		EncodingFormat:  config.EncodingFormat,
@@ -55,14 +57,20 @@ func NewOpenRouterChatProvider(config *OpenRouterConfig) contracts.IChatAIProvid
 	}
 }
 
-func (openRouterProvider *OpenRouterConfig) ChatCompletionRequest(ctx context.Context, userInput string, prompt string) <-chan general_models.StreamResponse {
+func (openRouterProvider *OpenRouterConfig) ChatCompletionRequest(ctx context.Context, userInput string, prompt string, images []general_models.Image) <-chan general_models.StreamResponse {
 	responseChan := make(chan general_models.StreamResponse)
-	var markdownBuffer strings.Builder // Buffer to accumulate content until newline
-	var usage models.Usage             // Variable to hold usage data
+	var markdownBuffer strings.Builder  // Buffer to accumulate content until newline
+	var reasoningBuffer strings.Builder // Buffer to accumulate reasoning until newline
+	var usage models.Usage              // Variable to hold usage data
 
 	go func() {
 		defer close(responseChan)
 
+		if len(images) > 0 {
+			responseChan <- general_models.StreamResponse{Err: fmt.Errorf("image input is not supported by the 'openrouter' provider")}
+			return
+		}
+
 		// Prepare the request body
 		reqBody := models.OpenRouterChatCompletionRequest{
 			Model: openRouterProvider.Model,
@@ -70,7 +78,7 @@ func (openRouterProvider *OpenRouterConfig) ChatCompletionRequest(ctx context.Co
 				{Role: "system", Content: prompt},
 				{Role: "user", Content: userInput},
 			},
-			Stream:          true,
+			Stream:          openRouterProvider.Stream,
 			Temperature:     openRouterProvider.Temperature,
 			ReasoningEffort: openRouterProvider.ReasoningEffort,
 		}
@@ -119,6 +127,32 @@ func (openRouterProvider *OpenRouterConfig) ChatCompletionRequest(ctx context.Co
 			return
 		}
 
+		// Return the full completion at once when streaming is disabled
+		if !openRouterProvider.Stream {
+			var response models.OpenRouterChatCompletionResponse
+			if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+				responseChan <- general_models.StreamResponse{Err: fmt.Errorf("error decoding response: %v", err)}
+				return
+			}
+
+			// Count total tokens usage
+			if response.Usage.TotalTokens > 0 {
+				openRouterProvider.TokenManagement.UsedTokens(response.Usage.PromptTokens, response.Usage.CompletionTokens)
+				openRouterProvider.TokenManagement.UsedReasoningTokens(response.Usage.CompletionTokensDetails.ReasoningTokens)
+				openRouterProvider.TokenManagement.UsedCachedTokens(response.Usage.PromptTokensDetails.CachedTokens)
+			}
+
+			if len(response.Choices) > 0 {
+				if response.Choices[0].Message.Reasoning != "" {
+					responseChan <- general_models.StreamResponse{ReasoningContent: response.Choices[0].Message.Reasoning}
+				}
+				responseChan <- general_models.StreamResponse{Content: response.Choices[0].Message.Content}
+			}
+
+			responseChan <- general_models.StreamResponse{Done: true}
+			return
+		}
+
 		reader := bufio.NewReader(resp.Body)
 
 		// Stream processing
@@ -156,6 +190,14 @@ func (openRouterProvider *OpenRouterConfig) ChatCompletionRequest(ctx context.Co
 				// Accumulate and send response content
 				if len(response.Choices) > 0 {
 					choice := response.Choices[0]
+					// Send reasoning apart from the answer and flush it once the answer starts
+					reasoning := choice.Delta.Reasoning
+					reasoningBuffer.WriteString(reasoning)
+					if strings.Contains(reasoning, "\n") || (choice.Delta.Content != "" && reasoningBuffer.Len() > 0) {
+						responseChan <- general_models.StreamResponse{ReasoningContent: reasoningBuffer.String()}
+						reasoningBuffer.Reset()
+					}
+
 					content := choice.Delta.Content
 					markdownBuffer.WriteString(content)
 
@@ -169,19 +211,32 @@ func (openRouterProvider *OpenRouterConfig) ChatCompletionRequest(ctx context.Co
 					if choice.FinishReason == "stop" {
 						responseChan <- general_models.StreamResponse{Content: markdownBuffer.String()}
 
-						responseChan <- general_models.StreamResponse{Done: true}
-
 						// Count total tokens usage
 						if usage.TotalTokens > 0 {
 							openRouterProvider.TokenManagement.UsedTokens(usage.PromptTokens, usage.CompletionTokens)
+							openRouterProvider.TokenManagement.UsedReasoningTokens(usage.CompletionTokensDetails.ReasoningTokens)
+							openRouterProvider.TokenManagement.UsedCachedTokens(usage.PromptTokensDetails.CachedTokens)
 						}
 
+						// Send the reasoning left when the stream ends before any answer or newline
+						if reasoningBuffer.Len() > 0 {
+							responseChan <- general_models.StreamResponse{ReasoningContent: reasoningBuffer.String()}
+							reasoningBuffer.Reset()
+						}
+						responseChan <- general_models.StreamResponse{Done: true}
+
 						break
 					}
 				}
 			}
 		}
 
+		// Send the reasoning left when the stream ends before any answer or newline
+		if reasoningBuffer.Len() > 0 {
+			responseChan <- general_models.StreamResponse{ReasoningContent: reasoningBuffer.String()}
+			reasoningBuffer.Reset()
+		}
+
 		// Send any remaining content in the buffer
 		if markdownBuffer.Len() > 0 {
 			responseChan <- general_models.StreamResponse{Content: markdownBuffer.String()}
@@ -20,6 +20,7 @@ import (
 type QwenConfig struct {
 	BaseURL         string
 	Model           string
+	Stream          bool
 	Temperature     *float32
 	MaxTokens       int
 	ApiKey          string
@@ -41,6 +42,7 @@ func NewQwenChatProvider(config *QwenConfig) contracts.IChatAIProvider {
 	return &QwenConfig{
 		BaseURL:         baseURL,
 		Model:           config.Model,
+		Stream:          config.Stream,
 		Temperature:     config.Temperature,
 		MaxTokens:       config.MaxTokens,
 		ApiKey:          config.ApiKey,
@@ -48,14 +50,20 @@ func NewQwenChatProvider(config *QwenConfig) contracts.IChatAIProvider {
 	}
 }
 
-func (qwenProvider *QwenConfig) ChatCompletionRequest(ctx context.Context, userInput string, prompt string) <-chan models.StreamResponse {
+func (qwenProvider *QwenConfig) ChatCompletionRequest(ctx context.Context, userInput string, prompt string, images []models.Image) <-chan models.StreamResponse {
 	responseChan := make(chan models.StreamResponse)
 	var markdownBuffer strings.Builder
+	var reasoningBuffer strings.Builder
 	var usage qwen_models.Usage
 
 	go func() {
 		defer close(responseChan)
 
+		if len(images) > 0 {
+			responseChan <- models.StreamResponse{Err: fmt.Errorf("image input is not supported by the 'qwen' provider")}
+			return
+		}
+
 		reqBody := qwen_models.QwenChatCompletionRequest{
 			Model: qwenProvider.Model,
 			Messages: []qwen_models.Message{
@@ -64,7 +72,7 @@ func (qwenProvider *QwenConfig) ChatCompletionRequest(ctx context.Context, userI
 			},
 			Temperature: qwenProvider.Temperature,
 			MaxTokens:   qwenProvider.MaxTokens,
-			Stream:      true,
+			Stream:      qwenProvider.Stream,
 		}
 
 		jsonData, err := json.Marshal(reqBody)
@@ -105,6 +113,32 @@ func (qwenProvider *QwenConfig) ChatCompletionRequest(ctx context.Context, userI
 			return
 		}
 
+		// Return the full completion at once when streaming is disabled
+		if !qwenProvider.Stream {
+			var response qwen_models.QwenChatCompletionResponse
+			if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+				responseChan <- models.StreamResponse{Err: fmt.Errorf("error decoding response: %v", err)}
+				return
+			}
+
+			// Count total tokens usage
+			if response.Usage.TotalTokens > 0 {
+				qwenProvider.TokenManagement.UsedTokens(response.Usage.PromptTokens, response.Usage.CompletionTokens)
+				qwenProvider.TokenManagement.UsedReasoningTokens(response.Usage.CompletionTokensDetails.ReasoningTokens)
+				qwenProvider.TokenManagement.UsedCachedTokens(response.Usage.PromptTokensDetails.CachedTokens)
+			}
+
+			if len(response.Choices) > 0 {
+				if response.Choices[0].Message.ReasoningContent != "" {
+					responseChan <- models.StreamResponse{ReasoningContent: response.Choices[0].Message.ReasoningContent}
+				}
+				responseChan <- models.StreamResponse{Content: response.Choices[0].Message.Content}
+			}
+
+			responseChan <- models.StreamResponse{Done: true}
+			return
+		}
+
 		reader := bufio.NewReader(resp.Body)
 
 		for {
@@ -130,6 +164,14 @@ func (qwenProvider *QwenConfig) ChatCompletionRequest(ctx context.Context, userI
 				}
 
 				if len(response.Choices) > 0 {
+					// Send reasoning apart from the answer and flush it once the answer starts
+					reasoning := response.Choices[0].Delta.ReasoningContent
+					reasoningBuffer.WriteString(reasoning)
+					if strings.Contains(reasoning, "\n") || (response.Choices[0].Delta.Content != "" && reasoningBuffer.Len() > 0) {
+						responseChan <- models.StreamResponse{ReasoningContent: reasoningBuffer.String()}
+						reasoningBuffer.Reset()
+					}
+
 					content := response.Choices[0].Delta.Content
 					markdownBuffer.WriteString(content)
 
@@ -145,10 +187,18 @@ func (qwenProvider *QwenConfig) ChatCompletionRequest(ctx context.Context, userI
 			responseChan <- models.StreamResponse{Content: markdownBuffer.String()}
 		}
 
-		responseChan <- models.StreamResponse{Done: true}
 		if usage.TotalTokens > 0 {
 			qwenProvider.TokenManagement.UsedTokens(usage.PromptTokens, usage.CompletionTokens)
+			qwenProvider.TokenManagement.UsedReasoningTokens(usage.CompletionTokensDetails.ReasoningTokens)
+			qwenProvider.TokenManagement.UsedCachedTokens(usage.PromptTokensDetails.CachedTokens)
 		}
+
+		// Send the reasoning left when the stream ends before any answer or newline
+		if reasoningBuffer.Len() > 0 {
+			responseChan <- models.StreamResponse{ReasoningContent: reasoningBuffer.String()}
+			reasoningBuffer.Reset()
+		}
+		responseChan <- models.StreamResponse{Done: true}
 	}()
 
 	return responseChan
@@ -0,0 +1,185 @@
+package session_commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"sort"
+	"strings"
+
+	"github.com/meysamhadeli/codai/constants/lipgloss"
+	"github.com/meysamhadeli/codai/session_commands/contracts"
+	"github.com/meysamhadeli/codai/session_commands/models"
+	"github.com/meysamhadeli/codai/utils"
+)
+
+// externalCommandPrefix is the prefix of executables on PATH that extend the commands, e.g., 'codai-review' for ':review'
+const externalCommandPrefix = "codai-"
+
+// commandRegex matches an input invoking a command, e.g., ":image path/to/screenshot.png"
+var commandRegex = regexp.MustCompile(`^:([\w-]+)(?:\s+|$)`)
+
+// commandRegistry keeps the commands of the code session by name and alias
+type commandRegistry struct {
+	commands     []*models.Command
+	byName       map[string]*models.Command
+	sessionState func() models.SessionState
+}
+
+// NewCommandRegistry creates a command registry, sessionState returns the state sent to external commands
+func NewCommandRegistry(sessionState func() models.SessionState) contracts.ICommandRegistry {
+	return &commandRegistry{
+		byName:       make(map[string]*models.Command),
+		sessionState: sessionState,
+	}
+}
+
+// Register adds a command, a name or alias already taken keeps its first command
+func (registry *commandRegistry) Register(command models.Command) {
+	cmd := &command
+
+	registered := false
+	for _, name := range append([]string{cmd.Name}, cmd.Aliases...) {
+		if _, exists := registry.byName[name]; exists {
+			continue
+		}
+		registry.byName[name] = cmd
+		registered = true
+	}
+
+	if registered {
+		registry.commands = append(registry.commands, cmd)
+	}
+}
+
+// RegisterExternalCommands registers the executables on PATH named 'codai-<cmd>' as ':<cmd>'
+func (registry *commandRegistry) RegisterExternalCommands() {
+	for _, dir := range filepath.SplitList(os.Getenv("PATH")) {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+
+		for _, entry := range entries {
+			if entry.IsDir() || !strings.HasPrefix(entry.Name(), externalCommandPrefix) {
+				continue
+			}
+
+			path := filepath.Join(dir, entry.Name())
+			if !isExecutable(path) {
+				continue
+			}
+
+			name := strings.TrimPrefix(entry.Name(), externalCommandPrefix)
+			if runtime.GOOS == "windows" {
+				name = strings.TrimSuffix(name, filepath.Ext(name))
+			}
+
+			registry.Register(models.Command{
+				Name:        name,
+				Usage:       "[args...]",
+				Description: fmt.Sprintf("External command '%s'", entry.Name()),
+				MaxArgs:     -1,
+				Run: func(args []string) (bool, error) {
+					return false, registry.runExternalCommand(path, args)
+				},
+			})
+		}
+	}
+}
+
+// Execute runs the command of the input, it reports if the input was a command and if the session should exit
+func (registry *commandRegistry) Execute(input string) (bool, bool) {
+	match := commandRegex.FindStringSubmatch(input)
+	if match == nil {
+		return false, false
+	}
+
+	command, exists := registry.byName[match[1]]
+	if !exists {
+		fmt.Println(lipgloss.Red.Render(fmt.Sprintf("Unknown command ':%s', use ':help' to list the commands.", match[1])))
+		return true, false
+	}
+
+	args := utils.SplitArgs(input[len(match[0]):])
+	if len(args) < command.MinArgs || (command.MaxArgs >= 0 && len(args) > command.MaxArgs) {
+		fmt.Println(lipgloss.Red.Render(fmt.Sprintf("Usage: %s", usageOf(command))))
+		return true, false
+	}
+
+	exit, err := command.Run(args)
+	if err != nil {
+		fmt.Println(lipgloss.Red.Render(fmt.Sprintf("%v", err)))
+	}
+
+	return true, exit
+}
+
+// Names returns the names and aliases of the commands prefixed with ':', used by the tab completion
+func (registry *commandRegistry) Names() []string {
+	var names []string
+	for name := range registry.byName {
+		names = append(names, ":"+name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Help returns a line per command with its usage, aliases and description
+func (registry *commandRegistry) Help() string {
+	var lines []string
+	for _, command := range registry.commands {
+		line := fmt.Sprintf("%s  %s", usageOf(command), command.Description)
+		if len(command.Aliases) > 0 {
+			line += fmt.Sprintf(" (alias: :%s)", strings.Join(command.Aliases, ", :"))
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// runExternalCommand runs an external command with the session state as JSON on its stdin
+func (registry *commandRegistry) runExternalCommand(path string, args []string) error {
+	state := registry.sessionState()
+	state.Args = args
+
+	stateJson, err := json.Marshal(state)
+	if err != nil {
+		return fmt.Errorf("error marshalling session state: %v", err)
+	}
+
+	cmd := exec.Command(path, args...)
+	cmd.Dir = state.Cwd
+	cmd.Stdin = bytes.NewReader(stateJson)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("external command '%s' failed: %v", filepath.Base(path), err)
+	}
+
+	return nil
+}
+
+func usageOf(command *models.Command) string {
+	if command.Usage == "" {
+		return ":" + command.Name
+	}
+	return fmt.Sprintf(":%s %s", command.Name, command.Usage)
+}
+
+func isExecutable(path string) bool {
+	info, err := os.Stat(path)
+	if err != nil || info.IsDir() {
+		return false
+	}
+	if runtime.GOOS == "windows" {
+		return strings.EqualFold(filepath.Ext(path), ".exe")
+	}
+	return info.Mode()&0111 != 0
+}
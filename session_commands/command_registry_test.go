@@ -0,0 +1,92 @@
+package session_commands
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/meysamhadeli/codai/session_commands/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExecuteBuiltInCommand(t *testing.T) {
+	registry := NewCommandRegistry(func() models.SessionState { return models.SessionState{} })
+
+	var receivedArgs []string
+	registry.Register(models.Command{
+		Name:    "image",
+		Aliases: []string{"img"},
+		Usage:   "<path>",
+		MinArgs: 1,
+		MaxArgs: 1,
+		Run: func(args []string) (bool, error) {
+			receivedArgs = args
+			return false, nil
+		},
+	})
+	registry.Register(models.Command{
+		Name: "exit",
+		Run: func(args []string) (bool, error) {
+			return true, nil
+		},
+	})
+
+	isCommand, exit := registry.Execute(`:img "my screenshot.png"`)
+	assert.True(t, isCommand)
+	assert.False(t, exit)
+	assert.Equal(t, []string{"my screenshot.png"}, receivedArgs)
+
+	// Wrong number of arguments shows the usage without running the command
+	receivedArgs = nil
+	isCommand, _ = registry.Execute(":image")
+	assert.True(t, isCommand)
+	assert.Nil(t, receivedArgs)
+
+	isCommand, exit = registry.Execute(":exit")
+	assert.True(t, isCommand)
+	assert.True(t, exit)
+
+	isCommand, _ = registry.Execute(":unknown")
+	assert.True(t, isCommand)
+
+	isCommand, _ = registry.Execute("explain the :image command")
+	assert.False(t, isCommand)
+
+	assert.Equal(t, []string{":exit", ":image", ":img"}, registry.Names())
+	assert.Contains(t, registry.Help(), ":image <path>")
+	assert.Contains(t, registry.Help(), "(alias: :img)")
+}
+
+func TestExecuteExternalCommand(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("external command script requires a unix shell")
+	}
+
+	binDir := t.TempDir()
+	workDir := t.TempDir()
+	outputFile := filepath.Join(workDir, "state.json")
+
+	script := "#!/bin/sh\ncat > " + outputFile + "\n"
+	assert.NoError(t, os.WriteFile(filepath.Join(binDir, "codai-review"), []byte(script), 0755))
+	t.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	registry := NewCommandRegistry(func() models.SessionState {
+		return models.SessionState{Cwd: workDir, Provider: "openai", Model: "gpt-4o", History: []string{"previous chat"}}
+	})
+	registry.RegisterExternalCommands()
+
+	isCommand, exit := registry.Execute(":review main.go")
+	assert.True(t, isCommand)
+	assert.False(t, exit)
+
+	content, err := os.ReadFile(outputFile)
+	assert.NoError(t, err)
+
+	var state models.SessionState
+	assert.NoError(t, json.Unmarshal(content, &state))
+	assert.Equal(t, "gpt-4o", state.Model)
+	assert.Equal(t, []string{"previous chat"}, state.History)
+	assert.Equal(t, []string{"main.go"}, state.Args)
+}
@@ -0,0 +1,11 @@
+package contracts
+
+import "github.com/meysamhadeli/codai/session_commands/models"
+
+type ICommandRegistry interface {
+	Register(command models.Command)
+	RegisterExternalCommands()
+	Execute(input string) (bool, bool)
+	Names() []string
+	Help() string
+}
@@ -0,0 +1,21 @@
+package models
+
+// Command is a command of the code session, invoked like ':name args'
+type Command struct {
+	Name        string
+	Aliases     []string
+	Usage       string // The arguments of command, e.g., "<path>"
+	Description string
+	MinArgs     int
+	MaxArgs     int // -1 for any number of arguments
+	Run         func(args []string) (exit bool, err error)
+}
+
+// SessionState is the state of the code session sent as JSON on the stdin of external commands
+type SessionState struct {
+	Cwd      string   `json:"cwd"`
+	Provider string   `json:"provider"`
+	Model    string   `json:"model"`
+	History  []string `json:"history"`
+	Args     []string `json:"args"`
+}
@@ -0,0 +1,119 @@
+package token_management
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/meysamhadeli/codai/token_management/models"
+)
+
+// Actions on a request above a limit of budget
+const (
+	BudgetConfirm = "confirm"
+	BudgetBlock   = "block"
+)
+
+// estimatedOutputTokens is the expected length of an answer for the pre-flight estimate, capped by the max output of model
+const estimatedOutputTokens = 2048
+
+// EstimateTokens estimates the tokens of a text before sending it, about 4 characters per token
+func EstimateTokens(text string) int {
+	return (len(text) + 3) / 4
+}
+
+// EstimateRequestCost estimates the cost of a request from its prompt, before sending it.
+func (tm *tokenManager) EstimateRequestCost(providerName string, modelName string, prompt string) float64 {
+	outputTokens := estimatedOutputTokens
+	if _, modelDetails, err := ResolveModelDetails(providerName, modelName); err == nil && modelDetails.MaxOutputTokens > 0 {
+		outputTokens = min(outputTokens, modelDetails.MaxOutputTokens)
+	}
+
+	return tm.CalculateCost(providerName, modelName, models.Usage{InputTokens: EstimateTokens(prompt), OutputTokens: outputTokens})
+}
+
+// ValidateBudget checks the action on a request above a limit of budget, either 'confirm' or 'block'.
+func ValidateBudget(budget *models.BudgetConfig) error {
+	if budget == nil || budget.OnExceed == BudgetConfirm || budget.OnExceed == BudgetBlock {
+		return nil
+	}
+	return fmt.Errorf("unknown budget.on_exceed '%s', use '%s' or '%s'", budget.OnExceed, BudgetConfirm, BudgetBlock)
+}
+
+// PreflightBudget checks the limits of budget with the estimated cost of a request, before sending it. A model missing
+// from the catalog has no price, so its request exceeds the budget rather than being counted as free.
+func (tm *tokenManager) PreflightBudget(budget *models.BudgetConfig, providerName string, modelName string, estimatedCost float64) models.BudgetCheck {
+	session := tm.SessionUsage(providerName, modelName)
+
+	check := checkBudget(budget, "estimated request", estimatedCost, session.Cost+estimatedCost, dailyCost()+estimatedCost)
+
+	if hasLimits(budget) {
+		if _, _, err := ResolveModelDetails(providerName, modelName); err != nil {
+			check.Exceeded = append(check.Exceeded, fmt.Sprintf("The price of model '%s' is unknown, so the budget can't be checked, add the model to '~/.config/codai/models.json'.", modelName))
+		}
+	}
+
+	return check
+}
+
+// CheckBudget checks the limits of budget with the cost of the last turn, after its response.
+func (tm *tokenManager) CheckBudget(budget *models.BudgetConfig, providerName string, modelName string) models.BudgetCheck {
+	turn := tm.TurnUsage(providerName, modelName)
+	session := tm.SessionUsage(providerName, modelName)
+
+	return checkBudget(budget, "last request", turn.Cost, session.Cost, dailyCost())
+}
+
+// checkBudget compares the costs of a request, the session and the day with their limits
+func checkBudget(budget *models.BudgetConfig, requestName string, requestCost float64, sessionCost float64, dailyCost float64) models.BudgetCheck {
+	var check models.BudgetCheck
+	if budget == nil {
+		return check
+	}
+
+	limits := []struct {
+		name  string
+		cost  float64
+		limit float64
+	}{
+		{fmt.Sprintf("%s (budget.per_request_usd)", requestName), requestCost, budget.PerRequestUSD},
+		{"session (budget.per_session_usd)", sessionCost, budget.PerSessionUSD},
+		{"today (budget.daily_usd)", dailyCost, budget.DailyUSD},
+	}
+
+	for _, limit := range limits {
+		if limit.limit <= 0 {
+			continue
+		}
+
+		message := fmt.Sprintf("The cost of the %s is %.4f $ of the %.4f $ limit.", limit.name, limit.cost, limit.limit)
+		switch {
+		case limit.cost > limit.limit:
+			check.Exceeded = append(check.Exceeded, message)
+		case budget.WarnThreshold > 0 && limit.cost >= limit.limit*budget.WarnThreshold:
+			check.Warnings = append(check.Warnings, message)
+		}
+	}
+
+	return check
+}
+
+// hasLimits reports whether a limit of budget is set
+func hasLimits(budget *models.BudgetConfig) bool {
+	return budget != nil && (budget.PerRequestUSD > 0 || budget.PerSessionUSD > 0 || budget.DailyUSD > 0)
+}
+
+// dailyCost returns the cost recorded in the usage ledger since the start of the day
+func dailyCost() float64 {
+	now := time.Now()
+	entries, err := ReadUsage(time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location()))
+	if err != nil {
+		return 0
+	}
+
+	var cost float64
+	for _, entry := range entries {
+		cost += entry.Cost
+	}
+
+	return cost
+}
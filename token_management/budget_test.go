@@ -0,0 +1,48 @@
+package token_management
+
+import (
+	"testing"
+
+	"github.com/meysamhadeli/codai/token_management/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckBudget(t *testing.T) {
+	budget := &models.BudgetConfig{PerRequestUSD: 0.1, PerSessionUSD: 1, DailyUSD: 0, WarnThreshold: 0.8}
+
+	check := checkBudget(budget, "estimated request", 0.05, 0.85, 100)
+	assert.Empty(t, check.Exceeded)
+	assert.Equal(t, []string{"The cost of the session (budget.per_session_usd) is 0.8500 $ of the 1.0000 $ limit."}, check.Warnings)
+
+	check = checkBudget(budget, "estimated request", 0.2, 0.5, 100)
+	assert.Empty(t, check.Warnings)
+	assert.Equal(t, []string{"The cost of the estimated request (budget.per_request_usd) is 0.2000 $ of the 0.1000 $ limit."}, check.Exceeded)
+
+	check = checkBudget(nil, "estimated request", 10, 10, 10)
+	assert.Empty(t, check.Warnings)
+	assert.Empty(t, check.Exceeded)
+}
+
+func TestValidateBudget(t *testing.T) {
+	assert.NoError(t, ValidateBudget(nil))
+	assert.NoError(t, ValidateBudget(&models.BudgetConfig{OnExceed: BudgetConfirm}))
+	assert.NoError(t, ValidateBudget(&models.BudgetConfig{OnExceed: BudgetBlock}))
+	assert.EqualError(t, ValidateBudget(&models.BudgetConfig{OnExceed: "warn"}), "unknown budget.on_exceed 'warn', use 'confirm' or 'block'")
+}
+
+func TestPreflightBudgetUnknownPrice(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	tm := NewTokenManager()
+	budget := &models.BudgetConfig{PerSessionUSD: 1, WarnThreshold: 0.8, OnExceed: BudgetConfirm}
+
+	check := tm.PreflightBudget(budget, "openai", "unknown-model", 0)
+	assert.Equal(t, []string{"The price of model 'unknown-model' is unknown, so the budget can't be checked, add the model to '~/.config/codai/models.json'."}, check.Exceeded)
+
+	check = tm.PreflightBudget(budget, "openai", "gpt-4o", 0.01)
+	assert.Empty(t, check.Exceeded)
+
+	// Without limits, the price doesn't matter
+	check = tm.PreflightBudget(&models.BudgetConfig{OnExceed: BudgetConfirm}, "openai", "unknown-model", 0)
+	assert.Empty(t, check.Exceeded)
+}
@@ -1,8 +1,20 @@
 package contracts
 
+import "github.com/meysamhadeli/codai/token_management/models"
+
 type ITokenManagement interface {
 	UsedTokens(inputToken int, outputToken int)
-	CalculateCost(providerName string, modelName string, inputToken int, outputToken int) float64
+	UsedReasoningTokens(reasoningToken int)
+	UsedCachedTokens(cachedToken int)
+	UsedCacheWriteTokens(cacheWriteToken int)
+	CalculateCost(providerName string, modelName string, usage models.Usage) float64
 	DisplayTokens(chatProviderName string, chatModel string)
+	StartTurn()
+	TurnUsage(chatProviderName string, chatModel string) models.Usage
+	SessionUsage(chatProviderName string, chatModel string) models.Usage
+	EstimateRequestCost(providerName string, modelName string, prompt string) float64
+	PreflightBudget(budget *models.BudgetConfig, providerName string, modelName string, estimatedCost float64) models.BudgetCheck
+	CheckBudget(budget *models.BudgetConfig, providerName string, modelName string) models.BudgetCheck
+	SupportsVision(providerName string, modelName string) (bool, error)
 	ClearToken()
 }
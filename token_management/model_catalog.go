@@ -0,0 +1,147 @@
+package token_management
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/meysamhadeli/codai/embed_data"
+	"github.com/meysamhadeli/codai/token_management/models"
+	"github.com/meysamhadeli/codai/utils"
+)
+
+// builtinSource is the source of the models of the embedded catalog
+const builtinSource = "builtin"
+
+// providerScopes are the prefixes of the catalog keys of a provider, besides the name of provider itself
+var providerScopes = map[string][]string{
+	"azure-openai": {"azure", "azure_ai"},
+	"azure":        {"azure_ai"},
+	"grok":         {"xai"},
+	"gemini":       {"vertex_ai"},
+}
+
+var (
+	catalogOnce sync.Once
+	catalog     map[string]models.ModelDetails
+	catalogErr  error
+)
+
+// GetModelCatalog returns the embedded catalog of models merged with the user catalog '~/.config/codai/models.json',
+// both are parsed once.
+func GetModelCatalog() (map[string]models.ModelDetails, error) {
+	catalogOnce.Do(func() {
+		catalog, catalogErr = loadModelCatalog()
+	})
+
+	return catalog, catalogErr
+}
+
+// loadModelCatalog parses the embedded catalog and merges the models of the user catalog on it, the fields of
+// a user model override the ones of the same model in the embedded catalog.
+func loadModelCatalog() (map[string]models.ModelDetails, error) {
+	var builtin models.ModelCatalog
+	if err := json.Unmarshal(embed_data.ModelDetails, &builtin); err != nil {
+		return nil, fmt.Errorf("error parsing the embedded model catalog: %v", err)
+	}
+
+	result := make(map[string]models.ModelDetails, len(builtin.ModelDetails))
+	for key, modelDetails := range builtin.ModelDetails {
+		modelDetails.Source = builtinSource
+		result[strings.ToLower(key)] = modelDetails
+	}
+
+	configDir, err := utils.GetCodaiConfigDir()
+	if err != nil {
+		return result, nil
+	}
+
+	userCatalogPath := filepath.Join(configDir, "models.json")
+	content, err := os.ReadFile(userCatalogPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return result, nil
+		}
+		return result, fmt.Errorf("failed to read model catalog %s: %w", userCatalogPath, err)
+	}
+
+	var userCatalog struct {
+		ModelDetails map[string]json.RawMessage `json:"models"`
+	}
+	if err := json.Unmarshal(content, &userCatalog); err != nil {
+		return result, fmt.Errorf("error parsing model catalog %s: %v", userCatalogPath, err)
+	}
+
+	for key, rawDetails := range userCatalog.ModelDetails {
+		key = strings.ToLower(key)
+		modelDetails := result[key]
+		if err := json.Unmarshal(rawDetails, &modelDetails); err != nil {
+			return result, fmt.Errorf("error parsing model '%s' of catalog %s: %v", key, userCatalogPath, err)
+		}
+		modelDetails.Source = userCatalogPath
+		result[key] = modelDetails
+	}
+
+	return result, nil
+}
+
+// ProviderScopes returns the prefixes of the catalog keys of a provider, e.g., "azure" for "azure-openai"
+func ProviderScopes(providerName string) []string {
+	providerName = strings.ToLower(providerName)
+	return append([]string{providerName}, providerScopes[providerName]...)
+}
+
+// ResolveModelDetails returns the catalog key and the details of a model of a provider, looking up the keys scoped
+// by the provider first, e.g., "azure/gpt-4o", then the name of model alone.
+func ResolveModelDetails(providerName string, modelName string) (string, models.ModelDetails, error) {
+	modelCatalog, err := GetModelCatalog()
+	if modelCatalog == nil {
+		return "", models.ModelDetails{}, err
+	}
+
+	modelName = strings.ToLower(modelName)
+
+	var keys []string
+	for _, scope := range ProviderScopes(providerName) {
+		keys = append(keys, scope+"/"+modelName)
+	}
+	keys = append(keys, modelName)
+
+	for _, key := range keys {
+		if modelDetails, exists := modelCatalog[key]; exists {
+			return key, modelDetails, nil
+		}
+	}
+
+	return "", models.ModelDetails{}, fmt.Errorf("model details price with name '%s' not found for provider '%s', add it to '~/.config/codai/models.json'", modelName, providerName)
+}
+
+// ModelCatalogKeys returns the sorted keys of the catalog, only the ones of a provider when it is not empty
+func ModelCatalogKeys(modelCatalog map[string]models.ModelDetails, providerName string) []string {
+	scopes := ProviderScopes(providerName)
+
+	var keys []string
+	for key, modelDetails := range modelCatalog {
+		if providerName != "" && !inProviderScope(key, modelDetails, scopes) {
+			continue
+		}
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+// inProviderScope reports whether a model of the catalog belongs to one of the scopes of a provider
+func inProviderScope(key string, modelDetails models.ModelDetails, scopes []string) bool {
+	for _, scope := range scopes {
+		if strings.HasPrefix(key, scope+"/") || strings.HasPrefix(modelDetails.Provider, scope) {
+			return true
+		}
+	}
+	return false
+}
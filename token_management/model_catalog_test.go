@@ -0,0 +1,90 @@
+package token_management
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/meysamhadeli/codai/token_management/models"
+	"github.com/stretchr/testify/assert"
+)
+
+// useUserCatalog writes a user catalog in a temporary home and parses the catalog again
+func useUserCatalog(t *testing.T, content string) {
+	homeDir := t.TempDir()
+	t.Setenv("HOME", homeDir)
+
+	configDir := filepath.Join(homeDir, ".config", "codai")
+	assert.NoError(t, os.MkdirAll(configDir, 0755))
+	assert.NoError(t, os.WriteFile(filepath.Join(configDir, "models.json"), []byte(content), 0644))
+
+	catalogOnce = sync.Once{}
+	t.Cleanup(func() {
+		catalogOnce = sync.Once{}
+	})
+}
+
+func TestResolveModelDetailsScopedByProvider(t *testing.T) {
+	useUserCatalog(t, `{"models": {}}`)
+
+	key, _, err := ResolveModelDetails("azure-openai", "gpt-4o")
+	assert.NoError(t, err)
+	assert.Equal(t, "azure/gpt-4o", key)
+
+	key, _, err = ResolveModelDetails("grok", "grok-2")
+	assert.NoError(t, err)
+	assert.Equal(t, "xai/grok-2", key)
+
+	key, _, err = ResolveModelDetails("openai", "GPT-4o")
+	assert.NoError(t, err)
+	assert.Equal(t, "gpt-4o", key)
+
+	_, _, err = ResolveModelDetails("ollama", "llama3.1")
+	assert.Error(t, err)
+}
+
+func TestUserCatalogOverridesEmbeddedCatalog(t *testing.T) {
+	useUserCatalog(t, `{"models": {
+		"ollama/llama3.1": {"max_input_tokens": 128000, "input_cost_per_token": 0.0000001},
+		"gpt-4o": {"input_cost_per_token": 0.000002}
+	}}`)
+
+	key, modelDetails, err := ResolveModelDetails("ollama", "llama3.1")
+	assert.NoError(t, err)
+	assert.Equal(t, "ollama/llama3.1", key)
+	assert.Equal(t, 128000, modelDetails.MaxInputTokens)
+
+	// Only the fields of the user model are overridden
+	_, modelDetails, err = ResolveModelDetails("openai", "gpt-4o")
+	assert.NoError(t, err)
+	assert.Equal(t, 0.000002, modelDetails.InputCostPerToken)
+	assert.Equal(t, 0.00001, modelDetails.OutputCostPerToken)
+}
+
+func TestBrokenUserCatalogKeepsEmbeddedCatalog(t *testing.T) {
+	useUserCatalog(t, `{"models": `)
+
+	_, err := GetModelCatalog()
+	assert.Error(t, err)
+
+	_, _, err = ResolveModelDetails("openai", "gpt-4o")
+	assert.NoError(t, err)
+}
+
+func TestCalculateCostWithPromptCache(t *testing.T) {
+	useUserCatalog(t, `{"models": {
+		"anthropic/claude-test": {"input_cost_per_token": 0.000003, "output_cost_per_token": 0.000015, "cache_read_input_token_cost": 0.0000003, "cache_creation_input_token_cost": 0.00000375},
+		"ollama/llama-test": {"input_cost_per_token": 0.000001, "output_cost_per_token": 0.000002}
+	}}`)
+
+	tokenManager := NewTokenManager()
+
+	// 1000 input tokens, 600 of them read from the cache and 300 written to it, and 100 output tokens
+	cost := tokenManager.CalculateCost("anthropic", "claude-test", models.Usage{InputTokens: 1000, CachedTokens: 600, CacheWriteTokens: 300, OutputTokens: 100})
+	assert.InDelta(t, 100*0.000003+600*0.0000003+300*0.00000375+100*0.000015, cost, 1e-12)
+
+	// A model without cache costs prices the cached tokens as the other input tokens
+	cost = tokenManager.CalculateCost("ollama", "llama-test", models.Usage{InputTokens: 1000, CachedTokens: 600, OutputTokens: 100})
+	assert.InDelta(t, 1000*0.000001+100*0.000002, cost, 1e-12)
+}
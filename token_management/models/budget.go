@@ -0,0 +1,16 @@
+package models
+
+// BudgetConfig limits the spending in dollars, a zero limit is disabled
+type BudgetConfig struct {
+	PerRequestUSD float64 `mapstructure:"per_request_usd"`
+	PerSessionUSD float64 `mapstructure:"per_session_usd"`
+	DailyUSD      float64 `mapstructure:"daily_usd"`
+	WarnThreshold float64 `mapstructure:"warn_threshold"` // Fraction of a limit from which a warning is shown, e.g., 0.8
+	OnExceed      string  `mapstructure:"on_exceed"`      // "confirm" to ask before a request above a limit, or "block" to skip it
+}
+
+// BudgetCheck holds the warnings of the limits near the threshold and the limits exceeded
+type BudgetCheck struct {
+	Warnings []string
+	Exceeded []string
+}
@@ -0,0 +1,23 @@
+package models
+
+// ModelDetails is the pricing and the capabilities of a model in the catalog
+type ModelDetails struct {
+	MaxTokens                int     `json:"max_tokens"`
+	MaxInputTokens           int     `json:"max_input_tokens"`
+	MaxOutputTokens          int     `json:"max_output_tokens"`
+	InputCostPerToken        float64 `json:"input_cost_per_token,omitempty"`
+	OutputCostPerToken       float64 `json:"output_cost_per_token,omitempty"`
+	CacheReadInputTokenCost  float64 `json:"cache_read_input_token_cost,omitempty"`
+	CacheWriteInputTokenCost float64 `json:"cache_creation_input_token_cost,omitempty"`
+	Provider                 string  `json:"litellm_provider,omitempty"`
+	Mode                     string  `json:"mode"`
+	SupportsFunctionCalling  bool    `json:"supports_function_calling,omitempty"`
+	SupportsVision           bool    `json:"supports_vision,omitempty"`
+	SupportsPromptCaching    bool    `json:"supports_prompt_caching,omitempty"`
+	Source                   string  `json:"-"` // "builtin" for the embedded catalog or the path of the user catalog
+}
+
+// ModelCatalog holds the details of the models by their key, e.g., "gpt-4o" or "azure/gpt-4o"
+type ModelCatalog struct {
+	ModelDetails map[string]ModelDetails `json:"models"`
+}
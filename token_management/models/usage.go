@@ -0,0 +1,29 @@
+package models
+
+import "time"
+
+// Usage is the tokens and cost of a turn or a session
+type Usage struct {
+	InputTokens      int     `json:"input_tokens"`
+	OutputTokens     int     `json:"output_tokens"`
+	CachedTokens     int     `json:"cached_tokens,omitempty"`      // Input tokens read from the prompt cache of provider
+	CacheWriteTokens int     `json:"cache_write_tokens,omitempty"` // Input tokens written to the prompt cache of provider, e.g., by Anthropic
+	ReasoningTokens  int     `json:"reasoning_tokens,omitempty"`   // Output tokens spent on reasoning, already counted in the output tokens
+	Cost             float64 `json:"cost"`
+}
+
+// LedgerEntry is the usage of a turn, appended to '~/.config/codai/usage.jsonl' for the usage reports
+type LedgerEntry struct {
+	Time     time.Time `json:"time"`
+	Project  string    `json:"project"`
+	Provider string    `json:"provider"`
+	Model    string    `json:"model"`
+	Usage
+}
+
+// UsageSummary is the usage of the turns of a model or a project in a usage report
+type UsageSummary struct {
+	Key   string
+	Turns int
+	Usage
+}
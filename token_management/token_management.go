@@ -1,80 +1,139 @@
 package token_management
 
 import (
-	"encoding/json"
 	"fmt"
 	"github.com/meysamhadeli/codai/constants/lipgloss"
-	"github.com/meysamhadeli/codai/embed_data"
 	"github.com/meysamhadeli/codai/token_management/contracts"
-	"log"
-	"strings"
+	"github.com/meysamhadeli/codai/token_management/models"
 )
 
 // TokenManager implementation
 type tokenManager struct {
-	usedToken       int
-	usedInputToken  int
-	usedOutputToken int
+	session   models.Usage // Tokens used in the session
+	turnStart models.Usage // Tokens used in the session before the current turn
 }
 
-type details struct {
-	MaxTokens               int     `json:"max_tokens"`
-	MaxInputTokens          int     `json:"max_input_tokens"`
-	MaxOutputTokens         int     `json:"max_output_tokens"`
-	InputCostPerToken       float64 `json:"input_cost_per_token,omitempty"`
-	OutputCostPerToken      float64 `json:"output_cost_per_token,omitempty"`
-	CacheReadInputTokenCost float64 `json:"cache_read_input_token_cost,omitempty"`
-	Mode                    string  `json:"mode"`
-	SupportsFunctionCalling bool    `json:"supports_function_calling,omitempty"`
+// NewTokenManager creates a new token manager
+func NewTokenManager() contracts.ITokenManagement {
+	return &tokenManager{}
 }
 
-type Models struct {
-	ModelDetails map[string]details `json:"models"`
+// UsedTokens adds the input and output tokens of a request to the session.
+func (tm *tokenManager) UsedTokens(inputToken int, outputToken int) {
+	tm.session.InputTokens += inputToken
+	tm.session.OutputTokens += outputToken
 }
 
-// NewTokenManager creates a new token manager
-func NewTokenManager() contracts.ITokenManagement {
-	return &tokenManager{
-		usedToken:       0,
-		usedInputToken:  0,
-		usedOutputToken: 0,
+// UsedReasoningTokens records the reasoning (thinking) tokens of a request.
+// Providers already bill them as output tokens, so they are tracked apart and not added to the output tokens again.
+func (tm *tokenManager) UsedReasoningTokens(reasoningToken int) {
+	tm.session.ReasoningTokens += reasoningToken
+}
+
+// UsedCachedTokens records the input tokens of a request read from the prompt cache of provider.
+// They are part of the input tokens, so they are tracked apart and not added to the input tokens again.
+func (tm *tokenManager) UsedCachedTokens(cachedToken int) {
+	tm.session.CachedTokens += cachedToken
+}
+
+// UsedCacheWriteTokens records the input tokens of a request written to the prompt cache of provider.
+// They are part of the input tokens, so they are tracked apart and not added to the input tokens again.
+func (tm *tokenManager) UsedCacheWriteTokens(cacheWriteToken int) {
+	tm.session.CacheWriteTokens += cacheWriteToken
+}
+
+// StartTurn starts counting the tokens of a new turn.
+func (tm *tokenManager) StartTurn() {
+	tm.turnStart = tm.session
+}
+
+// TurnUsage returns the tokens used since the start of the current turn and their cost.
+func (tm *tokenManager) TurnUsage(chatProviderName string, chatModel string) models.Usage {
+	usage := models.Usage{
+		InputTokens:      max(tm.session.InputTokens-tm.turnStart.InputTokens, 0),
+		OutputTokens:     max(tm.session.OutputTokens-tm.turnStart.OutputTokens, 0),
+		CachedTokens:     max(tm.session.CachedTokens-tm.turnStart.CachedTokens, 0),
+		CacheWriteTokens: max(tm.session.CacheWriteTokens-tm.turnStart.CacheWriteTokens, 0),
+		ReasoningTokens:  max(tm.session.ReasoningTokens-tm.turnStart.ReasoningTokens, 0),
 	}
+	usage.Cost = tm.CalculateCost(chatProviderName, chatModel, usage)
+
+	return usage
 }
 
-// UsedTokens deducts the token count from the available tokens.
-func (tm *tokenManager) UsedTokens(inputToken int, outputToken int) {
-	tm.usedInputToken = inputToken
-	tm.usedOutputToken = outputToken
+// SessionUsage returns the tokens used in the session and their cost.
+func (tm *tokenManager) SessionUsage(chatProviderName string, chatModel string) models.Usage {
+	usage := tm.session
+	usage.Cost = tm.CalculateCost(chatProviderName, chatModel, usage)
 
-	tm.usedToken += inputToken + outputToken
+	return usage
 }
 
+// DisplayTokens shows the tokens and cost of the last turn and of the whole session.
 func (tm *tokenManager) DisplayTokens(chatProviderName string, chatModel string) {
+	turn := tm.TurnUsage(chatProviderName, chatModel)
+	session := tm.SessionUsage(chatProviderName, chatModel)
 
-	cost := tm.CalculateCost(chatProviderName, chatModel, tm.usedInputToken, tm.usedOutputToken)
+	tokenInfo := fmt.Sprintf("Turn: %s - Cost: %.6f $\nSession: %s - Cost: %.6f $ - Chat Model: %s",
+		formatUsage(turn), turn.Cost, formatUsage(session), session.Cost, chatModel)
 
-	tokenInfo := fmt.Sprintf("Token Used: %s - Cost: %s $ - Chat Model: %s", fmt.Sprint(tm.usedToken), fmt.Sprintf("%.6f", cost), chatModel)
+	// A model missing from the catalog has no price, so its cost is unknown rather than free
+	if _, _, err := ResolveModelDetails(chatProviderName, chatModel); err != nil {
+		tokenInfo = fmt.Sprintf("Turn: %s\nSession: %s - Cost: unknown, add the model to '~/.config/codai/models.json' - Chat Model: %s",
+			formatUsage(turn), formatUsage(session), chatModel)
+	}
 
 	tokenBox := lipgloss.BoxStyle.Render(tokenInfo)
 	fmt.Println(tokenBox)
 }
 
+// formatUsage describes the tokens of a usage, with the cached and reasoning tokens when there are some.
+func formatUsage(usage models.Usage) string {
+	info := fmt.Sprintf("Token Used: %d (Input: %d", usage.InputTokens+usage.OutputTokens, usage.InputTokens)
+	if usage.CachedTokens > 0 {
+		info += fmt.Sprintf(", Cached: %d", usage.CachedTokens)
+	}
+	if usage.CacheWriteTokens > 0 {
+		info += fmt.Sprintf(", Cache Write: %d", usage.CacheWriteTokens)
+	}
+	info += fmt.Sprintf(", Output: %d", usage.OutputTokens)
+	if usage.ReasoningTokens > 0 {
+		info += fmt.Sprintf(", Reasoning: %d", usage.ReasoningTokens)
+	}
+
+	return info + ")"
+}
+
 func (tm *tokenManager) ClearToken() {
-	tm.usedToken = 0
-	tm.usedInputToken = 0
-	tm.usedOutputToken = 0
+	tm.session = models.Usage{}
+	tm.turnStart = models.Usage{}
 }
 
-func (tm *tokenManager) CalculateCost(providerName string, modelName string, inputToken int, outputToken int) float64 {
-	modelDetails, err := getModelDetails(providerName, modelName)
+// CalculateCost returns the cost of a usage, the input tokens read from or written to the prompt cache are priced
+// with the cache costs of the model, or as the other input tokens when the model has no cache cost.
+func (tm *tokenManager) CalculateCost(providerName string, modelName string, usage models.Usage) float64 {
+	_, modelDetails, err := ResolveModelDetails(providerName, modelName)
 	if err != nil {
 		return 0
 	}
-	// Calculate cost for input tokens
-	inputCost := float64(inputToken) * modelDetails.InputCostPerToken
+
+	cacheReadCost := modelDetails.CacheReadInputTokenCost
+	if cacheReadCost == 0 {
+		cacheReadCost = modelDetails.InputCostPerToken
+	}
+	cacheWriteCost := modelDetails.CacheWriteInputTokenCost
+	if cacheWriteCost == 0 {
+		cacheWriteCost = modelDetails.InputCostPerToken
+	}
+
+	// Calculate cost for input tokens, apart from the cached ones
+	uncachedInputTokens := max(usage.InputTokens-usage.CachedTokens-usage.CacheWriteTokens, 0)
+	inputCost := float64(uncachedInputTokens)*modelDetails.InputCostPerToken +
+		float64(usage.CachedTokens)*cacheReadCost +
+		float64(usage.CacheWriteTokens)*cacheWriteCost
 
 	// Calculate cost for output tokens
-	outputCost := float64(outputToken) * modelDetails.OutputCostPerToken
+	outputCost := float64(usage.OutputTokens) * modelDetails.OutputCostPerToken
 
 	// Total cost
 	totalCost := inputCost + outputCost
@@ -82,32 +141,12 @@ func (tm *tokenManager) CalculateCost(providerName string, modelName string, inp
 	return totalCost
 }
 
-func getModelDetails(providerName string, modelName string) (details, error) {
-
-	providerName = strings.ToLower(providerName)
-	modelName = strings.ToLower(modelName)
-
-	if strings.HasPrefix(providerName, "azure") {
-		modelName = "azure/" + modelName
-	}
-
-	// Initialize the Models struct to hold parsed JSON data
-	models := Models{
-		ModelDetails: make(map[string]details),
-	}
-
-	// Unmarshal the JSON data from the embedded file
-	err := json.Unmarshal(embed_data.ModelDetails, &models)
+// SupportsVision reports whether the model accepts image input, it returns an error if the model is not in the catalog.
+func (tm *tokenManager) SupportsVision(providerName string, modelName string) (bool, error) {
+	_, modelDetails, err := ResolveModelDetails(providerName, modelName)
 	if err != nil {
-		log.Fatalf("Error unmarshaling JSON: %v", err)
-		return details{}, err
-	}
-
-	// Look up the model by name
-	model, exists := models.ModelDetails[modelName]
-	if !exists {
-		return details{}, fmt.Errorf("model details price with name '%s' not found for provider '%s'", modelName, providerName)
+		return false, err
 	}
 
-	return model, nil
+	return modelDetails.SupportsVision, nil
 }
@@ -0,0 +1,174 @@
+package token_management
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/meysamhadeli/codai/token_management/models"
+	"github.com/meysamhadeli/codai/utils"
+)
+
+// Group keys of the usage reports
+const (
+	GroupByModel   = "model"
+	GroupByProject = "project"
+)
+
+// getUsageLedgerPath returns the path of the ledger of usage, '~/.config/codai/usage.jsonl'
+func getUsageLedgerPath() (string, error) {
+	configDir, err := utils.GetCodaiConfigDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(configDir, "usage.jsonl"), nil
+}
+
+// RecordUsage appends the usage of a turn to the ledger, a turn without tokens is not recorded
+func RecordUsage(project string, provider string, model string, usage models.Usage) error {
+	if usage.InputTokens == 0 && usage.OutputTokens == 0 {
+		return nil
+	}
+
+	path, err := getUsageLedgerPath()
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create usage ledger directory: %w", err)
+	}
+
+	data, err := json.Marshal(models.LedgerEntry{
+		Time:     time.Now(),
+		Project:  project,
+		Provider: provider,
+		Model:    model,
+		Usage:    usage,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal usage: %w", err)
+	}
+
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open usage ledger: %w", err)
+	}
+	defer file.Close()
+
+	if _, err := file.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write usage ledger: %w", err)
+	}
+
+	return nil
+}
+
+// ReadUsage returns the entries of the ledger recorded since a time
+func ReadUsage(since time.Time) ([]models.LedgerEntry, error) {
+	path, err := getUsageLedgerPath()
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to open usage ledger: %w", err)
+	}
+	defer file.Close()
+
+	var entries []models.LedgerEntry
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var entry models.LedgerEntry
+		// Skip a line broken by an interrupted write
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			continue
+		}
+		if !entry.Time.Before(since) {
+			entries = append(entries, entry)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read usage ledger: %w", err)
+	}
+
+	return entries, nil
+}
+
+// SummarizeUsage sums the usage of the entries by model or by project, the most expensive first
+func SummarizeUsage(entries []models.LedgerEntry, groupBy string) ([]models.UsageSummary, error) {
+	summaries := make(map[string]*models.UsageSummary)
+
+	for _, entry := range entries {
+		var key string
+		switch groupBy {
+		case GroupByModel:
+			key = entry.Provider + "/" + entry.Model
+		case GroupByProject:
+			key = entry.Project
+		default:
+			return nil, fmt.Errorf("unsupported group '%s', use 'model' or 'project'", groupBy)
+		}
+
+		summary, exists := summaries[key]
+		if !exists {
+			summary = &models.UsageSummary{Key: key}
+			summaries[key] = summary
+		}
+
+		summary.Turns++
+		summary.InputTokens += entry.InputTokens
+		summary.OutputTokens += entry.OutputTokens
+		summary.CachedTokens += entry.CachedTokens
+		summary.CacheWriteTokens += entry.CacheWriteTokens
+		summary.ReasoningTokens += entry.ReasoningTokens
+		summary.Cost += entry.Cost
+	}
+
+	var result []models.UsageSummary
+	for _, summary := range summaries {
+		result = append(result, *summary)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Cost != result[j].Cost {
+			return result[i].Cost > result[j].Cost
+		}
+		return result[i].Key < result[j].Key
+	})
+
+	return result, nil
+}
+
+// ParseSince parses the start of a usage report, a number of days like '7d', a duration like '12h' or a date like '2025-01-31'
+func ParseSince(since string, now time.Time) (time.Time, error) {
+	since = strings.TrimSpace(since)
+	if since == "" {
+		return time.Time{}, nil
+	}
+
+	if days, found := strings.CutSuffix(since, "d"); found {
+		if count, err := strconv.Atoi(days); err == nil && count >= 0 {
+			return now.AddDate(0, 0, -count), nil
+		}
+	}
+
+	if duration, err := time.ParseDuration(since); err == nil {
+		return now.Add(-duration), nil
+	}
+
+	if date, err := time.ParseInLocation("2006-01-02", since, time.Local); err == nil {
+		return date, nil
+	}
+
+	return time.Time{}, fmt.Errorf("invalid since '%s', use a number of days like '7d', a duration like '12h' or a date like '2025-01-31'", since)
+}
@@ -0,0 +1,55 @@
+package token_management
+
+import (
+	"testing"
+	"time"
+
+	"github.com/meysamhadeli/codai/token_management/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSummarizeUsage(t *testing.T) {
+	entries := []models.LedgerEntry{
+		{Project: "/work/api", Provider: "openai", Model: "gpt-4o", Usage: models.Usage{InputTokens: 100, OutputTokens: 10, CachedTokens: 50, Cost: 0.5}},
+		{Project: "/work/web", Provider: "openai", Model: "gpt-4o", Usage: models.Usage{InputTokens: 200, OutputTokens: 20, Cost: 1}},
+		{Project: "/work/api", Provider: "anthropic", Model: "claude-3-5-haiku-latest", Usage: models.Usage{InputTokens: 10, OutputTokens: 5, ReasoningTokens: 2, Cost: 0.1}},
+	}
+
+	byModel, err := SummarizeUsage(entries, GroupByModel)
+	assert.NoError(t, err)
+	assert.Equal(t, []models.UsageSummary{
+		{Key: "openai/gpt-4o", Turns: 2, Usage: models.Usage{InputTokens: 300, OutputTokens: 30, CachedTokens: 50, Cost: 1.5}},
+		{Key: "anthropic/claude-3-5-haiku-latest", Turns: 1, Usage: models.Usage{InputTokens: 10, OutputTokens: 5, ReasoningTokens: 2, Cost: 0.1}},
+	}, byModel)
+
+	byProject, err := SummarizeUsage(entries, GroupByProject)
+	assert.NoError(t, err)
+	assert.Equal(t, "/work/web", byProject[0].Key)
+	assert.Equal(t, 2, byProject[1].Turns)
+
+	_, err = SummarizeUsage(entries, "day")
+	assert.Error(t, err)
+}
+
+func TestParseSince(t *testing.T) {
+	now := time.Date(2025, 3, 10, 12, 0, 0, 0, time.Local)
+
+	since, err := ParseSince("7d", now)
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2025, 3, 3, 12, 0, 0, 0, time.Local), since)
+
+	since, err = ParseSince("12h", now)
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2025, 3, 10, 0, 0, 0, 0, time.Local), since)
+
+	since, err = ParseSince("2025-01-31", now)
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2025, 1, 31, 0, 0, 0, 0, time.Local), since)
+
+	since, err = ParseSince("", now)
+	assert.NoError(t, err)
+	assert.True(t, since.IsZero())
+
+	_, err = ParseSince("last week", now)
+	assert.Error(t, err)
+}
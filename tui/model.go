@@ -0,0 +1,718 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/cursor"
+	"github.com/charmbracelet/bubbles/textarea"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	contracts_history "github.com/meysamhadeli/codai/chat_history/contracts"
+	history_models "github.com/meysamhadeli/codai/chat_history/models"
+	"github.com/meysamhadeli/codai/code_analyzer"
+	contracts_analyzer "github.com/meysamhadeli/codai/code_analyzer/contracts"
+	"github.com/meysamhadeli/codai/code_analyzer/models"
+	"github.com/meysamhadeli/codai/config"
+	contracts_provider "github.com/meysamhadeli/codai/providers/contracts"
+	provider_models "github.com/meysamhadeli/codai/providers/models"
+	contracts_commands "github.com/meysamhadeli/codai/session_commands/contracts"
+	"github.com/meysamhadeli/codai/token_management"
+	contracts_token "github.com/meysamhadeli/codai/token_management/contracts"
+	"github.com/meysamhadeli/codai/utils"
+	"github.com/pmezard/go-difflib/difflib"
+)
+
+const (
+	sidebarWidth = 32
+	inputHeight  = 3
+
+	// doublePressInterval is the time between two Ctrl+C to quit while a request is in flight
+	doublePressInterval = time.Second
+)
+
+// Dependencies holds the services of the session used by the TUI
+type Dependencies struct {
+	ChatProvider    contracts_provider.IChatAIProvider
+	Analyzer        contracts_analyzer.ICodeAnalyzer
+	ChatHistory     contracts_history.IChatHistory
+	TokenManagement contracts_token.ITokenManagement
+	Config          *config.Config
+	Cwd             string
+	FullContext     *models.FullContextData
+	SlashCommands   map[string]utils.SlashCommand
+	Commands        contracts_commands.ICommandRegistry // The ':' commands of the session, the same as in the line mode
+
+	PendingImages    func() []provider_models.Image // Returns and clears the images attached with ':image'
+	SetLastReasoning func(reasoning string)         // Keeps the reasoning of the last answer for ':reasoning'
+}
+
+// responseMsg carries a chunk of the answer, closed is set when the response channel is closed
+type responseMsg struct {
+	responseChan <-chan provider_models.StreamResponse
+	response     provider_models.StreamResponse
+	closed       bool
+}
+
+// Model is the full-screen code session with a chat pane, a context sidebar, a diff-review pane and a status bar
+type Model struct {
+	deps   Dependencies
+	ctx    context.Context
+	width  int
+	height int
+
+	chat  viewport.Model
+	diff  viewport.Model
+	input textarea.Model
+
+	transcript strings.Builder
+	answer     strings.Builder
+	reasoning  strings.Builder
+	userInput  string
+	pinned     []string
+	images     []provider_models.Image // Images attached with ':image' until a request is sent
+	notice     string
+
+	budgetConfirmed string // Input confirmed to be sent above a limit of budget
+	visionConfirmed string // Input confirmed to send images to a model with unknown vision support
+	partialAnswer   string // Answer of a canceled request, kept in the history or discarded on the choice of the user
+
+	responseChan  <-chan provider_models.StreamResponse
+	cancelRequest context.CancelFunc
+	streaming     bool
+
+	changes     []models.CodeChange
+	changeIndex int
+
+	turn history_models.Turn
+
+	lastInterrupt time.Time
+}
+
+// NewModel creates the TUI model of a code session
+func NewModel(ctx context.Context, deps Dependencies) *Model {
+	input := textarea.New()
+	input.Placeholder = "Ask codai, mention files like @path/to/file.go (alt+enter for a new line)"
+	input.Prompt = "> "
+	input.ShowLineNumbers = false
+	input.CharLimit = 0
+	input.KeyMap.InsertNewline.SetKeys("alt+enter")
+	input.Cursor.SetMode(cursor.CursorStatic)
+	input.Focus()
+
+	return &Model{
+		deps:  deps,
+		ctx:   ctx,
+		chat:  viewport.New(0, 0),
+		diff:  viewport.New(0, 0),
+		input: input,
+	}
+}
+
+// SetFullContext replaces the files in context, e.g., after ':scope' changes the directories of the context
+func (m *Model) SetFullContext(fullContext *models.FullContextData) {
+	m.deps.FullContext = fullContext
+}
+
+func (m *Model) Init() tea.Cmd {
+	return nil
+}
+
+func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width, m.height = msg.Width, msg.Height
+		m.layout()
+		m.refreshChat()
+		return m, nil
+
+	case responseMsg:
+		return m, m.handleResponse(msg)
+
+	case tea.KeyMsg:
+		switch {
+		case msg.Type == tea.KeyCtrlC:
+			// The first Ctrl+C during a request cancels only that request, a second one in a row quits
+			doublePress := time.Since(m.lastInterrupt) < doublePressInterval
+			m.lastInterrupt = time.Now()
+			if !m.streaming || doublePress {
+				m.cancel()
+				return m, tea.Quit
+			}
+			m.notice = "Request canceled, press Ctrl+C again to quit."
+			m.finishAnswer(true)
+			return m, nil
+		case msg.Type == tea.KeyEsc && m.streaming:
+			// The remaining chunks of the canceled request are dropped, the partial answer can be kept
+			m.notice = "Request canceled."
+			m.finishAnswer(true)
+			return m, nil
+		case msg.Type == tea.KeyPgUp, msg.Type == tea.KeyPgDown:
+			var cmd tea.Cmd
+			m.chat, cmd = m.chat.Update(msg)
+			return m, cmd
+		case m.partialAnswer != "":
+			return m, m.handleKeepAnswer(msg)
+		case m.reviewing():
+			return m, m.handleReview(msg)
+		case msg.Type == tea.KeyEnter && !m.streaming:
+			return m, m.submit()
+		}
+	}
+
+	var cmd tea.Cmd
+	m.input, cmd = m.input.Update(msg)
+	return m, cmd
+}
+
+func (m *Model) View() string {
+	if m.width == 0 {
+		return "Loading..."
+	}
+
+	right := paneStyle.Render(m.chat.View())
+	if m.reviewing() {
+		change := m.changes[m.changeIndex]
+		title := titleStyle.Render(fmt.Sprintf("Review %d/%d: %s (y accept / n reject)", m.changeIndex+1, len(m.changes), change.RelativePath))
+		right = lipgloss.JoinVertical(lipgloss.Left, right, paneStyle.Render(title+"\n"+m.diff.View()))
+	}
+
+	main := lipgloss.JoinHorizontal(lipgloss.Top, paneStyle.Render(m.sidebarView()), right)
+
+	return lipgloss.JoinVertical(lipgloss.Left, main, inputStyle.Render(m.input.View()), m.statusView())
+}
+
+// layout sizes the panes to the window, the chat pane shares its space with the diff pane while reviewing
+func (m *Model) layout() {
+	// Borders take two lines and two columns of each pane
+	mainHeight := max(m.height-inputHeight-2-1-2, 1)
+	rightWidth := max(m.width-sidebarWidth-2-2, 1)
+
+	m.chat.Width = rightWidth
+	m.chat.Height = mainHeight
+	if m.reviewing() {
+		m.chat.Height = max(mainHeight/2-1, 1)
+		m.diff.Width = rightWidth
+		m.diff.Height = max(mainHeight-m.chat.Height-2-1, 1)
+	}
+
+	m.input.SetWidth(m.width - 2)
+	m.input.SetHeight(inputHeight)
+}
+
+// submit sends the user input to the AI provider, or runs it when it's a command
+func (m *Model) submit() tea.Cmd {
+	userInput := strings.TrimSpace(m.input.Value())
+	if userInput == "" {
+		return nil
+	}
+	m.input.Reset()
+
+	// ':clear' clears the chat pane, the other commands run like in the line mode with their output in the chat pane
+	if userInput == ":clear" {
+		m.transcript.Reset()
+		m.refreshChat()
+		return nil
+	}
+	if cmd, isCommand := m.runCommand(userInput); isCommand {
+		return cmd
+	}
+
+	rawInput := userInput
+
+	// Expand user commands like '/test path/to/file.go' into their prompt, other inputs like '/etc/hosts why?' are sent as is
+	if command, args, ok := utils.ParseSlashCommandInput(userInput, m.deps.SlashCommands); ok {
+		userInput = command.Expand(m.deps.Cwd, args)
+	}
+
+	// Attach the images queued with ':image' and the images mentioned like '@screenshot.png'
+	m.notice = ""
+	m.images = append(m.images, m.deps.PendingImages()...)
+	images := slices.Clone(m.images)
+	var mentions []string
+	for _, mention := range utils.ExtractMentions(userInput) {
+		if !utils.IsImageFile(mention) {
+			mentions = append(mentions, mention)
+			continue
+		}
+		image, err := utils.LoadImage(mention)
+		if err != nil {
+			m.notice = err.Error()
+			continue
+		}
+		images = append(images, *image)
+	}
+
+	if len(images) > 0 && !m.confirmVision(rawInput) {
+		m.input.SetValue(rawInput)
+		return nil
+	}
+
+	// Pin the full content of files mentioned like '@path/to/file.go', '@dir/' or '@FuncName'
+	var mentionedContext string
+	if len(mentions) > 0 {
+		var unresolved []string
+		mentionedContext, unresolved = m.deps.Analyzer.ResolveMentions(mentions, m.deps.FullContext)
+		for _, mention := range mentions {
+			if !slices.Contains(unresolved, mention) && !slices.Contains(m.pinned, mention) {
+				m.pinned = append(m.pinned, mention)
+			}
+		}
+		if len(unresolved) > 0 {
+			m.notice = fmt.Sprintf("No file, directory or symbol found for: @%s", strings.Join(unresolved, ", @"))
+		}
+	}
+
+	var rawCodes []string
+	if m.deps.FullContext != nil {
+		rawCodes = m.deps.Analyzer.RepoMap(m.deps.FullContext)
+	}
+
+	finalPrompt, userInputPrompt := m.deps.Analyzer.GeneratePrompt(rawCodes, m.deps.ChatHistory.GetHistory(), userInput, mentionedContext)
+
+	// Check the limits of budget with the estimated cost of the request, keep the input when it is not sent
+	if !m.confirmRequestBudget(rawInput, finalPrompt+userInputPrompt) {
+		m.input.SetValue(rawInput)
+		return nil
+	}
+
+	m.deps.TokenManagement.StartTurn()
+
+	ctx, cancel := context.WithCancel(m.ctx)
+	m.cancelRequest = cancel
+	m.responseChan = m.deps.ChatProvider.ChatCompletionRequest(ctx, userInputPrompt, finalPrompt, images)
+	m.streaming = true
+	m.userInput = userInput
+	m.images = nil
+	m.answer.Reset()
+	m.reasoning.Reset()
+
+	m.transcript.WriteString(userStyle.Render("> "+userInput) + "\n\n")
+	m.refreshChat()
+
+	return waitForResponse(m.responseChan)
+}
+
+// handleResponse appends a chunk to the answer and waits for the next one until the answer is done
+func (m *Model) handleResponse(msg responseMsg) tea.Cmd {
+	// Chunks of a canceled request are dropped
+	if !m.streaming || msg.responseChan != m.responseChan {
+		return nil
+	}
+
+	if msg.closed {
+		m.finishAnswer(false)
+		return nil
+	}
+
+	if msg.response.Err != nil {
+		m.notice = msg.response.Err.Error()
+		m.finishAnswer(false)
+		return nil
+	}
+
+	// Reasoning is shown dimmed above the answer and kept out of the answer and the history
+	if msg.response.ReasoningContent != "" {
+		m.reasoning.WriteString(msg.response.ReasoningContent)
+		m.refreshChat()
+		return waitForResponse(m.responseChan)
+	}
+
+	m.answer.WriteString(msg.response.Content)
+	m.refreshChat()
+
+	if msg.response.Done {
+		m.deps.ChatHistory.AddToHistory(m.userInput, m.answer.String())
+		m.finishAnswer(false)
+		return nil
+	}
+
+	return waitForResponse(m.responseChan)
+}
+
+// finishAnswer moves the answer to the transcript and starts the review of its code changes
+func (m *Model) finishAnswer(canceled bool) {
+	m.cancel()
+	m.streaming = false
+
+	answer := m.answer.String()
+	m.answer.Reset()
+	m.transcript.WriteString(m.reasoningView() + utils.HighlightMarkdown(answer, m.deps.Config.Theme) + "\n\n")
+
+	m.deps.SetLastReasoning(m.reasoning.String())
+	m.reasoning.Reset()
+
+	m.turn = history_models.Turn{Time: time.Now(), UserInput: m.userInput, Answer: answer, Canceled: canceled}
+
+	// The partial answer of a canceled request is added to the history only if the user keeps it, like in the line mode
+	if canceled && answer != "" {
+		m.partialAnswer = answer
+		m.notice = "Request canceled, keep the partial answer? (y/n)"
+		m.input.Blur()
+		m.layout()
+		m.refreshChat()
+		return
+	}
+
+	m.reviewAnswer(answer, canceled)
+}
+
+// handleKeepAnswer keeps the partial answer of a canceled request in the history or discards it, then reviews its
+// code changes
+func (m *Model) handleKeepAnswer(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "y", "Y":
+		m.deps.ChatHistory.AddToHistory(m.userInput, m.partialAnswer)
+		m.notice = "Partial answer kept in the history."
+	case "n", "N":
+		m.notice = "Partial answer discarded."
+	default:
+		return nil
+	}
+
+	answer := m.partialAnswer
+	m.partialAnswer = ""
+	m.reviewAnswer(answer, true)
+
+	if m.reviewing() {
+		return nil
+	}
+	return m.input.Focus()
+}
+
+// reviewAnswer starts the review of the code changes of the answer, or records the turn without changes
+func (m *Model) reviewAnswer(answer string, canceled bool) {
+	m.changes = m.deps.Analyzer.ExtractCodeChanges(answer)
+	m.changeIndex = 0
+	if m.reviewing() {
+		m.input.Blur()
+		m.loadDiff()
+	} else if answer != "" || canceled {
+		m.recordTurn()
+	}
+
+	m.layout()
+	m.refreshChat()
+}
+
+// runCommand runs the input when it's a command of the session, e.g., ':help' or an external ':<cmd>', and shows
+// its output in the chat pane
+func (m *Model) runCommand(userInput string) (tea.Cmd, bool) {
+	if !strings.HasPrefix(userInput, ":") {
+		return nil, false
+	}
+
+	var isCommand, exit bool
+	output, err := utils.CaptureStdout(func() {
+		isCommand, exit = m.deps.Commands.Execute(userInput)
+	})
+	if err != nil {
+		m.notice = err.Error()
+		return nil, true
+	}
+
+	if exit {
+		return tea.Quit, true
+	}
+	if !isCommand {
+		return nil, false
+	}
+
+	m.notice = ""
+	m.transcript.WriteString(userStyle.Render("> "+userInput) + "\n\n")
+	if output = strings.TrimRight(output, "\n"); output != "" {
+		m.transcript.WriteString(output + "\n\n")
+	}
+	m.refreshChat()
+
+	return nil, true
+}
+
+// handleReview accepts or rejects the current code change and moves to the next one
+func (m *Model) handleReview(msg tea.KeyMsg) tea.Cmd {
+	change := m.changes[m.changeIndex]
+
+	record := history_models.CodeChangeRecord{RelativePath: change.RelativePath, Code: change.Code}
+
+	switch msg.String() {
+	case "y", "Y":
+		if err := m.deps.Analyzer.ApplyChanges(change.RelativePath, change.Code); err != nil {
+			m.notice = fmt.Sprintf("Error applying changes: %v", err)
+		} else {
+			record.Accepted = true
+			m.notice = fmt.Sprintf("Changes accepted for %s", change.RelativePath)
+		}
+	case "n", "N":
+		m.notice = fmt.Sprintf("Changes rejected for %s", change.RelativePath)
+	default:
+		var cmd tea.Cmd
+		m.diff, cmd = m.diff.Update(msg)
+		return cmd
+	}
+
+	m.turn.CodeChanges = append(m.turn.CodeChanges, record)
+
+	m.changeIndex++
+	if !m.reviewing() {
+		m.recordTurn()
+		m.changes = nil
+		m.changeIndex = 0
+		m.layout()
+		m.refreshChat()
+		return m.input.Focus()
+	}
+
+	m.loadDiff()
+	return nil
+}
+
+// recordTurn records the finished turn for the export of session and the usage ledger, with the tokens and cost used since the request
+func (m *Model) recordTurn() {
+	provider := m.deps.Config.AIProviderConfig.Provider
+	model := m.deps.Config.AIProviderConfig.Model
+
+	usage := m.deps.TokenManagement.TurnUsage(provider, model)
+	m.turn.InputTokens = usage.InputTokens
+	m.turn.OutputTokens = usage.OutputTokens
+	m.turn.CachedTokens = usage.CachedTokens
+	m.turn.CacheWriteTokens = usage.CacheWriteTokens
+	m.turn.ReasoningTokens = usage.ReasoningTokens
+	m.turn.Cost = usage.Cost
+
+	if err := token_management.RecordUsage(m.deps.Cwd, provider, model, usage); err != nil {
+		m.notice = err.Error()
+	}
+
+	if err := m.deps.ChatHistory.RecordTurn(m.turn); err != nil {
+		m.notice = err.Error()
+	}
+
+	check := m.deps.TokenManagement.CheckBudget(m.deps.Config.Budget, provider, model)
+	if messages := append(check.Exceeded, check.Warnings...); len(messages) > 0 {
+		m.notice = messages[0]
+	}
+}
+
+// confirmRequestBudget checks the limits of budget before sending a request, a request above a limit is blocked,
+// or sent when the same input is submitted again, depending on 'budget.on_exceed'
+func (m *Model) confirmRequestBudget(input string, prompt string) bool {
+	provider := m.deps.Config.AIProviderConfig.Provider
+	model := m.deps.Config.AIProviderConfig.Model
+	budget := m.deps.Config.Budget
+
+	estimatedCost := m.deps.TokenManagement.EstimateRequestCost(provider, model, prompt)
+	check := m.deps.TokenManagement.PreflightBudget(budget, provider, model, estimatedCost)
+
+	switch {
+	case len(check.Exceeded) == 0:
+		if len(check.Warnings) > 0 {
+			m.notice = check.Warnings[0]
+		}
+		m.budgetConfirmed = ""
+		return true
+	case budget.OnExceed == token_management.BudgetBlock:
+		m.notice = check.Exceeded[0] + " The request is blocked by the budget."
+		return false
+	case m.budgetConfirmed == input:
+		m.budgetConfirmed = ""
+		return true
+	default:
+		m.budgetConfirmed = input
+		m.notice = check.Exceeded[0] + " Press enter again to send it anyway."
+		return false
+	}
+}
+
+// confirmVision checks the model catalog before sending images, they are refused for a model without vision, and
+// sent for a model missing from the catalog when the same input is submitted again
+func (m *Model) confirmVision(input string) bool {
+	provider := m.deps.Config.AIProviderConfig.Provider
+	model := m.deps.Config.AIProviderConfig.Model
+
+	supportsVision, err := m.deps.TokenManagement.SupportsVision(provider, model)
+
+	switch {
+	case err == nil && supportsVision:
+		m.visionConfirmed = ""
+		return true
+	case err == nil:
+		m.notice = fmt.Sprintf("Model '%s' is not vision-capable, choose a vision model with '--model' to send images.", model)
+		return false
+	case m.visionConfirmed == input:
+		m.visionConfirmed = ""
+		return true
+	default:
+		m.visionConfirmed = input
+		m.notice = fmt.Sprintf("Vision support of model '%s' is unknown, press enter again to send the images anyway.", model)
+		return false
+	}
+}
+
+// loadDiff renders the unified diff of the current code change against the file on disk
+func (m *Model) loadDiff() {
+	change := m.changes[m.changeIndex]
+
+	current, _ := os.ReadFile(filepath.Join(m.deps.Cwd, change.RelativePath))
+
+	diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(string(current)),
+		B:        difflib.SplitLines(code_analyzer.ResolveChangedContent(change.Code)),
+		FromFile: "a/" + change.RelativePath,
+		ToFile:   "b/" + change.RelativePath,
+		Context:  3,
+	})
+	if err != nil {
+		diff = err.Error()
+	}
+
+	var lines []string
+	for _, line := range strings.Split(strings.TrimSuffix(diff, "\n"), "\n") {
+		switch {
+		case strings.HasPrefix(line, "+++"), strings.HasPrefix(line, "---"):
+			lines = append(lines, titleStyle.Render(line))
+		case strings.HasPrefix(line, "@@"):
+			lines = append(lines, hunkStyle.Render(line))
+		case strings.HasPrefix(line, "+"):
+			lines = append(lines, addedStyle.Render(line))
+		case strings.HasPrefix(line, "-"):
+			lines = append(lines, removedStyle.Render(line))
+		default:
+			lines = append(lines, line)
+		}
+	}
+
+	m.diff.SetContent(strings.Join(lines, "\n"))
+	m.diff.GotoTop()
+}
+
+// refreshChat renders the transcript with the streamed answer and follows the end of the chat
+func (m *Model) refreshChat() {
+	content := m.transcript.String()
+	if m.streaming {
+		content += m.reasoningView() + utils.HighlightMarkdown(m.answer.String(), m.deps.Config.Theme)
+	}
+
+	m.chat.SetContent(ansi.Wrap(content, m.chat.Width, ""))
+	m.chat.GotoBottom()
+}
+
+// reasoningView renders the reasoning of the answer dimmed, or a single line when 'collapse_reasoning' is set
+func (m *Model) reasoningView() string {
+	reasoning := strings.TrimSpace(m.reasoning.String())
+	if reasoning == "" {
+		return ""
+	}
+
+	if m.deps.Config.CollapseReasoning {
+		if m.streaming && m.answer.Len() == 0 {
+			return reasoningStyle.Render("💭 Thinking...") + "\n\n"
+		}
+		lines := strings.Count(reasoning, "\n") + 1
+		return reasoningStyle.Render(fmt.Sprintf("💭 Thought for %d lines, use ':reasoning' to expand it.", lines)) + "\n\n"
+	}
+
+	lines := strings.Split(reasoning, "\n")
+	for i, line := range lines {
+		lines[i] = reasoningStyle.Render(line)
+	}
+	return strings.Join(lines, "\n") + "\n\n"
+}
+
+// sidebarView lists the pinned files and the files in context
+func (m *Model) sidebarView() string {
+	var lines []string
+
+	if len(m.pinned) > 0 {
+		lines = append(lines, titleStyle.Render("Pinned"))
+		for _, mention := range m.pinned {
+			lines = append(lines, "@"+mention)
+		}
+		lines = append(lines, "")
+	}
+
+	var files []models.FileData
+	if m.deps.FullContext != nil {
+		files = m.deps.FullContext.FileData
+	}
+
+	lines = append(lines, titleStyle.Render(fmt.Sprintf("Context (%d files)", len(files))))
+	for _, file := range files {
+		lines = append(lines, file.RelativePath)
+	}
+
+	height := m.chat.Height
+	if m.reviewing() {
+		height += m.diff.Height + 1 + 2
+	}
+	if len(lines) > height {
+		lines = append(lines[:height-1], fmt.Sprintf("... %d more", len(lines)-height+1))
+	}
+
+	for i, line := range lines {
+		lines[i] = ansi.Truncate(line, sidebarWidth-2, "…")
+	}
+
+	return sidebarStyle.Height(height).Render(strings.Join(lines, "\n"))
+}
+
+// statusView shows the model, tokens, cost and the keys of the current mode
+func (m *Model) statusView() string {
+	provider := m.deps.Config.AIProviderConfig.Provider
+	model := m.deps.Config.AIProviderConfig.Model
+
+	usage := m.deps.TokenManagement.SessionUsage(provider, model)
+
+	hint := "enter send • ctrl+c quit"
+	switch {
+	case m.streaming:
+		hint = "generating... esc or ctrl+c cancel"
+	case m.reviewing():
+		hint = "y accept • n reject"
+	}
+	if m.notice != "" {
+		hint = m.notice
+	}
+
+	status := fmt.Sprintf(" %s | Token Used: %d | Cost: %.6f $ | %s", model, usage.InputTokens+usage.OutputTokens, usage.Cost, hint)
+	return statusStyle.Width(m.width).Render(ansi.Truncate(status, m.width, "…"))
+}
+
+func (m *Model) reviewing() bool {
+	return m.changeIndex < len(m.changes)
+}
+
+// cancel cancels the in-flight request, if any, and drains its response channel so the provider isn't left blocked
+// on sending the chunks nobody reads anymore
+func (m *Model) cancel() {
+	if m.cancelRequest != nil {
+		m.cancelRequest()
+		m.cancelRequest = nil
+	}
+
+	if m.responseChan != nil {
+		responseChan := m.responseChan
+		m.responseChan = nil
+		go func() {
+			for range responseChan {
+			}
+		}()
+	}
+}
+
+// waitForResponse reads the next chunk of the answer
+func waitForResponse(responseChan <-chan provider_models.StreamResponse) tea.Cmd {
+	return func() tea.Msg {
+		response, ok := <-responseChan
+		if !ok {
+			return responseMsg{responseChan: responseChan, closed: true}
+		}
+		return responseMsg{responseChan: responseChan, response: response}
+	}
+}
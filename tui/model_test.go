@@ -0,0 +1,265 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/exp/teatest"
+	"github.com/meysamhadeli/codai/chat_history"
+	"github.com/meysamhadeli/codai/code_analyzer"
+	"github.com/meysamhadeli/codai/code_analyzer/models"
+	"github.com/meysamhadeli/codai/config"
+	provider_models "github.com/meysamhadeli/codai/providers/models"
+	"github.com/meysamhadeli/codai/session_commands"
+	commands_models "github.com/meysamhadeli/codai/session_commands/models"
+	"github.com/meysamhadeli/codai/token_management"
+	"github.com/stretchr/testify/assert"
+)
+
+const answer = "Here is the change:\n\nFile: greeting.go\n```go\npackage main\n\n-func hello() {}\n+func hello() string { return \"hi\" }\n```\n"
+
+// fakeChatProvider streams a fixed reasoning and answer line by line, and keeps the images of the last request
+type fakeChatProvider struct {
+	answer    string
+	reasoning string
+	images    []provider_models.Image
+}
+
+func (provider *fakeChatProvider) ChatCompletionRequest(ctx context.Context, userInput string, prompt string, images []provider_models.Image) <-chan provider_models.StreamResponse {
+	provider.images = images
+
+	responseChan := make(chan provider_models.StreamResponse)
+	go func() {
+		defer close(responseChan)
+		if provider.reasoning != "" {
+			responseChan <- provider_models.StreamResponse{ReasoningContent: provider.reasoning}
+		}
+		for _, line := range strings.SplitAfter(provider.answer, "\n") {
+			select {
+			case <-ctx.Done():
+				return
+			case responseChan <- provider_models.StreamResponse{Content: line}:
+			}
+		}
+		responseChan <- provider_models.StreamResponse{Done: true}
+	}()
+	return responseChan
+}
+
+// blockingChatProvider streams chunks without watching the context, like a provider blocked on sending a chunk
+type blockingChatProvider struct {
+	finished chan struct{}
+}
+
+func (provider *blockingChatProvider) ChatCompletionRequest(ctx context.Context, userInput string, prompt string, images []provider_models.Image) <-chan provider_models.StreamResponse {
+	responseChan := make(chan provider_models.StreamResponse)
+	go func() {
+		defer close(provider.finished)
+		defer close(responseChan)
+		for i := 0; i < 100; i++ {
+			responseChan <- provider_models.StreamResponse{Content: fmt.Sprintf("chunk %d\n", i)}
+			time.Sleep(10 * time.Millisecond)
+		}
+		responseChan <- provider_models.StreamResponse{Done: true}
+	}()
+	return responseChan
+}
+
+// setup creates a project with a single file in a temporary directory and the TUI model of a session on it
+func setup(t *testing.T, testDir string) *Model {
+	assert.NoError(t, os.WriteFile(filepath.Join(testDir, "greeting.go"), []byte("package main\n\nfunc hello() {}\n"), 0644))
+
+	cfg := config.DefaultConfig
+
+	commands := session_commands.NewCommandRegistry(func() commands_models.SessionState {
+		return commands_models.SessionState{Cwd: testDir}
+	})
+	commands.Register(commands_models.Command{
+		Name:        "greet",
+		Description: "Greet the user",
+		Run: func(args []string) (bool, error) {
+			fmt.Println("Hello from a command")
+			return false, nil
+		},
+	})
+	commands.Register(commands_models.Command{
+		Name: "exit",
+		Run: func(args []string) (bool, error) {
+			return true, nil
+		},
+	})
+
+	return NewModel(context.Background(), Dependencies{
+		ChatProvider:    &fakeChatProvider{answer: answer},
+		Analyzer:        code_analyzer.NewCodeAnalyzer(testDir),
+		ChatHistory:     chat_history.NewChatHistory(),
+		TokenManagement: token_management.NewTokenManager(),
+		Config:          &cfg,
+		Cwd:             testDir,
+		FullContext: &models.FullContextData{
+			FileData: []models.FileData{{RelativePath: "greeting.go", Code: "package main\n\nfunc hello() {}\n", TreeSitterCode: "function: func hello()", Symbols: []string{"hello"}}},
+		},
+		Commands:         commands,
+		PendingImages:    func() []provider_models.Image { return nil },
+		SetLastReasoning: func(reasoning string) {},
+	})
+}
+
+func waitForView(t *testing.T, tm *teatest.TestModel, text string) {
+	teatest.WaitFor(t, tm.Output(), func(bts []byte) bool {
+		return strings.Contains(string(bts), text)
+	}, teatest.WithDuration(5*time.Second))
+}
+
+func TestInitialView(t *testing.T) {
+	tm := teatest.NewTestModel(t, setup(t, t.TempDir()), teatest.WithInitialTermSize(100, 20))
+
+	waitForView(t, tm, "Context (1 files)")
+	tm.Send(tea.KeyMsg{Type: tea.KeyCtrlC})
+
+	finalModel := tm.FinalModel(t, teatest.WithFinalTimeout(5*time.Second))
+	teatest.RequireEqualOutput(t, []byte(finalModel.View()))
+}
+
+func TestReviewChanges(t *testing.T) {
+	tm := teatest.NewTestModel(t, setup(t, t.TempDir()), teatest.WithInitialTermSize(100, 30))
+
+	tm.Type("say hi in @greeting.go")
+	tm.Send(tea.KeyMsg{Type: tea.KeyEnter})
+
+	waitForView(t, tm, "Review 1/1")
+	tm.Send(tea.KeyMsg{Type: tea.KeyCtrlC})
+
+	finalModel := tm.FinalModel(t, teatest.WithFinalTimeout(5*time.Second))
+	teatest.RequireEqualOutput(t, []byte(finalModel.View()))
+}
+
+func TestAcceptChanges(t *testing.T) {
+	// Changes are applied relative to the working directory
+	rootDir, err := os.Getwd()
+	assert.NoError(t, err)
+
+	testDir := t.TempDir()
+	assert.NoError(t, os.Chdir(testDir))
+	t.Cleanup(func() {
+		assert.NoError(t, os.Chdir(rootDir))
+	})
+
+	tm := teatest.NewTestModel(t, setup(t, testDir), teatest.WithInitialTermSize(100, 30))
+
+	tm.Type("say hi")
+	tm.Send(tea.KeyMsg{Type: tea.KeyEnter})
+
+	waitForView(t, tm, "Review 1/1")
+	tm.Send(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("y")})
+
+	waitForView(t, tm, "Changes accepted for greeting.go")
+	tm.Send(tea.KeyMsg{Type: tea.KeyCtrlC})
+	tm.WaitFinished(t, teatest.WithFinalTimeout(5*time.Second))
+
+	content, err := os.ReadFile("greeting.go")
+	assert.NoError(t, err)
+	assert.Contains(t, string(content), "func hello() string { return \"hi\" }")
+	assert.NotContains(t, string(content), "func hello() {}")
+}
+
+func TestRunCommand(t *testing.T) {
+	tm := teatest.NewTestModel(t, setup(t, t.TempDir()), teatest.WithInitialTermSize(100, 30))
+
+	tm.Type(":greet")
+	tm.Send(tea.KeyMsg{Type: tea.KeyEnter})
+	waitForView(t, tm, "Hello from a command")
+
+	tm.Type(":missing")
+	tm.Send(tea.KeyMsg{Type: tea.KeyEnter})
+	waitForView(t, tm, "Unknown command ':missing'")
+
+	tm.Type(":exit")
+	tm.Send(tea.KeyMsg{Type: tea.KeyEnter})
+	tm.WaitFinished(t, teatest.WithFinalTimeout(5*time.Second))
+}
+
+func TestReasoningAndImages(t *testing.T) {
+	testDir := t.TempDir()
+	imagePath := filepath.Join(testDir, "shot.png")
+	assert.NoError(t, os.WriteFile(imagePath, []byte("\x89PNG\r\n\x1a\n"), 0644))
+
+	model := setup(t, testDir)
+	provider := &fakeChatProvider{answer: "It is a login page.\n", reasoning: "The image shows a form."}
+	model.deps.ChatProvider = provider
+
+	var lastReasoning string
+	model.deps.SetLastReasoning = func(reasoning string) {
+		lastReasoning = reasoning
+	}
+
+	tm := teatest.NewTestModel(t, model, teatest.WithInitialTermSize(100, 30))
+
+	tm.Type("what is in @" + imagePath)
+	tm.Send(tea.KeyMsg{Type: tea.KeyEnter})
+	waitForView(t, tm, "It is a login page.")
+	tm.Send(tea.KeyMsg{Type: tea.KeyCtrlC})
+
+	finalModel := tm.FinalModel(t, teatest.WithFinalTimeout(5*time.Second)).(*Model)
+	assert.Contains(t, finalModel.transcript.String(), "The image shows a form.")
+	assert.Empty(t, finalModel.notice)
+	assert.Equal(t, "The image shows a form.", lastReasoning)
+	if assert.Len(t, provider.images, 1) {
+		assert.Equal(t, "image/png", provider.images[0].MediaType)
+	}
+}
+
+func TestCancelDrainsResponses(t *testing.T) {
+	model := setup(t, t.TempDir())
+	provider := &blockingChatProvider{finished: make(chan struct{})}
+	model.deps.ChatProvider = provider
+
+	tm := teatest.NewTestModel(t, model, teatest.WithInitialTermSize(100, 30))
+
+	tm.Type("stream")
+	tm.Send(tea.KeyMsg{Type: tea.KeyEnter})
+	waitForView(t, tm, "chunk 1")
+	tm.Send(tea.KeyMsg{Type: tea.KeyEsc})
+	waitForView(t, tm, "Request canceled, keep the partial answer? (y/n)")
+
+	// The remaining chunks are read in the background, so the provider ends its stream
+	select {
+	case <-provider.finished:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the provider is blocked on sending the chunks of the canceled request")
+	}
+
+	tm.Send(tea.KeyMsg{Type: tea.KeyCtrlC})
+	tm.WaitFinished(t, teatest.WithFinalTimeout(5*time.Second))
+}
+
+func TestCancelKeepsPartialAnswer(t *testing.T) {
+	model := setup(t, t.TempDir())
+	model.deps.ChatProvider = &blockingChatProvider{finished: make(chan struct{})}
+
+	tm := teatest.NewTestModel(t, model, teatest.WithInitialTermSize(100, 30))
+
+	tm.Type("stream")
+	tm.Send(tea.KeyMsg{Type: tea.KeyEnter})
+	waitForView(t, tm, "chunk 1")
+	tm.Send(tea.KeyMsg{Type: tea.KeyEsc})
+	waitForView(t, tm, "keep the partial answer? (y/n)")
+
+	// The partial answer is in the history of the next requests once kept
+	tm.Type("y")
+	waitForView(t, tm, "Partial answer kept in the history.")
+
+	tm.Send(tea.KeyMsg{Type: tea.KeyCtrlC})
+	tm.WaitFinished(t, teatest.WithFinalTimeout(5*time.Second))
+
+	history := model.deps.ChatHistory.GetHistory()
+	assert.Len(t, history, 1)
+	assert.Contains(t, history[0], "stream")
+	assert.Contains(t, history[0], "chunk 1")
+}
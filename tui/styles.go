@@ -0,0 +1,16 @@
+package tui
+
+import "github.com/charmbracelet/lipgloss"
+
+var (
+	paneStyle      = lipgloss.NewStyle().Border(lipgloss.RoundedBorder()).BorderForeground(lipgloss.Color("#2b7fec"))
+	inputStyle     = lipgloss.NewStyle().Border(lipgloss.RoundedBorder()).BorderForeground(lipgloss.Color("#00BFFF"))
+	sidebarStyle   = lipgloss.NewStyle().Width(sidebarWidth - 2)
+	statusStyle    = lipgloss.NewStyle().Background(lipgloss.Color("#E5E7E9")).Foreground(lipgloss.Color("#2b7fec")).Bold(true)
+	titleStyle     = lipgloss.NewStyle().Foreground(lipgloss.Color("205")).Bold(true)
+	userStyle      = lipgloss.NewStyle().Foreground(lipgloss.Color("#00BFFF")).Bold(true)
+	reasoningStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("245")).Faint(true)
+	hunkStyle      = lipgloss.NewStyle().Foreground(lipgloss.Color("#7F00FF"))
+	addedStyle     = lipgloss.NewStyle().Foreground(lipgloss.Color("76"))
+	removedStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("197"))
+)
@@ -0,0 +1,39 @@
+package utils
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+)
+
+// CaptureStdout runs a function with the standard output redirected and returns what it printed, e.g., to show the
+// output of the commands of the session in the chat pane of the TUI.
+func CaptureStdout(run func()) (string, error) {
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		return "", fmt.Errorf("failed to capture the output: %w", err)
+	}
+	defer reader.Close()
+
+	// Read while the function runs, a large output would block on the full pipe otherwise
+	output := make(chan string)
+	go func() {
+		var buffer bytes.Buffer
+		_, _ = io.Copy(&buffer, reader)
+		output <- buffer.String()
+	}()
+
+	stdout := os.Stdout
+	os.Stdout = writer
+	defer func() {
+		os.Stdout = stdout
+	}()
+
+	run()
+
+	os.Stdout = stdout
+	_ = writer.Close()
+
+	return <-output, nil
+}
@@ -1,6 +1,7 @@
 package utils
 
 import (
+	"path/filepath"
 	"regexp"
 	"strings"
 )
@@ -35,22 +36,70 @@ func DetectLanguageFromCodeBlock(content string) string {
 	return "markdown"
 }
 
+// supportedLanguages maps the extensions of files to the languages with a Tree-sitter grammar
+var supportedLanguages = map[string]string{
+	".cs":         "csharp",
+	".go":         "go",
+	".ts":         "typescript",
+	".mts":        "typescript",
+	".cts":        "typescript",
+	".tsx":        "tsx",
+	".js":         "javascript",
+	".jsx":        "javascript",
+	".mjs":        "javascript",
+	".cjs":        "javascript",
+	".py":         "python",
+	".java":       "java",
+	".rs":         "rust",
+	".c":          "c",
+	".h":          "c",
+	".cpp":        "cpp",
+	".cc":         "cpp",
+	".cxx":        "cpp",
+	".hpp":        "cpp",
+	".hh":         "cpp",
+	".hxx":        "cpp",
+	".rb":         "ruby",
+	".php":        "php",
+	".kt":         "kotlin",
+	".kts":        "kotlin",
+	".swift":      "swift",
+	".scala":      "scala",
+	".sc":         "scala",
+	".sh":         "bash",
+	".bash":       "bash",
+	".lua":        "lua",
+	".ex":         "elixir",
+	".exs":        "elixir",
+	".css":        "css",
+	".cue":        "cue",
+	".elm":        "elm",
+	".groovy":     "groovy",
+	".gradle":     "groovy",
+	".tf":         "hcl",
+	".hcl":        "hcl",
+	".html":       "html",
+	".htm":        "html",
+	".ml":         "ocaml",
+	".proto":      "protobuf",
+	".dockerfile": "dockerfile",
+	".sql":        "sql",
+	".svelte":     "svelte",
+	".toml":       "toml",
+	".yaml":       "yaml",
+	".yml":        "yaml",
+}
+
+// supportedFileNames maps the names of files without a language extension to their language
+var supportedFileNames = map[string]string{
+	"dockerfile":    "dockerfile",
+	"containerfile": "dockerfile",
+}
+
+// GetSupportedLanguage returns the language of a file by its extension or name, or an empty string if it has no grammar
 func GetSupportedLanguage(path string) string {
-	// Use a switch statement to check for file extensions
-	switch {
-	case strings.HasSuffix(path, ".cs"):
-		return "csharp"
-	case strings.HasSuffix(path, ".go"):
-		return "go"
-	case strings.HasSuffix(path, ".ts"):
-		return "typescript"
-	case strings.HasSuffix(path, ".js"):
-		return "javascript"
-	case strings.HasSuffix(path, ".py"):
-		return "python"
-	case strings.HasSuffix(path, ".java"):
-		return "java"
-	default:
-		return "" // Return empty string if no match
+	if language, exists := supportedFileNames[strings.ToLower(filepath.Base(path))]; exists {
+		return language
 	}
+	return supportedLanguages[strings.ToLower(filepath.Ext(path))]
 }
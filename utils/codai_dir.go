@@ -0,0 +1,30 @@
+package utils
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// GetCodaiConfigDir returns the user level directory of codai, '~/.config/codai', and creates it if it doesn't exist.
+func GetCodaiConfigDir() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("error getting home directory: %w", err)
+	}
+
+	configDir := filepath.Join(homeDir, ".config", "codai")
+	if err := os.MkdirAll(configDir, os.ModePerm); err != nil {
+		return "", fmt.Errorf("failed to create directory %s: %w", configDir, err)
+	}
+
+	return configDir, nil
+}
+
+// GetProjectKey returns a stable key for a project directory, used to keep per-project files in the user level directory.
+func GetProjectKey(cwd string) string {
+	hash := sha256.Sum256([]byte(cwd))
+	return fmt.Sprintf("%s-%s", filepath.Base(cwd), hex.EncodeToString(hash[:])[:8])
+}
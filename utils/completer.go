@@ -0,0 +1,68 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Completer completes ':' and '/' commands, '@' mentions and the file path of ':image' on tab.
+type Completer struct {
+	Commands []string // Commands of the session, e.g., ":help" or "/test"
+	Mentions []string // Files, directories and symbols of the project context
+}
+
+// Do returns the candidates that complete the word under the cursor, as expected by the line editor.
+func (completer *Completer) Do(line []rune, pos int) ([][]rune, int) {
+	text := string(line[:pos])
+	word := text[strings.LastIndexAny(text, " \t")+1:]
+
+	var candidates []string
+	switch {
+	case (strings.HasPrefix(word, ":") || strings.HasPrefix(word, "/")) && word == text:
+		candidates = completer.Commands
+	case strings.HasPrefix(word, "@"):
+		for _, mention := range completer.Mentions {
+			candidates = append(candidates, "@"+mention)
+		}
+	case strings.HasPrefix(text, ":image "):
+		candidates = completeFilePath(word)
+	default:
+		return nil, 0
+	}
+
+	var newLine [][]rune
+	for _, candidate := range candidates {
+		if strings.HasPrefix(candidate, word) {
+			newLine = append(newLine, []rune(candidate[len(word):]))
+		}
+	}
+
+	return newLine, len([]rune(word))
+}
+
+// completeFilePath lists the entries of the directory of a partial path, directories end with '/'
+func completeFilePath(partialPath string) []string {
+	dir, _ := filepath.Split(partialPath)
+
+	readDir := dir
+	if readDir == "" {
+		readDir = "."
+	}
+
+	entries, err := os.ReadDir(readDir)
+	if err != nil {
+		return nil
+	}
+
+	var paths []string
+	for _, entry := range entries {
+		path := dir + entry.Name()
+		if entry.IsDir() {
+			path += "/"
+		}
+		paths = append(paths, path)
+	}
+
+	return paths
+}
@@ -0,0 +1,69 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompleterDo(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, os.MkdirAll(filepath.Join(dir, "screens"), os.ModePerm))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "shot.png"), []byte("png"), 0644))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "screens", "login.png"), []byte("png"), 0644))
+
+	wd, err := os.Getwd()
+	assert.NoError(t, err)
+	assert.NoError(t, os.Chdir(dir))
+	t.Cleanup(func() { _ = os.Chdir(wd) })
+
+	completer := &Completer{
+		Commands: []string{":help", ":clear", ":clear-history", "/test"},
+		Mentions: []string{"cmd/code.go", "cmd/root.go", "GetProjectFiles"},
+	}
+
+	tests := []struct {
+		name       string
+		line       string
+		candidates []string
+		length     int
+	}{
+		{name: "command", line: ":cl", candidates: []string{"ear", "ear-history"}, length: 3},
+		{name: "slash command", line: "/t", candidates: []string{"est"}, length: 2},
+		{name: "all commands", line: ":", candidates: []string{"help", "clear", "clear-history"}, length: 1},
+		{name: "command after text", line: "explain :cl", candidates: nil, length: 0},
+		{name: "mention", line: "explain @cmd/", candidates: []string{"code.go", "root.go"}, length: 5},
+		{name: "symbol mention", line: "where is @Get", candidates: []string{"ProjectFiles"}, length: 4},
+		{name: "unknown mention", line: "explain @missing", candidates: nil, length: 8},
+		{name: "image path", line: ":image sh", candidates: []string{"ot.png"}, length: 2},
+		{name: "image directory", line: ":image scr", candidates: []string{"eens/"}, length: 3},
+		{name: "image in directory", line: ":image screens/", candidates: []string{"login.png"}, length: 8},
+		{name: "plain text", line: "explain the", candidates: nil, length: 0},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			line := []rune(test.line)
+			newLine, length := completer.Do(line, len(line))
+
+			var candidates []string
+			for _, candidate := range newLine {
+				candidates = append(candidates, string(candidate))
+			}
+			assert.Equal(t, test.candidates, candidates)
+			assert.Equal(t, test.length, length)
+		})
+	}
+}
+
+func TestCompleterDoBeforeCursor(t *testing.T) {
+	completer := &Completer{Mentions: []string{"main.go"}}
+
+	// Only the word before the cursor is completed
+	line := []rune("explain @ma and more")
+	newLine, length := completer.Do(line, len("explain @ma"))
+	assert.Equal(t, [][]rune{[]rune("in.go")}, newLine)
+	assert.Equal(t, 3, length)
+}
@@ -1,21 +1,20 @@
 package utils
 
 import (
-	"bufio"
 	"fmt"
 	"github.com/meysamhadeli/codai/constants/lipgloss"
 	"strings"
 )
 
 // ConfirmPrompt prompts the user to accept or reject the changes in a file path
-func ConfirmPrompt(path string, reader *bufio.Reader) (bool, error) {
+func ConfirmPrompt(path string, editor *LineEditor) (bool, error) {
 
 	// Styled prompt message
 	fmt.Print("\r")
-	fmt.Printf(lipgloss.BlueSky.Render(fmt.Sprintf("Do you want to accept the change for file %v%s", lipgloss.LightBlueB.Render(path), lipgloss.BlueSky.Render(" ? (y/n): "))))
+	prompt := lipgloss.BlueSky.Render(fmt.Sprintf("Do you want to accept the change for file %v%s", lipgloss.LightBlueB.Render(path), lipgloss.BlueSky.Render(" ? (y/n): ")))
 
-	// Read user input
-	input, _ := reader.ReadString('\n')
+	// Read user input, Ctrl+C rejects the change
+	input, _ := editor.ReadLine(prompt)
 	input = strings.TrimSpace(input)
 
 	if input == "y" || input == "Y" {
@@ -26,15 +25,18 @@ func ConfirmPrompt(path string, reader *bufio.Reader) (bool, error) {
 }
 
 // ConfirmAdditinalContext prompts the user to accept or reject additional context
-func ConfirmAdditinalContext(reader *bufio.Reader) (bool, error) {
+func ConfirmAdditinalContext(editor *LineEditor) (bool, error) {
 
 	// Styled prompt message
 	fmt.Print("\r")
-	fmt.Printf(lipgloss.Gray.Render(fmt.Sprintf("Do you want to add above files to context %s", lipgloss.Gray.Render("? (y/n): "))))
+	prompt := lipgloss.Gray.Render(fmt.Sprintf("Do you want to add above files to context %s", lipgloss.Gray.Render("? (y/n): ")))
 
 	for {
 		// Read user input
-		input, _ := reader.ReadString('\n')
+		input, err := editor.ReadLine(prompt)
+		if err != nil {
+			return false, nil
+		}
 		input = strings.TrimSpace(input)
 
 		if input == "" {
@@ -48,3 +50,57 @@ func ConfirmAdditinalContext(reader *bufio.Reader) (bool, error) {
 		}
 	}
 }
+
+// ConfirmKeepPartialAnswer prompts the user to keep or discard the partial answer of a canceled request
+func ConfirmKeepPartialAnswer(editor *LineEditor) (bool, error) {
+
+	// Styled prompt message
+	fmt.Print("\r")
+	prompt := lipgloss.Gray.Render(fmt.Sprintf("Do you want to keep the partial answer in the history of chat %s", lipgloss.Gray.Render("? (y/n): ")))
+
+	// Read user input, Ctrl+C discards the partial answer
+	input, _ := editor.ReadLine(prompt)
+	input = strings.TrimSpace(input)
+
+	if input == "y" || input == "Y" {
+		return true, nil
+	}
+
+	return false, nil
+}
+
+// ConfirmBudget prompts the user to send or skip a request above a limit of budget
+func ConfirmBudget(editor *LineEditor) (bool, error) {
+
+	// Styled prompt message
+	fmt.Print("\r")
+	prompt := lipgloss.Yellow.Render(fmt.Sprintf("Do you want to send the request anyway %s", lipgloss.Yellow.Render("? (y/n): ")))
+
+	// Read user input, Ctrl+C skips the request
+	input, _ := editor.ReadLine(prompt)
+	input = strings.TrimSpace(input)
+
+	if input == "y" || input == "Y" {
+		return true, nil
+	}
+
+	return false, nil
+}
+
+// ConfirmUnknownVision prompts the user to send or skip images for a model with unknown vision support
+func ConfirmUnknownVision(editor *LineEditor, model string) (bool, error) {
+
+	// Styled prompt message
+	fmt.Print("\r")
+	prompt := lipgloss.Yellow.Render(fmt.Sprintf("Vision support of model '%s' is unknown, do you want to send the images anyway %s", model, lipgloss.Yellow.Render("? (y/n): ")))
+
+	// Read user input, Ctrl+C skips the request
+	input, _ := editor.ReadLine(prompt)
+	input = strings.TrimSpace(input)
+
+	if input == "y" || input == "Y" {
+		return true, nil
+	}
+
+	return false, nil
+}
@@ -0,0 +1,89 @@
+package utils
+
+import (
+	"bytes"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+// sniffLength is the length of the start of a file sniffed for its encoding, like git for the binary files
+const sniffLength = 8000
+
+// minifiedLineLength is the average length of the lines from which a file is minified, e.g., a bundled JavaScript file
+const minifiedLineLength = 300
+
+// lockfileNames are the files of the resolved versions of dependencies, generated by the package managers
+var lockfileNames = []string{
+	"package-lock.json",
+	"npm-shrinkwrap.json",
+	"yarn.lock",
+	"pnpm-lock.yaml",
+	"bun.lockb",
+	"composer.lock",
+	"Gemfile.lock",
+	"Cargo.lock",
+	"poetry.lock",
+	"Pipfile.lock",
+	"uv.lock",
+	"pdm.lock",
+	"packages.lock.json",
+	"paket.lock",
+	"mix.lock",
+	"Podfile.lock",
+	"Package.resolved",
+	"pubspec.lock",
+	"flake.lock",
+	"gradle.lockfile",
+}
+
+// generatedHeader matches the header of a generated file, e.g., '// Code generated by protoc-gen-go. DO NOT EDIT.' in Go,
+// '@generated' in a comment of JavaScript, Rust or Python, or '<auto-generated>' in C#. The '@generated' must follow a
+// comment marker, so the code looking for generated files, e.g., with a "@generated" string, is kept.
+var generatedHeader = regexp.MustCompile(`(?m)^\s*((//|#|/?\*|--|<!--)?\s*(Code generated .* DO NOT EDIT\.?|<auto-generated[ />])|(//!?|#|/\*|\*\s|--|<!--).*@generated\b)`)
+
+// generatedHeaderLines is the number of lines at the start of a file searched for a generated header
+const generatedHeaderLines = 20
+
+// IsBinaryContent reports whether the content of a file is binary, with a NUL byte in its first 8000 bytes like git
+func IsBinaryContent(content []byte) bool {
+	return bytes.IndexByte(content[:min(len(content), sniffLength)], 0) >= 0
+}
+
+// IsTextEncoded reports whether the start of the content of a file is valid UTF-8, e.g., not a Latin-1 or UTF-16 file.
+func IsTextEncoded(content []byte) bool {
+	start := content[:min(len(content), sniffLength)]
+	// A rune cut at the end of the sniffed bytes is not an invalid encoding
+	for i := 0; i < utf8.UTFMax-1 && len(start) < len(content) && !utf8.Valid(start); i++ {
+		start = start[:len(start)-1]
+	}
+	return utf8.Valid(start)
+}
+
+// IsGeneratedContent reports whether the content of a file has a header of generated code in its first lines, e.g.,
+// the protobuf, mock or ORM files.
+func IsGeneratedContent(content []byte) bool {
+	lines := bytes.SplitN(content[:min(len(content), sniffLength)], []byte("\n"), generatedHeaderLines+1)
+	header := bytes.Join(lines[:min(len(lines), generatedHeaderLines)], []byte("\n"))
+	return generatedHeader.Match(header)
+}
+
+// IsMinifiedContent reports whether the content of a file is minified, with '.min.' in its name or very long lines on
+// average, e.g., a bundled JavaScript or CSS file.
+func IsMinifiedContent(path string, content []byte) bool {
+	if strings.Contains(filepath.Base(path), ".min.") {
+		return true
+	}
+	if len(content) < 1024 {
+		return false
+	}
+	lines := bytes.Count(bytes.TrimRight(content, "\n"), []byte("\n")) + 1
+	return len(content)/lines > minifiedLineLength
+}
+
+// IsLockfile reports whether the file is a lockfile of a package manager, e.g., 'package-lock.json' or 'Cargo.lock'.
+func IsLockfile(path string) bool {
+	return slices.Contains(lockfileNames, filepath.Base(path))
+}
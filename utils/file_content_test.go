@@ -0,0 +1,31 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsGeneratedContent(t *testing.T) {
+	tests := []struct {
+		name      string
+		content   string
+		generated bool
+	}{
+		{name: "go header", content: "// Code generated by protoc-gen-go. DO NOT EDIT.\npackage api", generated: true},
+		{name: "javascript doc comment", content: "/**\n * @generated by the schema compiler\n */\nexport type A = {}", generated: true},
+		{name: "python comment", content: "# @generated by pants\nimport os", generated: true},
+		{name: "rust inner doc comment", content: "//! @generated\nfn main() {}", generated: true},
+		{name: "csharp header", content: "// <auto-generated />\nnamespace Api;", generated: true},
+		{name: "string literal", content: "package scan\n\nconst marker = \"@generated\"\n\nfunc isGenerated(header string) bool {\n\treturn strings.Contains(header, marker)\n}", generated: false},
+		{name: "string literal at the start of a line", content: "markers = [\n    \"@generated\",\n]", generated: false},
+		{name: "header after the first lines", content: "package main\n" + "\n\n\n\n\n\n\n\n\n\n\n\n\n\n\n\n\n\n\n\n" + "// @generated", generated: false},
+		{name: "plain code", content: "package main\n\nfunc main() {}", generated: false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.generated, IsGeneratedContent([]byte(test.content)))
+		})
+	}
+}
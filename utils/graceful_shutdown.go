@@ -5,9 +5,64 @@ import (
 	"fmt"
 	"github.com/meysamhadeli/codai/constants/lipgloss"
 	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
 )
 
-func GracefulShutdown(ctx context.Context, stop context.CancelFunc, cleanup func()) {
+// doublePressInterval is the time between two Ctrl+C to exit while a request is in flight
+const doublePressInterval = time.Second
+
+// RequestCanceler keeps the cancel function of the in-flight request, so Ctrl+C can cancel it without ending the session
+type RequestCanceler struct {
+	mu            sync.Mutex
+	cancel        context.CancelFunc
+	lastInterrupt time.Time
+}
+
+// Start returns the context of a new request, canceled by the next Ctrl+C
+func (rc *RequestCanceler) Start(ctx context.Context) context.Context {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	requestCtx, cancel := context.WithCancel(ctx)
+	rc.cancel = cancel
+
+	return requestCtx
+}
+
+// Done releases the context of the finished request
+func (rc *RequestCanceler) Done() {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	if rc.cancel != nil {
+		rc.cancel()
+		rc.cancel = nil
+	}
+}
+
+// Interrupt cancels the in-flight request and reports if it did, a second Ctrl+C in a row or a Ctrl+C without a request should exit
+func (rc *RequestCanceler) Interrupt() bool {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	now := time.Now()
+	doublePress := now.Sub(rc.lastInterrupt) < doublePressInterval
+	rc.lastInterrupt = now
+
+	if rc.cancel == nil || doublePress {
+		return false
+	}
+
+	rc.cancel()
+	rc.cancel = nil
+
+	return true
+}
+
+func GracefulShutdown(ctx context.Context, stop context.CancelFunc, requestCanceler *RequestCanceler, cleanup func()) {
 	// Defer the recovery function to handle any panics during cleanup
 	defer func() {
 		if r := recover(); r != nil {
@@ -16,8 +71,24 @@ func GracefulShutdown(ctx context.Context, stop context.CancelFunc, cleanup func
 		}
 	}()
 
-	// Wait for the context to be canceled by an external signal (e.g., SIGINT or SIGTERM)
-	<-ctx.Done()
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(signals)
+
+	// Wait for the context to be canceled or for an external signal (e.g., SIGINT or SIGTERM),
+	// the first Ctrl+C during a request only cancels the request
+waitLoop:
+	for {
+		select {
+		case <-ctx.Done():
+			break waitLoop
+		case sig := <-signals:
+			if sig == syscall.SIGINT && requestCanceler.Interrupt() {
+				continue
+			}
+			break waitLoop
+		}
+	}
 
 	stop() // Cancel the context to stop further processing
 
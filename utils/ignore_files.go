@@ -4,102 +4,232 @@ import (
 	"fmt"
 	"io/ioutil"
 	"os"
+	"path"
 	"path/filepath"
 	"strings"
 )
 
-// GetGitignorePatterns reads and returns the patterns from the .gitignore file.
-// If the file does not exist, it returns an empty pattern list.
-func GetGitignorePatterns(cwd string) ([]string, error) {
-	gitignorePath := filepath.Join(cwd, ".codai-gitignore")
+// ignoreFileNames are the ignore files read in each directory of the project, the rules of the later file win
+var ignoreFileNames = []string{".gitignore", ".codai-gitignore"}
 
-	// Check if the .gitignore file exists
-	if _, err := os.Stat(gitignorePath); os.IsNotExist(err) {
-		// .gitignore doesn't exist, return an empty slice
-		return []string{}, nil
-	} else if err != nil {
-		// Some other error occurred while checking the file
-		return nil, fmt.Errorf("error checking .codai-gitignore: %w", err)
+// defaultIgnorePatterns are the files and directories never sent to the AI, e.g., the dependencies and build outputs
+var defaultIgnorePatterns = []string{
+	"codai-config.yml",
+	".git",
+	".gitignore",
+	".codai-gitignore",
+	".codai/",
+	".gitattributes",
+	".gitmodules",
+	".svn",
+	"*.sum",
+	"*.tmp",
+	"*.tmpl",
+	".idea/",
+	".vscode/",
+	"bin/",
+	"obj/",
+	"dist/",
+	"out/",
+	"node_modules/",
+	"*.exe",
+	"*.dll",
+	"*.log",
+	"*.bak",
+	"*.bkp",
+	"*.mp3",
+	"*.wav",
+	"*.aac",
+	"*.flac",
+	"*.ogg",
+	"*.jpg",
+	"*.jpeg",
+	"*.png",
+	"*.gif",
+	"*.mkv",
+	"*.mp4",
+	"*.avi",
+	"*.mov",
+	"*.wmv",
+	"*.drawio",
+	"*.excalidraw",
+}
+
+var defaultIgnoreMatcher = NewPathMatcher(defaultIgnorePatterns...)
+
+// ignoreRule is a pattern of a gitignore file, relative to the directory of the file
+type ignoreRule struct {
+	base     string   // The directory of the ignore file relative to the root, empty for the root
+	segments []string // The segments of the pattern split on '/'
+	negate   bool     // The pattern starts with '!' and re-includes the paths
+	dirOnly  bool     // The pattern ends with '/' and matches only directories
+	anchored bool     // The pattern has a '/' before its end and matches relative to its base, else it matches the names at any depth
+}
+
+// PathMatcher matches the paths relative to the root of the project with gitignore patterns, e.g., '*.log', '/build',
+// 'docs/**/*.md' or '!keep.log'. The last matching pattern decides like in git, so the patterns of a nested ignore
+// file, added after the ones of its parents, win over them.
+type PathMatcher struct {
+	rules []ignoreRule
+}
+
+// NewPathMatcher returns a matcher of the gitignore patterns relative to the root of the project.
+func NewPathMatcher(patterns ...string) *PathMatcher {
+	matcher := &PathMatcher{}
+	matcher.AddPatterns("", patterns)
+	return matcher
+}
+
+// AddPatterns adds the gitignore patterns of a directory relative to the root of the project, empty for the root.
+func (matcher *PathMatcher) AddPatterns(base string, patterns []string) {
+	base = strings.Trim(filepath.ToSlash(base), "/")
+	if base == "." {
+		base = ""
 	}
 
-	// Read and parse the .gitignore file if it exists
-	ignorePatterns, err := readGitignore(gitignorePath)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read .codai-gitignore: %w", err)
+	for _, pattern := range patterns {
+		if rule, ok := parseIgnoreRule(base, pattern); ok {
+			matcher.rules = append(matcher.rules, rule)
+		}
 	}
+}
 
-	// Validate patterns to ignore those that start with .git and .idea
-	var validPatterns []string
-	for _, pattern := range ignorePatterns {
-		if !IsDefaultIgnored(pattern) {
-			validPatterns = append(validPatterns, pattern)
+// AddIgnoreFiles adds the patterns of the '.gitignore' and '.codai-gitignore' files of a directory of the project, if they exist.
+func (matcher *PathMatcher) AddIgnoreFiles(rootDir string, relativeDir string) error {
+	for _, fileName := range ignoreFileNames {
+		ignorePath := filepath.Join(rootDir, filepath.FromSlash(relativeDir), fileName)
+
+		patterns, err := readGitignore(ignorePath)
+		if os.IsNotExist(err) {
+			continue
+		} else if err != nil {
+			return fmt.Errorf("failed to read %s: %w", ignorePath, err)
 		}
+
+		matcher.AddPatterns(relativeDir, patterns)
 	}
+	return nil
+}
 
-	return validPatterns, nil
+// Matches reports whether a path relative to the root of the project matches the patterns. A path inside a matched
+// directory matches too, like in git a file can't be re-included when its directory is ignored.
+func (matcher *PathMatcher) Matches(relativePath string, isDir bool) bool {
+	segments := strings.Split(filepath.ToSlash(relativePath), "/")
+	for i := 1; i < len(segments); i++ {
+		if matcher.match(strings.Join(segments[:i], "/"), true) {
+			return true
+		}
+	}
+	return matcher.match(strings.Join(segments, "/"), isDir)
 }
 
-func IsDefaultIgnored(path string) bool {
-	// Define ignore patterns
-	ignorePatterns := []string{
-		"codai-config.yml",
-		".git",
-		".svn",
-		".sum",
-		".tmp",
-		".tmpl",
-		".idea",
-		".vscode",
-		"bin",
-		"obj",
-		"dist",
-		"out",
-		"node_modules",
-		"*.exe",
-		"*.dll",
-		"*.log",
-		"*.bak",
-		"*.bkp",
-		".mp3",
-		".wav",
-		".aac",
-		".flac",
-		".ogg",
-		".jpg",
-		".jpeg",
-		".png",
-		".gif",
-		".mkv",
-		".mp4",
-		".avi",
-		".mov",
-		".wmv",
-		".drawio",
-		".excalidraw",
-	}
-
-	// Split the path into parts based on the file separator
-	parts := strings.Split(path, string(filepath.Separator))
-
-	// Check each part for any ignore patterns
-	for _, part := range parts {
-		part = strings.ToLower(part)
-		for _, pattern := range ignorePatterns {
-			if strings.HasPrefix(pattern, "*") {
-				// If the pattern starts with '*', check for suffix
-				suffix := strings.TrimPrefix(pattern, "*")
-				if strings.HasSuffix(part, suffix) {
-					return true
-				}
-			} else {
-				// Check for both prefix and suffix matches
-				if strings.HasPrefix(part, pattern) || strings.HasSuffix(part, pattern) {
+// match returns the decision of the last pattern matching the path, ignoring its parent directories.
+func (matcher *PathMatcher) match(relativePath string, isDir bool) bool {
+	for i := len(matcher.rules) - 1; i >= 0; i-- {
+		if matcher.rules[i].matches(relativePath, isDir) {
+			return !matcher.rules[i].negate
+		}
+	}
+	return false
+}
+
+// IsDefaultIgnored reports whether a path relative to the root of the project is ignored by default, e.g., '.git',
+// 'node_modules/' or '*.exe', regardless of the case of its name.
+func IsDefaultIgnored(relativePath string, isDir bool) bool {
+	return defaultIgnoreMatcher.Matches(strings.ToLower(relativePath), isDir)
+}
+
+// MatchGlob reports whether a path relative to the root of the project matches a glob relative to the root, where
+// '**' matches any number of directories, e.g., 'packages/**' or 'apps/*/web'.
+func MatchGlob(pattern string, relativePath string) bool {
+	return matchSegments(strings.Split(path.Clean(filepath.ToSlash(pattern)), "/"), strings.Split(filepath.ToSlash(relativePath), "/"))
+}
+
+// parseIgnoreRule parses a line of a gitignore file, it returns false for the blank lines and comments.
+func parseIgnoreRule(base string, pattern string) (ignoreRule, bool) {
+	// The trailing spaces are ignored unless they are escaped with a backslash
+	trimmed := strings.TrimRight(pattern, " \t\r")
+	if strings.HasSuffix(trimmed, "\\") && len(trimmed) < len(pattern) {
+		trimmed += " "
+	}
+	pattern = trimmed
+
+	if pattern == "" || strings.HasPrefix(pattern, "#") {
+		return ignoreRule{}, false
+	}
+
+	rule := ignoreRule{base: base}
+	if strings.HasPrefix(pattern, "!") {
+		rule.negate = true
+		pattern = pattern[1:]
+	} else if strings.HasPrefix(pattern, "\\!") || strings.HasPrefix(pattern, "\\#") {
+		pattern = pattern[1:]
+	}
+
+	if strings.HasSuffix(pattern, "/") {
+		rule.dirOnly = true
+		pattern = strings.TrimRight(pattern, "/")
+	}
+	rule.anchored = strings.Contains(pattern, "/")
+	pattern = strings.TrimPrefix(pattern, "/")
+
+	if pattern == "" {
+		return ignoreRule{}, false
+	}
+
+	rule.segments = strings.Split(pattern, "/")
+	return rule, true
+}
+
+// matches reports whether the rule matches the path relative to the root of the project.
+func (rule ignoreRule) matches(relativePath string, isDir bool) bool {
+	if rule.dirOnly && !isDir {
+		return false
+	}
+
+	if rule.base != "" {
+		if !strings.HasPrefix(relativePath, rule.base+"/") {
+			return false
+		}
+		relativePath = relativePath[len(rule.base)+1:]
+	}
+
+	segments := strings.Split(relativePath, "/")
+	if !rule.anchored {
+		return matchSegment(rule.segments[0], segments[len(segments)-1])
+	}
+	return matchSegments(rule.segments, segments)
+}
+
+// matchSegments matches the segments of a path with the segments of a pattern, where '**' matches any number of
+// directories, and a trailing '**' everything inside a directory but not the directory itself.
+func matchSegments(patterns []string, segments []string) bool {
+	for len(patterns) > 0 {
+		if patterns[0] == "**" {
+			patterns = patterns[1:]
+			if len(patterns) == 0 {
+				return len(segments) > 0
+			}
+			for i := 0; i <= len(segments); i++ {
+				if matchSegments(patterns, segments[i:]) {
 					return true
 				}
 			}
+			return false
+		}
+
+		if len(segments) == 0 || !matchSegment(patterns[0], segments[0]) {
+			return false
 		}
+		patterns, segments = patterns[1:], segments[1:]
 	}
-	return false
+	return len(segments) == 0
+}
+
+// matchSegment matches a name with a segment of a pattern with '*', '?' and '[...]', a malformed pattern matches nothing.
+func matchSegment(pattern string, name string) bool {
+	match, err := path.Match(pattern, name)
+	return err == nil && match
 }
 
 // readGitignore reads the .gitignore file and returns the list of ignore patterns.
@@ -111,25 +241,10 @@ func readGitignore(gitignorePath string) ([]string, error) {
 	lines := strings.Split(string(content), "\n")
 	var patterns []string
 	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if line != "" && !strings.HasPrefix(line, "#") {
+		line = strings.TrimSuffix(line, "\r")
+		if strings.TrimSpace(line) != "" && !strings.HasPrefix(line, "#") {
 			patterns = append(patterns, line)
 		}
 	}
 	return patterns, nil
 }
-
-// IsGitIgnored checks if a file path matches any of the patterns in .gitignore.
-func IsGitIgnored(path string, patterns []string) bool {
-	for _, pattern := range patterns {
-		match, _ := filepath.Match(pattern, path)
-		if match {
-			return true
-		}
-		// Handle patterns like "dir/" that ignore entire directories
-		if strings.HasSuffix(pattern, "/") && strings.HasPrefix(path, pattern) {
-			return true
-		}
-	}
-	return false
-}
@@ -0,0 +1,56 @@
+package utils
+
+import (
+	"encoding/base64"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/meysamhadeli/codai/providers/models"
+)
+
+// maxImageSize keeps attached images within the limits of all vision providers
+const maxImageSize = 5 * 1024 * 1024
+
+var imageMediaTypes = map[string]string{
+	".png":  "image/png",
+	".jpg":  "image/jpeg",
+	".jpeg": "image/jpeg",
+	".gif":  "image/gif",
+	".webp": "image/webp",
+}
+
+// IsImageFile checks if the path has an image extension supported by vision models.
+func IsImageFile(path string) bool {
+	_, ok := imageMediaTypes[strings.ToLower(filepath.Ext(path))]
+	return ok
+}
+
+// LoadImage reads an image from disk and base64 encodes it for vision models.
+func LoadImage(path string) (*models.Image, error) {
+	mediaType, ok := imageMediaTypes[strings.ToLower(filepath.Ext(path))]
+	if !ok {
+		return nil, fmt.Errorf("unsupported image format '%s', supported formats are png, jpg, jpeg, gif and webp", filepath.Ext(path))
+	}
+
+	fileInfo, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get image info: %s, error: %w", path, err)
+	}
+
+	if fileInfo.Size() > maxImageSize {
+		return nil, fmt.Errorf("image %s is larger than %d MB", path, maxImageSize/(1024*1024))
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read image: %s, error: %w", path, err)
+	}
+
+	return &models.Image{
+		Path:      filepath.ToSlash(path),
+		MediaType: mediaType,
+		Data:      base64.StdEncoding.EncodeToString(content),
+	}, nil
+}
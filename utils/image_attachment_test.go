@@ -0,0 +1,84 @@
+package utils
+
+import (
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsImageFile(t *testing.T) {
+	tests := []struct {
+		path     string
+		expected bool
+	}{
+		{"shot.png", true},
+		{"docs/photo.JPG", true},
+		{"photo.jpeg", true},
+		{"anim.gif", true},
+		{"image.webp", true},
+		{"icon.svg", false},
+		{"main.go", false},
+		{"png", false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.path, func(t *testing.T) {
+			assert.Equal(t, test.expected, IsImageFile(test.path))
+		})
+	}
+}
+
+func TestLoadImage(t *testing.T) {
+	dir := t.TempDir()
+	content := []byte("\x89PNG\r\n\x1a\n")
+
+	tests := []struct {
+		name      string
+		fileName  string
+		size      int64
+		mediaType string
+		err       string
+	}{
+		{name: "png", fileName: "shot.png", mediaType: "image/png"},
+		{name: "upper case jpg", fileName: "photo.JPG", mediaType: "image/jpeg"},
+		{name: "jpeg", fileName: "photo.jpeg", mediaType: "image/jpeg"},
+		{name: "gif", fileName: "anim.gif", mediaType: "image/gif"},
+		{name: "webp", fileName: "image.webp", mediaType: "image/webp"},
+		{name: "at the size limit", fileName: "limit.png", size: maxImageSize, mediaType: "image/png"},
+		{name: "above the size limit", fileName: "large.png", size: maxImageSize + 1, err: "is larger than 5 MB"},
+		{name: "unsupported format", fileName: "icon.svg", err: "unsupported image format '.svg'"},
+		{name: "missing file", fileName: "missing.png", size: -1, err: "failed to get image info"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			path := filepath.Join(dir, test.fileName)
+			switch {
+			case test.size > 0:
+				file, err := os.Create(path)
+				assert.NoError(t, err)
+				assert.NoError(t, file.Truncate(test.size))
+				assert.NoError(t, file.Close())
+			case test.size == 0:
+				assert.NoError(t, os.WriteFile(path, content, 0644))
+			}
+
+			image, err := LoadImage(path)
+			if test.err != "" {
+				assert.ErrorContains(t, err, test.err)
+				assert.Nil(t, image)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, test.mediaType, image.MediaType)
+			assert.Equal(t, filepath.ToSlash(path), image.Path)
+			if test.size == 0 {
+				assert.Equal(t, base64.StdEncoding.EncodeToString(content), image.Data)
+			}
+		})
+	}
+}
@@ -1,32 +1,106 @@
 package utils
 
 import (
-	"bufio"
-	"fmt"
-	"io"
 	"strings"
 
 	"github.com/meysamhadeli/codai/constants/lipgloss"
 )
 
-// InputPrompt prompts the user to enter their request for code assistance in a charming way
-func InputPrompt(reader *bufio.Reader) (string, error) {
+// multiLineDelimiter starts and ends a multi-line block, e.g., to paste a stack trace
+const multiLineDelimiter = `"""`
 
-	// Beautifully styled prompt message
-	fmt.Print(lipgloss.BlueSky.Render("> "))
+// InputPrompt prompts the user to enter their request for code assistance in a charming way.
+// A block between '"""' lines, or lines ending with '\', span multiple lines.
+func InputPrompt(editor *LineEditor) (string, error) {
+
+	userInput, err := readInput(editor.ReadLine, editor.IsHistoryEntry)
+	if err != nil {
+		return "", err
+	}
 
-	// Read user input
-	userInput, err := reader.ReadString('\n')
-	if userInput == "" {
-		return "", nil
+	// The history file keeps one entry per line, a multi-line input is saved escaped in a single line block
+	if userInput != "" {
+		editor.SaveHistory(escapeHistoryEntry(userInput))
 	}
 
+	return userInput, nil
+}
+
+// readInput reads the lines of a request and joins the lines of a multi-line block or of the lines ending with '\'.
+// Only an entry of the history is unescaped, a typed '"""' line is kept as it is, e.g., '"""printf("a\n")"""'.
+func readInput(readLine func(prompt string) (string, error), isHistoryEntry func(line string) bool) (string, error) {
+
+	// Beautifully styled prompt message
+	userInput, err := readLine(lipgloss.BlueSky.Render("> "))
 	if err != nil {
-		if err == io.EOF {
-			return "", nil
+		return "", err
+	}
+
+	var lines []string
+	trimmedInput := strings.TrimSpace(userInput)
+
+	switch {
+	// A multi-line input recalled from the history, e.g., '"""first line\nsecond line"""'
+	case len(trimmedInput) >= 2*len(multiLineDelimiter) && strings.HasPrefix(trimmedInput, multiLineDelimiter) && strings.HasSuffix(trimmedInput, multiLineDelimiter) && isHistoryEntry(trimmedInput):
+		lines = append(lines, unescapeHistoryEntry(trimmedInput[len(multiLineDelimiter):len(trimmedInput)-len(multiLineDelimiter)]))
+
+	case strings.HasPrefix(trimmedInput, multiLineDelimiter):
+		line := strings.TrimPrefix(trimmedInput, multiLineDelimiter)
+		for !strings.HasSuffix(line, multiLineDelimiter) {
+			lines = append(lines, line)
+			if line, err = readLine(lipgloss.BlueSky.Render(". ")); err != nil {
+				return "", err
+			}
 		}
-		return "", fmt.Errorf(lipgloss.Red.Render("🚫 Error reading input: "))
+		lines = append(lines, strings.TrimSuffix(line, multiLineDelimiter))
+
+	case strings.HasSuffix(trimmedInput, `\`):
+		line := userInput
+		for strings.HasSuffix(strings.TrimSpace(line), `\`) {
+			lines = append(lines, strings.TrimSuffix(strings.TrimSpace(line), `\`))
+			if line, err = readLine(lipgloss.BlueSky.Render(". ")); err != nil {
+				return "", err
+			}
+		}
+		lines = append(lines, line)
+
+	default:
+		lines = append(lines, userInput)
 	}
 
-	return strings.TrimSpace(userInput), nil
+	return strings.TrimSpace(strings.Join(lines, "\n")), nil
+}
+
+// escapeHistoryEntry keeps a multi-line input in a single line of the history file, as a '"""' block where the
+// line breaks are written '\n' and the backslashes '\\', so recalling it restores the lines. An input starting with
+// '"""' is escaped too, else it would be read as a block when recalled.
+func escapeHistoryEntry(userInput string) string {
+	if !strings.Contains(userInput, "\n") && !strings.HasPrefix(userInput, multiLineDelimiter) {
+		return userInput
+	}
+
+	escaped := strings.ReplaceAll(userInput, `\`, `\\`)
+	escaped = strings.ReplaceAll(escaped, "\n", `\n`)
+	return multiLineDelimiter + escaped + multiLineDelimiter
+}
+
+// unescapeHistoryEntry restores the line breaks and backslashes of a multi-line input escaped in the history.
+func unescapeHistoryEntry(entry string) string {
+	var builder strings.Builder
+	for i := 0; i < len(entry); i++ {
+		if entry[i] == '\\' && i+1 < len(entry) {
+			switch entry[i+1] {
+			case 'n':
+				builder.WriteByte('\n')
+				i++
+				continue
+			case '\\':
+				builder.WriteByte('\\')
+				i++
+				continue
+			}
+		}
+		builder.WriteByte(entry[i])
+	}
+	return builder.String()
 }
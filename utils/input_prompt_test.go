@@ -0,0 +1,97 @@
+package utils
+
+import (
+	"io"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// linesReader returns a function reading the lines in order like the line editor, then io.EOF
+func linesReader(lines ...string) func(prompt string) (string, error) {
+	return func(prompt string) (string, error) {
+		if len(lines) == 0 {
+			return "", io.EOF
+		}
+		line := lines[0]
+		lines = lines[1:]
+		return line, nil
+	}
+}
+
+// historyOf returns a function reporting the entries of a history
+func historyOf(entries ...string) func(line string) bool {
+	return func(line string) bool {
+		return slices.Contains(entries, line)
+	}
+}
+
+func TestReadInput(t *testing.T) {
+	tests := []struct {
+		name     string
+		lines    []string
+		history  []string
+		expected string
+		err      error
+	}{
+		{name: "single line", lines: []string{"  explain @main.go  "}, expected: "explain @main.go"},
+		{name: "block", lines: []string{`"""`, "panic: boom", "", "goroutine 1", `"""`}, expected: "panic: boom\n\ngoroutine 1"},
+		{name: "block with text on the delimiters", lines: []string{`"""why does`, "this fail?", `it panics"""`}, expected: "why does\nthis fail?\nit panics"},
+		{name: "single line block", lines: []string{`"""fix it"""`}, expected: "fix it"},
+		{name: "continued lines", lines: []string{`first line\`, `second line \`, "third line"}, expected: "first line\nsecond line \nthird line"},
+		{name: "recalled multi-line entry", lines: []string{`"""first\nC:\\temp\\new"""`}, history: []string{`"""first\nC:\\temp\\new"""`}, expected: "first\nC:\\temp\\new"},
+		{name: "typed single line block with backslashes", lines: []string{`"""printf("a\n") in C:\\temp"""`}, expected: `printf("a\n") in C:\\temp`},
+		{name: "unterminated block", lines: []string{`"""`, "panic: boom"}, err: io.EOF},
+		{name: "unterminated continued line", lines: []string{`first line \`}, err: io.EOF},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			userInput, err := readInput(linesReader(test.lines...), historyOf(test.history...))
+			if test.err != nil {
+				assert.ErrorIs(t, err, test.err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, test.expected, userInput)
+		})
+	}
+}
+
+func TestHistoryEntry(t *testing.T) {
+	tests := []struct {
+		name      string
+		userInput string
+		entry     string
+	}{
+		{name: "single line", userInput: `replace \n with a space`, entry: `replace \n with a space`},
+		{name: "multi-line", userInput: "panic: boom\n\ngoroutine 1", entry: `"""panic: boom\n\ngoroutine 1"""`},
+		{name: "backslashes", userInput: "path\nC:\\new\\n", entry: `"""path\nC:\\new\\n"""`},
+		{name: "starting with the delimiter", userInput: `"""quoted\n`, entry: `""""""quoted\\n"""`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			entry := escapeHistoryEntry(test.userInput)
+			assert.Equal(t, test.entry, entry)
+
+			// Recalling the entry from the history restores the input
+			userInput, err := readInput(linesReader(entry), historyOf(entry))
+			assert.NoError(t, err)
+			assert.Equal(t, test.userInput, userInput)
+		})
+	}
+}
+
+func TestTypedBlockRoundTrip(t *testing.T) {
+	// A one-line block typed with backslashes is sent as typed, and recalling it from the history keeps it
+	userInput, err := readInput(linesReader(`"""printf("a\n"); path := "C:\\temp\\"`+`"""`), historyOf())
+	assert.NoError(t, err)
+	assert.Equal(t, `printf("a\n"); path := "C:\\temp\\"`, userInput)
+
+	entry := escapeHistoryEntry(userInput)
+	recalled, err := readInput(linesReader(entry), historyOf(entry))
+	assert.NoError(t, err)
+	assert.Equal(t, userInput, recalled)
+}
@@ -0,0 +1,82 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/chzyer/readline"
+)
+
+// ErrInterrupt is returned when the user presses Ctrl+C while typing.
+var ErrInterrupt = readline.ErrInterrupt
+
+// LineEditor reads the user input with persistent history, reverse search (Ctrl+R) and tab completion.
+type LineEditor struct {
+	instance *readline.Instance
+	history  map[string]bool // The entries of the history, only a recalled entry is unescaped
+}
+
+// NewLineEditor creates a line editor that keeps the input history of the project in the user level directory.
+func NewLineEditor(cwd string, completer readline.AutoCompleter) (*LineEditor, error) {
+	var historyFile string
+
+	configDir, err := GetCodaiConfigDir()
+	if err == nil {
+		historyDir := filepath.Join(configDir, "history")
+		if err := os.MkdirAll(historyDir, os.ModePerm); err == nil {
+			historyFile = filepath.Join(historyDir, GetProjectKey(cwd))
+		}
+	}
+
+	instance, err := readline.NewEx(&readline.Config{
+		HistoryFile:            historyFile,
+		HistorySearchFold:      true,
+		DisableAutoSaveHistory: true, // Only user requests are saved, not the answers of confirm prompts
+		AutoComplete:           completer,
+		InterruptPrompt:        "^C",
+		EOFPrompt:              "",
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error creating line editor: %w", err)
+	}
+
+	editor := &LineEditor{instance: instance, history: make(map[string]bool)}
+	if content, err := os.ReadFile(historyFile); err == nil {
+		for _, entry := range strings.Split(string(content), "\n") {
+			editor.history[entry] = true
+		}
+	}
+
+	return editor, nil
+}
+
+// ReadLine reads a single line with the given prompt.
+func (editor *LineEditor) ReadLine(prompt string) (string, error) {
+	editor.instance.SetPrompt(prompt)
+
+	line, err := editor.instance.Readline()
+	if errors.Is(err, readline.ErrInterrupt) {
+		return "", ErrInterrupt
+	}
+
+	return line, err
+}
+
+// SaveHistory adds an entry to the persistent input history.
+func (editor *LineEditor) SaveHistory(entry string) {
+	editor.history[entry] = true
+	_ = editor.instance.SaveHistory(entry)
+}
+
+// IsHistoryEntry reports whether a line is an entry of the history, e.g., recalled with the up arrow.
+func (editor *LineEditor) IsHistoryEntry(line string) bool {
+	return editor.history[line]
+}
+
+// Close restores the terminal and releases the line editor.
+func (editor *LineEditor) Close() {
+	_ = editor.instance.Close()
+}
@@ -3,6 +3,7 @@ package utils
 import (
 	"fmt"
 	"github.com/alecthomas/chroma/v2/quick"
+	"github.com/meysamhadeli/codai/constants/lipgloss"
 	"os"
 	"strings"
 )
@@ -32,3 +33,59 @@ func RenderAndPrintMarkdown(line string, language string, theme string) error {
 
 	return nil
 }
+
+// HighlightMarkdown returns the markdown text highlighted with the theme, code blocks use the language of their fence.
+func HighlightMarkdown(text string, theme string) string {
+	var builder strings.Builder
+
+	inCodeBlock := false
+	language := "markdown"
+	for _, line := range strings.SplitAfter(text, "\n") {
+		if line == "" {
+			continue
+		}
+
+		content := strings.TrimSuffix(line, "\n")
+		newLine := line[len(content):]
+
+		switch {
+		case strings.HasPrefix(strings.TrimSpace(line), "```"):
+			_ = quick.Highlight(&builder, line, "markdown", "terminal256", theme)
+			inCodeBlock = !inCodeBlock
+			language = "markdown"
+			if fence := strings.TrimSpace(line); inCodeBlock && len(fence) > 3 {
+				language = fence[3:]
+			}
+		case inCodeBlock && strings.HasPrefix(line, "+"):
+			builder.WriteString("\x1b[92m" + content + "\x1b[0m" + newLine)
+		case inCodeBlock && strings.HasPrefix(line, "-"):
+			builder.WriteString("\x1b[91m" + content + "\x1b[0m" + newLine)
+		default:
+			if err := quick.Highlight(&builder, line, language, "terminal256", theme); err != nil {
+				builder.WriteString(line)
+			}
+		}
+	}
+
+	return builder.String()
+}
+
+// RenderReasoning prints a chunk of the model reasoning dimmed, so it stays apart from the answer.
+func RenderReasoning(chunk string) {
+	lines := strings.SplitAfter(chunk, "\n")
+	for _, line := range lines {
+		text := strings.TrimSuffix(line, "\n")
+		if text != "" {
+			fmt.Print(lipgloss.Dim.Render(text))
+		}
+		if strings.HasSuffix(line, "\n") {
+			fmt.Print("\n")
+		}
+	}
+}
+
+// RenderReasoningSummary prints the collapsed form of the reasoning as a single dimmed line.
+func RenderReasoningSummary(reasoning string) {
+	lines := strings.Count(strings.TrimSpace(reasoning), "\n") + 1
+	fmt.Println(lipgloss.Dim.Render(fmt.Sprintf("💭 Thought for %d lines, use ':reasoning' to expand it.", lines)))
+}
@@ -0,0 +1,26 @@
+package utils
+
+import (
+	"regexp"
+	"strings"
+)
+
+var mentionRegex = regexp.MustCompile(`(?:^|\s)@([^\s@]+)`)
+
+// ExtractMentions returns the '@' mentions of the user input in order, without the '@' prefix and duplicates.
+func ExtractMentions(userInput string) []string {
+	var mentions []string
+	seen := make(map[string]bool)
+
+	for _, match := range mentionRegex.FindAllStringSubmatch(userInput, -1) {
+		// Drop trailing punctuation, e.g., "look at @main.go."
+		mention := strings.TrimRight(match[1], ".,;:!?)\"'")
+		if mention == "" || seen[mention] {
+			continue
+		}
+		seen[mention] = true
+		mentions = append(mentions, mention)
+	}
+
+	return mentions
+}
@@ -0,0 +1,34 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExtractMentions(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected []string
+	}{
+		{name: "no mention", input: "explain the analyzer", expected: nil},
+		{name: "file", input: "explain @main.go", expected: []string{"main.go"}},
+		{name: "at the start", input: "@cmd/code.go why?", expected: []string{"cmd/code.go"}},
+		{name: "directory", input: "look at @code_analyzer/ please", expected: []string{"code_analyzer/"}},
+		{name: "symbol", input: "where is @GetProjectFiles used", expected: []string{"GetProjectFiles"}},
+		{name: "in order", input: "compare @b.go with @a.go", expected: []string{"b.go", "a.go"}},
+		{name: "duplicates", input: "@a.go and @a.go again", expected: []string{"a.go"}},
+		{name: "trailing punctuation", input: "look at @main.go. Then @shot.png, and (@utils/mentions.go)", expected: []string{"main.go", "shot.png"}},
+		{name: "quoted", input: `fix "@main.go"`, expected: nil},
+		{name: "email", input: "mail me at dev@example.com", expected: nil},
+		{name: "only punctuation", input: "what about @?", expected: nil},
+		{name: "multi-line", input: "first line\n@main.go\n\t@go.mod", expected: []string{"main.go", "go.mod"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, ExtractMentions(test.input))
+		})
+	}
+}
@@ -0,0 +1,181 @@
+package utils
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// slashCommandRegex matches an input invoking a slash command, e.g., "/test path/to/file.go"
+var slashCommandRegex = regexp.MustCompile(`^/([\w-]+)(?:\s+|$)`)
+
+// argumentPlaceholderRegex matches the placeholders of arguments, '$ARGUMENTS' for all of them or '$1' to '$9'
+var argumentPlaceholderRegex = regexp.MustCompile(`\$(ARGUMENTS|[1-9])`)
+
+// SlashCommand is a reusable prompt stored in '.codai/commands/<name>.md' and invoked like '/<name> args'
+type SlashCommand struct {
+	Name        string
+	Description string
+	Prompt      string
+	Pin         bool // Pin the arguments that are files or directories into the context
+}
+
+// LoadSlashCommands loads the commands of '~/.config/codai/commands' and '.codai/commands' of the project,
+// a project command overrides a user command with the same name.
+func LoadSlashCommands(cwd string) (map[string]SlashCommand, error) {
+	commands := make(map[string]SlashCommand)
+
+	var dirs []string
+	if configDir, err := GetCodaiConfigDir(); err == nil {
+		dirs = append(dirs, filepath.Join(configDir, "commands"))
+	}
+	dirs = append(dirs, filepath.Join(cwd, ".codai", "commands"))
+
+	for _, dir := range dirs {
+		files, err := filepath.Glob(filepath.Join(dir, "*.md"))
+		if err != nil {
+			return nil, err
+		}
+
+		for _, file := range files {
+			content, err := os.ReadFile(file)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read command %s: %w", file, err)
+			}
+
+			command := parseSlashCommand(strings.ToLower(strings.TrimSuffix(filepath.Base(file), ".md")), string(content))
+			commands[command.Name] = command
+		}
+	}
+
+	return commands, nil
+}
+
+// SlashCommandNames returns the sorted names of the commands prefixed with '/'
+func SlashCommandNames(commands map[string]SlashCommand) []string {
+	var names []string
+	for name := range commands {
+		names = append(names, "/"+name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ParseSlashCommandInput returns the command and the arguments of an input invoking one of the commands, an input
+// starting with another name, e.g., a path like "/etc/hosts why?", isn't a command
+func ParseSlashCommandInput(input string, commands map[string]SlashCommand) (SlashCommand, []string, bool) {
+	match := slashCommandRegex.FindStringSubmatch(input)
+	if match == nil {
+		return SlashCommand{}, nil, false
+	}
+
+	command, exists := commands[strings.ToLower(match[1])]
+	if !exists {
+		return SlashCommand{}, nil, false
+	}
+
+	return command, SplitArgs(input[len(match[0]):]), true
+}
+
+// Expand returns the prompt of the command with the arguments in place of their placeholders, the arguments are
+// appended when the prompt has no placeholder. Pinned files and directories are appended as '@' mentions.
+func (command SlashCommand) Expand(cwd string, args []string) string {
+	hasPlaceholder := false
+	prompt := argumentPlaceholderRegex.ReplaceAllStringFunc(command.Prompt, func(placeholder string) string {
+		hasPlaceholder = true
+		if placeholder == "$ARGUMENTS" {
+			return strings.Join(args, " ")
+		}
+		index, _ := strconv.Atoi(placeholder[1:])
+		if index > len(args) {
+			return ""
+		}
+		return args[index-1]
+	})
+
+	if !hasPlaceholder && len(args) > 0 {
+		prompt += "\n\n" + strings.Join(args, " ")
+	}
+
+	if command.Pin {
+		var mentions []string
+		for _, arg := range args {
+			if _, err := os.Stat(filepath.Join(cwd, arg)); err == nil {
+				mentions = append(mentions, "@"+strings.TrimPrefix(arg, "@"))
+			}
+		}
+		if len(mentions) > 0 {
+			prompt += "\n\n" + strings.Join(mentions, " ")
+		}
+	}
+
+	return strings.TrimSpace(prompt)
+}
+
+// SplitArgs splits the arguments of a command by whitespace, keeping the quoted ones together
+func SplitArgs(input string) []string {
+	var args []string
+	var current strings.Builder
+	var quote rune
+	inArg := false
+
+	for _, r := range input {
+		switch {
+		case quote != 0 && r == quote:
+			quote = 0
+		case quote == 0 && (r == '"' || r == '\''):
+			quote = r
+			inArg = true
+		case quote == 0 && (r == ' ' || r == '\t' || r == '\n'):
+			if inArg {
+				args = append(args, current.String())
+				current.Reset()
+				inArg = false
+			}
+		default:
+			current.WriteRune(r)
+			inArg = true
+		}
+	}
+
+	if inArg {
+		args = append(args, current.String())
+	}
+
+	return args
+}
+
+// parseSlashCommand reads the optional front matter of a command, with 'description' and 'pin', and its prompt
+func parseSlashCommand(name string, content string) SlashCommand {
+	content = strings.ReplaceAll(content, "\r\n", "\n")
+	command := SlashCommand{Name: name, Prompt: content}
+
+	if rest, found := strings.CutPrefix(content, "---\n"); found {
+		if frontMatter, prompt, found := strings.Cut(rest, "\n---"); found {
+			command.Prompt = strings.TrimPrefix(prompt, "\n")
+			for _, line := range strings.Split(frontMatter, "\n") {
+				key, value, _ := strings.Cut(line, ":")
+				value = strings.Trim(strings.TrimSpace(value), `"'`)
+				switch strings.TrimSpace(key) {
+				case "description":
+					command.Description = value
+				case "pin":
+					command.Pin, _ = strconv.ParseBool(value)
+				}
+			}
+		}
+	}
+
+	command.Prompt = strings.TrimSpace(command.Prompt)
+
+	// Use the first line of the prompt when there is no description
+	if command.Description == "" {
+		command.Description, _, _ = strings.Cut(command.Prompt, "\n")
+	}
+
+	return command
+}
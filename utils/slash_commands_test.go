@@ -0,0 +1,103 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseSlashCommand(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		expected SlashCommand
+	}{
+		{
+			name:     "without front matter",
+			content:  "Write the tests of $1.\nCover the edge cases.\n",
+			expected: SlashCommand{Name: "test", Description: "Write the tests of $1.", Prompt: "Write the tests of $1.\nCover the edge cases."},
+		},
+		{
+			name:     "with front matter",
+			content:  "---\ndescription: \"Write unit tests\"\npin: true\n---\nWrite the tests of $ARGUMENTS.\n",
+			expected: SlashCommand{Name: "test", Description: "Write unit tests", Prompt: "Write the tests of $ARGUMENTS.", Pin: true},
+		},
+		{
+			name:     "with windows line endings",
+			content:  "---\r\ndescription: Write unit tests\r\npin: yes\r\n---\r\nWrite the tests.\r\n",
+			expected: SlashCommand{Name: "test", Description: "Write unit tests", Prompt: "Write the tests."},
+		},
+		{
+			name:     "unterminated front matter",
+			content:  "---\ndescription: Write unit tests\n",
+			expected: SlashCommand{Name: "test", Description: "---", Prompt: "---\ndescription: Write unit tests"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, parseSlashCommand("test", test.content))
+		})
+	}
+}
+
+func TestSlashCommandExpand(t *testing.T) {
+	cwd := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(cwd, "main.go"), []byte("package main"), 0644))
+
+	tests := []struct {
+		name     string
+		command  SlashCommand
+		args     []string
+		expected string
+	}{
+		{name: "all arguments", command: SlashCommand{Prompt: "Explain $ARGUMENTS."}, args: []string{"main.go", "go.mod"}, expected: "Explain main.go go.mod."},
+		{name: "positional arguments", command: SlashCommand{Prompt: "Rename $1 to $2."}, args: []string{"Foo", "Bar"}, expected: "Rename Foo to Bar."},
+		{name: "missing positional argument", command: SlashCommand{Prompt: "Rename $1 to $2."}, args: []string{"Foo"}, expected: "Rename Foo to ."},
+		{name: "ninth argument", command: SlashCommand{Prompt: "Last is $9."}, args: []string{"1", "2", "3", "4", "5", "6", "7", "8", "9"}, expected: "Last is 9."},
+		{name: "no placeholder", command: SlashCommand{Prompt: "Review the code."}, args: []string{"main.go"}, expected: "Review the code.\n\nmain.go"},
+		{name: "no placeholder and no argument", command: SlashCommand{Prompt: "Review the code."}, expected: "Review the code."},
+		{name: "pinned files", command: SlashCommand{Prompt: "Test $1.", Pin: true}, args: []string{"main.go", "missing.go"}, expected: "Test main.go.\n\n@main.go"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, test.command.Expand(cwd, test.args))
+		})
+	}
+}
+
+func TestParseSlashCommandInput(t *testing.T) {
+	commands := map[string]SlashCommand{
+		"test":     {Name: "test", Prompt: "Write the tests of $1."},
+		"fix-lint": {Name: "fix-lint", Prompt: "Fix the lint errors."},
+	}
+
+	tests := []struct {
+		name     string
+		input    string
+		command  string
+		args     []string
+		expected bool
+	}{
+		{name: "command with arguments", input: "/test path/to/file.go", command: "test", args: []string{"path/to/file.go"}, expected: true},
+		{name: "command without arguments", input: "/fix-lint", command: "fix-lint", expected: true},
+		{name: "upper case name", input: "/TEST main.go", command: "test", args: []string{"main.go"}, expected: true},
+		{name: "quoted arguments", input: `/test "my file.go" 'other file.go'`, command: "test", args: []string{"my file.go", "other file.go"}, expected: true},
+		{name: "absolute path", input: "/etc/hosts why?", expected: false},
+		{name: "unknown name", input: "/tmp is full, why?", expected: false},
+		{name: "name inside text", input: "run /test please", expected: false},
+		{name: "name prefix", input: "/testing", expected: false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			command, args, ok := ParseSlashCommandInput(test.input, commands)
+			assert.Equal(t, test.expected, ok)
+			assert.Equal(t, test.command, command.Name)
+			assert.Equal(t, test.args, args)
+		})
+	}
+}